@@ -0,0 +1,87 @@
+package httpretry
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+// ErrAsyncTimeout is returned by DoAsync when the operation isn't done after
+// AsyncOptions.MaxPolls status polls.
+var ErrAsyncTimeout = errors.New("async operation not done")
+
+type AsyncOptions struct {
+	// Done reports whether a status response is terminal.  A status
+	// endpoint answering 303 See Other is followed to the resource, which
+	// is terminal with the default.
+	// defaults to any status but 202 Accepted
+	Done func(status *Response) bool
+
+	// MaxPolls fails with ErrAsyncTimeout after that many status polls
+	// defaults to no limit, use ctx to bound the wait
+	MaxPolls int
+}
+
+// DoAsync sends a request to an API answering long operations with 202
+// Accepted and a status URL in the Location header.  The status URL is
+// polled with GET until options.Done and the terminal response, typically
+// the created resource, is returned:
+//
+//	resource, err := api.DoAsync(ctx, http.MethodPost, job, httpretry.AsyncOptions{})
+//
+// Every request is retried like DoFull.  Between polls it waits the
+// Retry-After of the last status, capped by RetryAfterMax, or else the
+// backoff.  A response other than 202, or without a Location, is returned
+// as is.  A non 2xx status response that isn't terminal fails.  A status URL
+// on another host than the request URL is polled without credentials.
+func (r httpRequest) DoAsync(ctx context.Context, method string, object []byte, options AsyncOptions, opts ...CallOption) (*Response, error) {
+	if options.Done == nil {
+		options.Done = func(status *Response) bool {
+			return status.StatusCode != http.StatusAccepted
+		}
+	}
+
+	resp, err := r.DoFull(ctx, method, object, opts...)
+	if err != nil || resp.StatusCode != http.StatusAccepted {
+		return resp, err
+	}
+	statusURL := r.URL
+	for polls := 1; ; polls++ {
+		location := resp.Header.Get("Location")
+		if location == "" {
+			if polls == 1 {
+				return resp, nil
+			}
+		} else if statusURL, err = statusURL.Parse(location); err != nil {
+			return resp, err
+		}
+		if options.MaxPolls > 0 && polls > options.MaxPolls {
+			return resp, ErrAsyncTimeout
+		}
+
+		wait, ok := parseRetryAfter(resp.Header.Get("Retry-After"), r.clock().Now())
+		if !ok {
+			wait = r.Backoff.Backoff(polls)
+		} else if r.RetryAfterMax > 0 && wait > r.RetryAfterMax {
+			wait = r.RetryAfterMax
+		}
+		if err := r.clock().Sleep(ctx, wait); err != nil {
+			return resp, err
+		}
+
+		if resp, err = r.sendingTo(statusURL).HttpGetFull(ctx, opts...); err != nil {
+			return resp, err
+		}
+		if options.Done(resp) {
+			return resp, nil
+		}
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			return resp, newUnexpectedStatusError([]int{http.StatusOK}, resp, statusURL)
+		}
+	}
+}
+
+// HttpPostAsync is DoAsync for POST requests.
+func (r httpRequest) HttpPostAsync(ctx context.Context, object []byte, options AsyncOptions, opts ...CallOption) (*Response, error) {
+	return r.DoAsync(ctx, http.MethodPost, object, options, opts...)
+}
@@ -0,0 +1,114 @@
+package httpretry
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntegration_DoAsync(t *testing.T) {
+
+	t.Run("GIVEN a job API answering with 202 Accepted", func(t *testing.T) {
+		var paths []string
+		statusPolls := 0
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			paths = append(paths, r.Method+" "+r.URL.Path)
+			switch r.URL.Path {
+			case "/jobs":
+				w.Header().Set("Location", "/jobs/1/status")
+				w.Header().Set("Retry-After", "0")
+				w.WriteHeader(http.StatusAccepted)
+			case "/jobs/1/status":
+				statusPolls++
+				switch {
+				case statusPolls <= 1:
+					w.WriteHeader(http.StatusAccepted)
+				case statusPolls == 2:
+					w.WriteHeader(http.StatusServiceUnavailable)
+				default:
+					http.Redirect(w, r, "/things/1", http.StatusSeeOther)
+				}
+			case "/things/1":
+				w.Write([]byte(`{"id":1}`))
+			}
+		}))
+		defer ts.Close()
+
+		jobs, err := url.Parse(ts.URL + "/jobs")
+		require.NoError(t, err)
+		api := NewHttpRequest(HttpRequestOptions{
+			URL:              jobs,
+			RetriesWait:      time.Millisecond,
+			IsRetryCondition: RetryOnStatus(http.StatusServiceUnavailable),
+		})
+
+		t.Run("WHEN HttpPostAsync is sent", func(t *testing.T) {
+			resource, err := api.HttpPostAsync(context.Background(), []byte(`{}`), AsyncOptions{})
+			require.NoError(t, err)
+
+			t.Run("THEN the status URL is polled until the resource is returned", func(t *testing.T) {
+				assert.Equal(t, http.StatusOK, resource.StatusCode)
+				assert.Equal(t, `{"id":1}`, string(resource.Body))
+				assert.Equal(t, []string{
+					"POST /jobs",
+					"GET /jobs/1/status",
+					"GET /jobs/1/status",
+					"GET /jobs/1/status",
+					"GET /things/1",
+				}, paths)
+			})
+		})
+
+		t.Run("WHEN MaxPolls is reached", func(t *testing.T) {
+			statusPolls = -10
+			resp, err := api.HttpPostAsync(context.Background(), []byte(`{}`), AsyncOptions{MaxPolls: 2})
+
+			t.Run("THEN ErrAsyncTimeout is returned with the last status", func(t *testing.T) {
+				assert.ErrorIs(t, err, ErrAsyncTimeout)
+				assert.Equal(t, http.StatusAccepted, resp.StatusCode)
+				assert.Equal(t, -8, statusPolls)
+			})
+		})
+	})
+}
+
+func TestIntegration_DoAsyncCrossHost(t *testing.T) {
+
+	t.Run("GIVEN a job API whose status URL is on another host", func(t *testing.T) {
+		var statusAuthorization string
+		status := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			statusAuthorization = r.Header.Get("Authorization")
+			w.Write([]byte("done"))
+		}))
+		defer status.Close()
+		var authorization string
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			authorization = r.Header.Get("Authorization")
+			w.Header().Set("Location", status.URL+"/jobs/1")
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusAccepted)
+		}))
+		defer ts.Close()
+
+		url, err := url.Parse(ts.URL + "/jobs")
+		require.NoError(t, err)
+		api := NewHttpRequest(HttpRequestOptions{URL: url, Token: "s3cr3t"})
+
+		t.Run("WHEN HttpPostAsync is sent", func(t *testing.T) {
+			resp, err := api.HttpPostAsync(context.Background(), []byte(`{}`), AsyncOptions{})
+			require.NoError(t, err)
+
+			t.Run("THEN the status is polled without the credentials", func(t *testing.T) {
+				assert.Equal(t, "done", string(resp.Body))
+				assert.Equal(t, "Bearer s3cr3t", authorization)
+				assert.Empty(t, statusAuthorization)
+			})
+		})
+	})
+}
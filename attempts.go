@@ -0,0 +1,56 @@
+package httpretry
+
+import (
+	"sync"
+	"time"
+)
+
+// AttemptSummary describes one attempt of a call.
+type AttemptSummary struct {
+	// Attempt number, starting at 1 for every host, see FallbackURLs
+	Attempt int
+	Host    string
+
+	// Start of the attempt, after the wait before it
+	Start time.Time
+
+	// StatusCode is 0 when no response was received
+	StatusCode int
+	Err        error
+
+	// Duration of the attempt, without the wait before it
+	Duration time.Duration
+
+	// Wait before the attempt
+	Wait time.Duration
+
+	// Conn describes the connection of the attempt, it is zero for hedged
+	// attempts
+	Conn ConnStats
+}
+
+// AttemptRecorder receives the summary of every attempt, see
+// HttpRequestOptions.AttemptRecorder.  It must be safe for concurrent use.
+type AttemptRecorder interface {
+	RecordAttempt(attempt AttemptSummary)
+}
+
+// AttemptLog is an AttemptRecorder keeping every attempt in memory, for
+// asserting on retry behavior in tests.
+type AttemptLog struct {
+	mu       sync.Mutex
+	attempts []AttemptSummary
+}
+
+func (l *AttemptLog) RecordAttempt(attempt AttemptSummary) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.attempts = append(l.attempts, attempt)
+}
+
+// Attempts returns a copy of the recorded attempts in order.
+func (l *AttemptLog) Attempts() []AttemptSummary {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]AttemptSummary(nil), l.attempts...)
+}
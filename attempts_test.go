@@ -0,0 +1,65 @@
+package httpretry
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntegration_AttemptHistory(t *testing.T) {
+
+	t.Run("GIVEN a server that returns 503 twice", func(t *testing.T) {
+		calls := 0
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			if calls <= 2 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}
+		}))
+		defer ts.Close()
+
+		u, err := url.Parse(ts.URL)
+		require.NoError(t, err)
+
+		t.Run("AND http request with an attempt log", func(t *testing.T) {
+			log := &AttemptLog{}
+			api := NewHttpRequest(HttpRequestOptions{
+				URL:             u,
+				RetriesWait:     time.Millisecond,
+				AttemptRecorder: log,
+				IsRetryCondition: func(resp *http.Response, retryCount int) bool {
+					return resp.StatusCode == http.StatusServiceUnavailable
+				},
+			})
+
+			t.Run("WHEN HttpGetFull is sent", func(t *testing.T) {
+				resp, err := api.HttpGetFull(context.Background())
+				require.NoError(t, err)
+
+				t.Run("THEN the response history describes every attempt", func(t *testing.T) {
+					require.Len(t, resp.History, 3)
+					var codes []int
+					for _, attempt := range resp.History {
+						codes = append(codes, attempt.StatusCode)
+						assert.Equal(t, u.Host, attempt.Host)
+						assert.False(t, attempt.Start.IsZero())
+					}
+					assert.Equal(t, []int{503, 503, 200}, codes)
+					assert.Equal(t, time.Duration(0), resp.History[0].Wait)
+					assert.Equal(t, time.Millisecond, resp.History[2].Wait)
+					assert.False(t, resp.History[1].Start.After(resp.History[2].Start))
+				})
+
+				t.Run("AND the recorder received the same attempts", func(t *testing.T) {
+					assert.Equal(t, resp.History, log.Attempts())
+				})
+			})
+		})
+	})
+}
@@ -0,0 +1,137 @@
+package httpretry
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// AuditRecord describes a call once its final response or error is known,
+// see HttpRequestOptions.AuditSink.
+type AuditRecord struct {
+	Time       time.Time     `json:"time"`
+	Method     string        `json:"method"`
+	URL        string        `json:"url"`
+	StatusCode int           `json:"status,omitempty"`
+	Duration   time.Duration `json:"duration_ns"`
+	Attempts   int           `json:"attempts"`
+	RequestID  string        `json:"request_id,omitempty"`
+	Error      string        `json:"error,omitempty"`
+
+	// RequestBytes and ResponseBytes are the sizes of the bodies, -1 when
+	// unknown like the ContentLength of a streamed body
+	RequestBytes  int64 `json:"request_bytes"`
+	ResponseBytes int64 `json:"response_bytes"`
+
+	// RequestSHA256 and ResponseSHA256 are the hex SHA-256 of the bodies,
+	// set with HttpRequestOptions.AuditHashBodies when the body is known
+	RequestSHA256  string `json:"request_sha256,omitempty"`
+	ResponseSHA256 string `json:"response_sha256,omitempty"`
+}
+
+// AuditSink receives a record per call for compliance logging, separate from
+// the debug and retry logs.  It must be safe for concurrent use.  Errors are
+// logged as warnings, they don't fail the call.
+type AuditSink interface {
+	Audit(record AuditRecord) error
+}
+
+// JSONLinesAuditSink writes every record as a line of JSON.
+type JSONLinesAuditSink struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewJSONLinesAuditSink writes the records to w.
+func NewJSONLinesAuditSink(w io.Writer) *JSONLinesAuditSink {
+	return &JSONLinesAuditSink{w: w}
+}
+
+// OpenAuditLog appends the records to the file at path, creating it readable
+// by the owner only.  Close the sink to close the file.
+func OpenAuditLog(path string) (*JSONLinesAuditSink, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	return NewJSONLinesAuditSink(f), nil
+}
+
+func (s *JSONLinesAuditSink) Audit(record AuditRecord) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err = s.w.Write(append(line, '\n'))
+	return err
+}
+
+// Close closes the writer of the sink when it is an io.Closer.
+func (s *JSONLinesAuditSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if closer, ok := s.w.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// audit sends the record of a call to AuditSink, req is the final request
+// sent, nil when none could be built.
+func (r httpRequest) audit(ctx context.Context, req *http.Request, result *Response, err error, start time.Time, duration time.Duration) {
+	d := r.dumper()
+	record := AuditRecord{
+		Time:          start,
+		StatusCode:    result.StatusCode,
+		Duration:      duration,
+		Attempts:      result.Attempts,
+		RequestID:     RequestIDFromContext(ctx),
+		ResponseBytes: int64(len(result.Body)),
+	}
+	u := r.URL
+	if req != nil {
+		record.Method = req.Method
+		record.RequestBytes = req.ContentLength
+		u = req.URL
+	}
+	if u != nil {
+		record.URL = string(d.redactor.Redact([]byte(u.Redacted()), d.token))
+	}
+	if err != nil {
+		record.Error = string(d.redactor.Redact([]byte(err.Error()), d.token))
+	}
+	if result.Body == nil && result.raw != nil {
+		record.ResponseBytes = result.raw.ContentLength
+	}
+	if r.AuditHashBodies {
+		if req != nil && req.GetBody != nil {
+			if body, err := req.GetBody(); err == nil {
+				record.RequestSHA256 = hashBody(body)
+			}
+		}
+		if result.Body != nil {
+			sum := sha256.Sum256(result.Body)
+			record.ResponseSHA256 = hex.EncodeToString(sum[:])
+		}
+	}
+	if auditErr := r.AuditSink.Audit(record); auditErr != nil {
+		r.logRetry(LogLevelWarn, "Audit record failed", Fields{"error": auditErr, "request_id": record.RequestID})
+	}
+}
+
+func hashBody(body io.ReadCloser) string {
+	defer body.Close()
+	hash := sha256.New()
+	if _, err := io.Copy(hash, body); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
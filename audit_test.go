@@ -0,0 +1,93 @@
+package httpretry
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mandric/httpretry/httpretrytest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntegration_AuditSink(t *testing.T) {
+
+	t.Run("GIVEN a server failing once AND a JSON lines audit sink", func(t *testing.T) {
+		ts := httpretrytest.NewFlakyServer(1, http.StatusServiceUnavailable)
+		defer ts.Close()
+
+		url, err := url.Parse(ts.URL + "/orders?api_key=secret")
+		require.NoError(t, err)
+		var buf bytes.Buffer
+		api := NewHttpRequest(HttpRequestOptions{
+			URL:             url,
+			Token:           "secret-token",
+			Clock:           httpretrytest.NewFakeClock(time.Now()),
+			AuditSink:       NewJSONLinesAuditSink(&buf),
+			AuditHashBodies: true,
+		})
+
+		t.Run("WHEN a POST is sent", func(t *testing.T) {
+			object := []byte(`{"id":1}`)
+			resp, err := api.HttpPostFull(context.Background(), object)
+			require.NoError(t, err)
+
+			lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+			require.Len(t, lines, 1)
+			var record AuditRecord
+			require.NoError(t, json.Unmarshal([]byte(lines[0]), &record))
+
+			t.Run("THEN a single record describes the call", func(t *testing.T) {
+				assert.Equal(t, http.MethodPost, record.Method)
+				assert.Equal(t, http.StatusOK, record.StatusCode)
+				assert.Equal(t, 2, record.Attempts)
+				assert.Equal(t, int64(len(object)), record.RequestBytes)
+				assert.Equal(t, int64(len(resp.Body)), record.ResponseBytes)
+				assert.Empty(t, record.Error)
+			})
+
+			t.Run("AND the URL is redacted", func(t *testing.T) {
+				assert.Contains(t, record.URL, "/orders?api_key=[REDACTED]")
+				assert.NotContains(t, lines[0], "secret")
+			})
+
+			t.Run("AND the bodies are hashed", func(t *testing.T) {
+				sum := sha256.Sum256(object)
+				assert.Equal(t, hex.EncodeToString(sum[:]), record.RequestSHA256)
+				assert.NotEmpty(t, record.ResponseSHA256)
+			})
+		})
+	})
+
+	t.Run("GIVEN an audit log file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "audit.jsonl")
+		sink, err := OpenAuditLog(path)
+		require.NoError(t, err)
+
+		t.Run("WHEN a call fails to connect", func(t *testing.T) {
+			url, err := url.Parse("http://127.0.0.1:1")
+			require.NoError(t, err)
+			_, _, err = NewHttpRequest(HttpRequestOptions{URL: url, RetriesMax: NoRetries, AuditSink: sink}).HttpGet(context.Background())
+			require.Error(t, err)
+			require.NoError(t, sink.Close())
+
+			t.Run("THEN the error is recorded", func(t *testing.T) {
+				content, err := os.ReadFile(path)
+				require.NoError(t, err)
+				var record AuditRecord
+				require.NoError(t, json.Unmarshal(content, &record))
+				assert.Equal(t, http.MethodGet, record.Method)
+				assert.Contains(t, record.Error, "connection refused")
+			})
+		})
+	})
+}
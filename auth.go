@@ -0,0 +1,143 @@
+package httpretry
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// TokenSource supplies the bearer token of every attempt so long-running
+// processes pick up refreshed tokens mid-retry.  Sources should cache the
+// token until it expires, Token is called once per attempt.  A
+// golang.org/x/oauth2 token source is adapted by OAuth2TokenSource.
+type TokenSource interface {
+	Token() (string, error)
+}
+
+// OAuth2Token is implemented by *oauth2.Token of golang.org/x/oauth2.
+type OAuth2Token interface {
+	SetAuthHeader(r *http.Request)
+}
+
+// OAuth2TokenSource adapts a golang.org/x/oauth2 token source, for example
+// one returned by oauth2.Config.TokenSource, without this package importing
+// oauth2:
+//
+//	api := httpretry.NewHttpRequest(httpretry.HttpRequestOptions{
+//		URL:         u,
+//		TokenSource: httpretry.OAuth2TokenSource[*oauth2.Token](ts),
+//	})
+//
+// Only bearer tokens are supported.
+func OAuth2TokenSource[T OAuth2Token](source interface{ Token() (T, error) }) TokenSource {
+	return TokenSourceFunc(func() (string, error) {
+		token, err := source.Token()
+		if err != nil {
+			return "", err
+		}
+		// oauth2 tokens only expose their type and value through the header
+		req := &http.Request{Header: http.Header{}}
+		token.SetAuthHeader(req)
+		scheme, value, _ := strings.Cut(req.Header.Get("Authorization"), " ")
+		if !strings.EqualFold(scheme, "Bearer") || value == "" {
+			return "", fmt.Errorf("unsupported OAuth2 token type %q", scheme)
+		}
+		return value, nil
+	})
+}
+
+// TokenRefresher is implemented by token sources that can discard their
+// cached token and fetch a new one, used when a request is rejected with 401.
+type TokenRefresher interface {
+	RefreshToken() (string, error)
+}
+
+type TokenSourceFunc func() (string, error)
+
+func (f TokenSourceFunc) Token() (string, error) {
+	return f()
+}
+
+func setBearerToken(req *http.Request, token string) {
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+}
+
+// refreshToken forces a new token after a 401, sources without a refresher
+// are simply asked again.
+func refreshToken(source TokenSource) (string, error) {
+	if refresher, ok := source.(TokenRefresher); ok {
+		return refresher.RefreshToken()
+	}
+	return source.Token()
+}
+
+// ReauthFunc obtains a new bearer token after a request was rejected with 401.
+type ReauthFunc func(ctx context.Context) (string, error)
+
+// tokenCache keeps the token obtained by ReauthFunc, it is shared between
+// copies of an httpRequest.
+type tokenCache struct {
+	mu    sync.Mutex
+	token string
+}
+
+func (c *tokenCache) get() string {
+	if c == nil {
+		return ""
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.token
+}
+
+func (c *tokenCache) set(token string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.token = token
+}
+
+func (r httpRequest) canReauthenticate() bool {
+	return r.ReauthFunc != nil || (r.RetryOnUnauthorized && r.TokenSource != nil)
+}
+
+// reauthenticate returns the token for the retry of a 401, ReauthFunc wins
+// over refreshing the TokenSource.
+func (r httpRequest) reauthenticate(ctx context.Context) (string, error) {
+	if r.ReauthFunc == nil {
+		return refreshToken(r.TokenSource)
+	}
+	token, err := r.ReauthFunc(ctx)
+	if err != nil {
+		return "", err
+	}
+	r.reauthToken.set(token)
+	return token, nil
+}
+
+// credentialHeaders are dropped from requests following a link to another
+// host, like net/http does on cross-host redirects.
+var credentialHeaders = []string{"Authorization", "Cookie", "X-Api-Key"}
+
+// sendingTo returns r sending to u.  When u is on another host than r.URL,
+// for example a next page or status URL chosen by the server, the token,
+// token source and credential headers of r are dropped so they don't leak
+// to a third party.
+func (r httpRequest) sendingTo(u *url.URL) httpRequest {
+	crossHost := r.URL == nil || u.Host != r.URL.Host
+	r.URL = u
+	if !crossHost {
+		return r
+	}
+	r.Token = ""
+	r.TokenSource = nil
+	r.ReauthFunc = nil
+	r.reauthToken = &tokenCache{}
+	r.Header = r.Header.Clone()
+	for _, name := range credentialHeaders {
+		r.Header.Del(name)
+	}
+	return r
+}
@@ -0,0 +1,251 @@
+package httpretry
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testTokenSource struct {
+	version int
+}
+
+func (s *testTokenSource) Token() (string, error) {
+	return "token-" + strconv.Itoa(s.version), nil
+}
+
+func (s *testTokenSource) RefreshToken() (string, error) {
+	s.version++
+	return s.Token()
+}
+
+func TestIntegration_TokenSource(t *testing.T) {
+
+	t.Run("GIVEN a server that only accepts token-1", func(t *testing.T) {
+		var authorizations []string
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			authorizations = append(authorizations, r.Header.Get("Authorization"))
+			if r.Header.Get("Authorization") != "Bearer token-1" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer ts.Close()
+
+		url, err := url.Parse(ts.URL)
+		require.NoError(t, err)
+
+		t.Run("AND http request with an expired token source AND retry on unauthorized", func(t *testing.T) {
+			source := &testTokenSource{}
+			api := NewHttpRequest(HttpRequestOptions{
+				URL:                 url,
+				RetriesMax:          1,
+				TokenSource:         source,
+				RetryOnUnauthorized: true,
+			})
+
+			t.Run("WHEN HttpGet is sent", func(t *testing.T) {
+				resp, err := api.HttpGetFull(context.Background())
+				require.NoError(t, err)
+
+				t.Run("THEN the token is refreshed AND the request retried once", func(t *testing.T) {
+					assert.Equal(t, http.StatusOK, resp.StatusCode)
+					assert.Equal(t, []string{"Bearer token-0", "Bearer token-1"}, authorizations)
+					assert.Equal(t, 2, resp.Attempts)
+				})
+			})
+
+			t.Run("WHEN the token is rejected again", func(t *testing.T) {
+				authorizations = nil
+				source.version = 5
+				_, code, err := api.HttpGet(context.Background())
+				require.NoError(t, err)
+
+				t.Run("THEN the request is only retried once", func(t *testing.T) {
+					assert.Equal(t, http.StatusUnauthorized, code)
+					assert.Equal(t, []string{"Bearer token-5", "Bearer token-6"}, authorizations)
+				})
+			})
+		})
+
+		t.Run("AND http request with a token source AND an Authorization header", func(t *testing.T) {
+			authorizations = nil
+			api := NewHttpRequest(HttpRequestOptions{
+				URL:         url,
+				Header:      http.Header{"Authorization": []string{"Bearer token-1"}},
+				TokenSource: &testTokenSource{},
+			})
+
+			t.Run("WHEN HttpGet is sent", func(t *testing.T) {
+				_, code, err := api.HttpGet(context.Background())
+				require.NoError(t, err)
+
+				t.Run("THEN the header wins", func(t *testing.T) {
+					assert.Equal(t, http.StatusOK, code)
+					assert.Equal(t, []string{"Bearer token-1"}, authorizations)
+				})
+			})
+		})
+	})
+}
+
+func TestIntegration_ReauthFunc(t *testing.T) {
+
+	t.Run("GIVEN a server that only accepts a fresh token", func(t *testing.T) {
+		var authorizations []string
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			authorizations = append(authorizations, r.Header.Get("Authorization"))
+			if r.Header.Get("Authorization") != "Bearer fresh" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer ts.Close()
+
+		url, err := url.Parse(ts.URL)
+		require.NoError(t, err)
+
+		t.Run("AND http request with a stale token AND a reauth func", func(t *testing.T) {
+			reauths := 0
+			api := NewHttpRequest(HttpRequestOptions{
+				URL:   url,
+				Token: "stale",
+				ReauthFunc: func(ctx context.Context) (string, error) {
+					reauths++
+					return "fresh", nil
+				},
+			})
+
+			t.Run("WHEN HttpGet is sent twice", func(t *testing.T) {
+				_, first, err := api.HttpGet(context.Background())
+				require.NoError(t, err)
+				_, second, err := api.HttpGet(context.Background())
+				require.NoError(t, err)
+
+				t.Run("THEN the first call reauthenticates AND the second reuses the token", func(t *testing.T) {
+					assert.Equal(t, http.StatusOK, first)
+					assert.Equal(t, http.StatusOK, second)
+					assert.Equal(t, 1, reauths)
+					assert.Equal(t, []string{"Bearer stale", "Bearer fresh", "Bearer fresh"}, authorizations)
+				})
+			})
+		})
+	})
+}
+
+func TestIntegration_ReauthFuncStream(t *testing.T) {
+
+	t.Run("GIVEN a server that only accepts a fresh token AND counts connections", func(t *testing.T) {
+		var connections atomic.Int32
+		ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Authorization") != "Bearer fresh" {
+				w.WriteHeader(http.StatusUnauthorized)
+				w.Write([]byte("token expired"))
+				return
+			}
+			w.Write([]byte("ok"))
+		}))
+		ts.Config.ConnState = func(conn net.Conn, state http.ConnState) {
+			if state == http.StateNew {
+				connections.Add(1)
+			}
+		}
+		ts.Start()
+		defer ts.Close()
+
+		url, err := url.Parse(ts.URL)
+		require.NoError(t, err)
+		api := NewHttpRequest(HttpRequestOptions{
+			URL:   url,
+			Token: "stale",
+			ReauthFunc: func(ctx context.Context) (string, error) {
+				return "fresh", nil
+			},
+		})
+
+		t.Run("WHEN HttpGetStream is sent", func(t *testing.T) {
+			resp, err := api.HttpGetStream(context.Background())
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+
+			t.Run("THEN the unauthorized response is drained AND its connection reused", func(t *testing.T) {
+				assert.Equal(t, "ok", string(body))
+				assert.EqualValues(t, 1, connections.Load())
+			})
+		})
+	})
+}
+
+// oauth2Token and oauth2TokenSource mirror golang.org/x/oauth2.Token and
+// oauth2.TokenSource.
+type oauth2Token struct {
+	AccessToken string
+	TokenType   string
+}
+
+func (t *oauth2Token) SetAuthHeader(r *http.Request) {
+	tokenType := t.TokenType
+	if tokenType == "" {
+		tokenType = "Bearer"
+	}
+	r.Header.Set("Authorization", tokenType+" "+t.AccessToken)
+}
+
+type oauth2TokenSource interface {
+	Token() (*oauth2Token, error)
+}
+
+type staticOAuth2Source struct {
+	token *oauth2Token
+}
+
+func (s staticOAuth2Source) Token() (*oauth2Token, error) {
+	return s.token, nil
+}
+
+func TestIntegration_OAuth2TokenSource(t *testing.T) {
+
+	t.Run("GIVEN a server recording the Authorization header", func(t *testing.T) {
+		var authorization string
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			authorization = r.Header.Get("Authorization")
+		}))
+		defer ts.Close()
+
+		url, err := url.Parse(ts.URL)
+		require.NoError(t, err)
+
+		t.Run("WHEN HttpGet is sent with an oauth2 token source", func(t *testing.T) {
+			var source oauth2TokenSource = staticOAuth2Source{token: &oauth2Token{AccessToken: "acc3ss"}}
+			api := NewHttpRequest(HttpRequestOptions{URL: url, TokenSource: OAuth2TokenSource[*oauth2Token](source)})
+			_, _, err := api.HttpGet(context.Background())
+
+			t.Run("THEN its access token is sent", func(t *testing.T) {
+				require.NoError(t, err)
+				assert.Equal(t, "Bearer acc3ss", authorization)
+			})
+		})
+
+		t.Run("WHEN the oauth2 token isn't a bearer token", func(t *testing.T) {
+			source := staticOAuth2Source{token: &oauth2Token{AccessToken: "acc3ss", TokenType: "MAC"}}
+			_, err := OAuth2TokenSource[*oauth2Token](source).Token()
+
+			t.Run("THEN Token fails", func(t *testing.T) {
+				assert.Error(t, err)
+			})
+		})
+	})
+}
@@ -0,0 +1,104 @@
+package httpretry
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// BackoffStrategy computes the delay before the next attempt.  retryCount is
+// the number of attempts made so far, so the first call receives 1.
+type BackoffStrategy interface {
+	Backoff(retryCount int) time.Duration
+}
+
+// ConstantBackoff waits the same amount of time between every attempt.  This
+// is the behavior of RetriesWait and the default when no strategy is set.
+type ConstantBackoff struct {
+	Wait time.Duration
+}
+
+func (b ConstantBackoff) Backoff(retryCount int) time.Duration {
+	return b.Wait
+}
+
+var (
+	defaultBackoffMu sync.RWMutex
+	defaultBackoff   BackoffStrategy
+)
+
+// SetDefaultBackoff sets the strategy of every request created afterwards
+// without its own Backoff, RetriesWait, RetriesWaitMax or BackoffMultiplier.
+// nil restores waiting RetriesWait.
+func SetDefaultBackoff(backoff BackoffStrategy) {
+	defaultBackoffMu.Lock()
+	defer defaultBackoffMu.Unlock()
+	defaultBackoff = backoff
+}
+
+func getDefaultBackoff() BackoffStrategy {
+	defaultBackoffMu.RLock()
+	defer defaultBackoffMu.RUnlock()
+	return defaultBackoff
+}
+
+// ExponentialBackoff multiplies the wait by Multiplier after every attempt
+// starting at Base, never waiting longer than Max.  Max of zero means no cap,
+// Multiplier of zero doubles the wait.
+type ExponentialBackoff struct {
+	Base       time.Duration
+	Max        time.Duration
+	Multiplier float64
+}
+
+func (b ExponentialBackoff) Backoff(retryCount int) time.Duration {
+	return exponentialWait(b.Base, b.Max, b.Multiplier, retryCount)
+}
+
+// ExponentialJitterBackoff is ExponentialBackoff with "full jitter": the wait
+// is a random duration between zero and the exponential wait.  Spreading
+// retries out this way avoids many clients hammering a recovering upstream at
+// the same moment.
+//
+// See https://aws.amazon.com/blogs/architecture/exponential-backoff-and-jitter/
+type ExponentialJitterBackoff struct {
+	Base       time.Duration
+	Max        time.Duration
+	Multiplier float64
+
+	// Rand draws the waits, NewRand with a fixed seed makes them
+	// deterministic.
+	// defaults to the package Rand, see SetJitterSeed
+	Rand *Rand
+}
+
+func (b ExponentialJitterBackoff) Backoff(retryCount int) time.Duration {
+	wait := exponentialWait(b.Base, b.Max, b.Multiplier, retryCount)
+	if wait <= 0 {
+		return 0
+	}
+	n := int64(wait)
+	if n < math.MaxInt64 {
+		// include wait itself unless that overflows Int63n
+		n++
+	}
+	return time.Duration(orDefaultRand(b.Rand).Int63n(n))
+}
+
+func exponentialWait(base time.Duration, max time.Duration, multiplier float64, retryCount int) time.Duration {
+	if retryCount < 1 {
+		retryCount = 1
+	}
+	if multiplier <= 0 {
+		multiplier = 2
+	}
+	wait := float64(base) * math.Pow(multiplier, float64(retryCount-1))
+	if max > 0 && wait > float64(max) {
+		return max
+	}
+	// guard against overflowing time.Duration on large retry counts
+	if wait >= math.MaxInt64 {
+		return time.Duration(math.MaxInt64)
+	}
+	return time.Duration(wait)
+}
@@ -0,0 +1,116 @@
+package httpretry
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBackoffStrategies(t *testing.T) {
+
+	t.Run("GIVEN a constant backoff", func(t *testing.T) {
+		b := ConstantBackoff{Wait: 50 * time.Millisecond}
+
+		t.Run("THEN every retry waits the same", func(t *testing.T) {
+			assert.Equal(t, 50*time.Millisecond, b.Backoff(1))
+			assert.Equal(t, 50*time.Millisecond, b.Backoff(7))
+		})
+	})
+
+	t.Run("GIVEN an exponential backoff with a cap", func(t *testing.T) {
+		b := ExponentialBackoff{Base: 100 * time.Millisecond, Max: time.Second}
+
+		t.Run("THEN the wait doubles until the cap", func(t *testing.T) {
+			assert.Equal(t, 100*time.Millisecond, b.Backoff(1))
+			assert.Equal(t, 200*time.Millisecond, b.Backoff(2))
+			assert.Equal(t, 800*time.Millisecond, b.Backoff(4))
+			assert.Equal(t, time.Second, b.Backoff(5))
+			assert.Equal(t, time.Second, b.Backoff(500))
+		})
+	})
+
+	t.Run("GIVEN an exponential backoff with a multiplier", func(t *testing.T) {
+		b := ExponentialBackoff{Base: 200 * time.Millisecond, Max: 30 * time.Second, Multiplier: 1.5}
+
+		t.Run("THEN the wait grows by the multiplier until the cap", func(t *testing.T) {
+			assert.Equal(t, 200*time.Millisecond, b.Backoff(1))
+			assert.Equal(t, 300*time.Millisecond, b.Backoff(2))
+			assert.Equal(t, 450*time.Millisecond, b.Backoff(3))
+			assert.Equal(t, 30*time.Second, b.Backoff(100))
+		})
+	})
+
+	t.Run("GIVEN an exponential backoff without a cap AND a huge retry count", func(t *testing.T) {
+		b := ExponentialBackoff{Base: time.Second}
+
+		t.Run("THEN the wait does not overflow", func(t *testing.T) {
+			assert.Greater(t, b.Backoff(1000), time.Duration(0))
+		})
+	})
+
+	t.Run("GIVEN an exponential backoff with full jitter", func(t *testing.T) {
+		b := ExponentialJitterBackoff{Base: 100 * time.Millisecond, Max: time.Second}
+
+		t.Run("THEN the wait is never above the exponential wait", func(t *testing.T) {
+			for i := 0; i < 100; i++ {
+				wait := b.Backoff(3)
+				assert.GreaterOrEqual(t, wait, time.Duration(0))
+				assert.LessOrEqual(t, wait, 400*time.Millisecond)
+			}
+		})
+	})
+
+	t.Run("GIVEN an uncapped exponential backoff with full jitter", func(t *testing.T) {
+		b := ExponentialJitterBackoff{Base: time.Second}
+
+		t.Run("THEN a high retry count does not overflow", func(t *testing.T) {
+			assert.GreaterOrEqual(t, b.Backoff(100), time.Duration(0))
+			assert.GreaterOrEqual(t, b.Backoff(1000), time.Duration(0))
+		})
+	})
+}
+
+func TestNewHttpRequestBackoff(t *testing.T) {
+
+	t.Run("GIVEN RetriesWaitMax AND BackoffMultiplier without a Backoff", func(t *testing.T) {
+		api := NewHttpRequest(HttpRequestOptions{
+			RetriesWait:       200 * time.Millisecond,
+			RetriesWaitMax:    30 * time.Second,
+			BackoffMultiplier: 3,
+		})
+
+		t.Run("THEN an exponential backoff is used", func(t *testing.T) {
+			assert.Equal(t, ExponentialBackoff{Base: 200 * time.Millisecond, Max: 30 * time.Second, Multiplier: 3}, api.Backoff)
+			assert.Equal(t, 1800*time.Millisecond, api.Backoff.Backoff(3))
+		})
+	})
+
+	t.Run("GIVEN only RetriesWait", func(t *testing.T) {
+		api := NewHttpRequest(HttpRequestOptions{RetriesWait: time.Second})
+
+		t.Run("THEN the wait is constant", func(t *testing.T) {
+			assert.Equal(t, ConstantBackoff{Wait: time.Second}, api.Backoff)
+		})
+	})
+	t.Run("GIVEN a default backoff", func(t *testing.T) {
+		SetDefaultBackoff(ExponentialBackoff{Base: time.Second})
+		defer SetDefaultBackoff(nil)
+
+		t.Run("WHEN a request without wait options is created", func(t *testing.T) {
+			api := NewHttpRequest(HttpRequestOptions{})
+
+			t.Run("THEN it inherits the default backoff", func(t *testing.T) {
+				assert.Equal(t, ExponentialBackoff{Base: time.Second}, api.Backoff)
+			})
+		})
+
+		t.Run("WHEN a request with RetriesWait is created", func(t *testing.T) {
+			api := NewHttpRequest(HttpRequestOptions{RetriesWait: time.Millisecond})
+
+			t.Run("THEN its wait wins", func(t *testing.T) {
+				assert.Equal(t, ConstantBackoff{Wait: time.Millisecond}, api.Backoff)
+			})
+		})
+	})
+}
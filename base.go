@@ -15,35 +15,33 @@
 // Using singleton client to maximize connection pool usage, reuse connections
 // and minimize new file handles used.  This improves support for heavy
 // workloads in resource constrained environments like lambdas.
+// GetConnPoolStats reports how many attempts actually reused a connection.
+// Shutdown drains the attempts in flight and closes the idle connections
+// before a lambda or container terminates.
 //
-// TODO keep http stats (req/res/code counts) or find library that can
+// Metrics
+//
+// Request, retry and status class counts are reported through the Metrics
+// option, see PrometheusMetrics and NewStatsMetrics.
 
 package httpretry
 
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
 	"io"
+	"math"
+	"net"
 	"net/http"
+	"net/http/httptrace"
 	"net/http/httputil"
 	"net/url"
-	"strings"
 	"time"
-
-	"github.com/google/uuid"
-	"github.com/sirupsen/logrus"
 )
 
-var httpClient *http.Client
-
-func GetSingletonHttpClient() *http.Client {
-	if httpClient == nil {
-		httpClient = &http.Client{}
-	}
-	return httpClient
-}
-
 type RetryPredicate func(resp *http.Response, retryCount int) bool
 
 type httpRequest struct {
@@ -52,7 +50,100 @@ type httpRequest struct {
 	Header           http.Header
 	RetriesMax       int
 	RetriesWait      time.Duration
+	Backoff          BackoffStrategy
 	IsRetryCondition RetryPredicate
+	IsRetryError     RetryErrorPredicate
+	StatusClassifier *StatusClassifier
+	MethodPolicies   map[string]MethodPolicy
+	PolicyRouter     *PolicyRouter
+
+	RetriesWaitMax    time.Duration
+	BackoffMultiplier float64
+	// customBackoff and customRetriesWait are false when NewHttpRequest
+	// derived Backoff and RetriesWait, With derives them again then
+	customBackoff     bool
+	customRetriesWait bool
+
+	RespectRetryAfter bool
+	RetryAfterMax     time.Duration
+
+	RetryBudget    *RetryBudget
+	CircuitBreaker *CircuitBreaker
+	Metrics        MetricsCollector
+	Tracer         Tracer
+	ClientTrace    *httptrace.ClientTrace
+
+	InjectRequestID bool
+
+	Client         *http.Client
+	ClientName     string
+	ClientRegistry *ClientRegistry
+	Redirect       *RedirectPolicy
+	Jar            http.CookieJar
+
+	HedgeDelay time.Duration
+	HedgeMax   int
+
+	Logger        Logger
+	RetryLogLevel LogLevel
+
+	Redactor          *Redactor
+	DisableRedaction  bool
+	MaxDebugBodyBytes int
+	DebugContentTypes []string
+
+	Hooks      Hooks
+	Middleware []Middleware
+	Clock      Clock
+
+	TokenSource         TokenSource
+	RetryOnUnauthorized bool
+	ReauthFunc          ReauthFunc
+	reauthToken         *tokenCache
+
+	RateLimiter RateLimiter
+	HostLimiter *HostLimiter
+
+	AddIdempotencyKey bool
+	SafeRetries       bool
+
+	Cache        Cache
+	StaleOnError bool
+
+	CompressRequest    bool
+	DecompressResponse bool
+
+	RotateAddresses bool
+
+	HostPolicy *HostPolicy
+
+	FallbackURLs        []*url.URL
+	FallbackStatusCodes []int
+
+	AttemptRecorder AttemptRecorder
+
+	MaxElapsedTime time.Duration
+
+	MaxResponseBytes int64
+	TruncateResponse bool
+
+	ExpectedSHA256 string
+	VerifyDigest   bool
+
+	GraphQLRetryCodes []string
+
+	PollCursorParam string
+
+	FollowCreated bool
+
+	ExpectStatus []int
+
+	DryRun bool
+
+	AuditSink       AuditSink
+	AuditHashBodies bool
+	HARRecorder     *HARRecorder
+	AttachCurl      bool
 }
 
 type HttpRequestOptions struct {
@@ -60,7 +151,21 @@ type HttpRequestOptions struct {
 	Token  string
 	Header http.Header
 
-	// RetriesMax max number of retries
+	// DefaultHeaders are added to Header unless it sets them
+	// defaults to the package defaults, see SetDefaultHeaders
+	DefaultHeaders http.Header
+
+	// UserAgent is sent unless Header sets a User-Agent
+	// defaults to the User-Agent of DefaultHeaders, DefaultUserAgent
+	UserAgent string
+
+	// JSONAPIHeaders sends the JSON:API Accept and Content-Type unless
+	// Header or DefaultHeaders set them, see JSONAPIHeaders()
+	JSONAPIHeaders bool
+
+	// RetriesMax max number of attempts, NoRetries for a single attempt or
+	// UnlimitedRetries to retry until ctx is done, other negative values are
+	// a single attempt too
 	// defaults to 10
 	RetriesMax int
 
@@ -68,7 +173,29 @@ type HttpRequestOptions struct {
 	// defaults to 1sec
 	RetriesWait time.Duration
 
-	// IsRetryCondition returns false by default
+	// Backoff computes the wait between retries, for example
+	// ExponentialJitterBackoff.  When set RetriesWait, RetriesWaitMax and
+	// BackoffMultiplier are ignored.
+	// defaults to SetDefaultBackoff, or ConstantBackoff using RetriesWait
+	Backoff BackoffStrategy
+
+	// RetriesWaitMax and BackoffMultiplier grow the wait exponentially from
+	// RetriesWait, for example 200ms, 2x and 30s, without a Backoff.
+	// Setting either one uses ExponentialBackoff, BackoffMultiplier then
+	// defaults to 2 and RetriesWaitMax to no cap.
+	RetriesWaitMax    time.Duration
+	BackoffMultiplier float64
+
+	// RespectRetryAfter waits for the duration of the Retry-After header
+	// instead of the backoff when a retried response is a 429 or 503.
+	RespectRetryAfter bool
+
+	// RetryAfterMax caps the wait taken from a Retry-After header so a server
+	// can't stall the caller indefinitely.
+	// defaults to 1min
+	RetryAfterMax time.Duration
+
+	// IsRetryCondition replaces StatusClassifier when set
 	//
 	// To invoke retries pass in a function that returns true.  Avoid blanket
 	// conditions like `return resp.StatusCode != 201` which would retry any time
@@ -82,20 +209,416 @@ type HttpRequestOptions struct {
 	//
 	// Different HTTP APIs behave differently so work to only specify the edge
 	// cases for when a retry has a good chance to succeed.
+	// defaults to SetDefaultRetryPolicy
 	IsRetryCondition RetryPredicate
+
+	// IsRetryError is called when an attempt fails with an error, return false
+	// to give up without retrying.  Helpers like IsConnectionRefused and
+	// IsTimeoutError classify the error.
+	// defaults to SetDefaultRetryPolicy, or retrying on every error but
+	// IsPermanentTLSError
+	IsRetryError RetryErrorPredicate
+
+	// StatusClassifier decides which responses are retried when
+	// IsRetryCondition is not set.
+	// defaults to DefaultStatusClassifier
+	StatusClassifier *StatusClassifier
+
+	// MethodPolicies override RetriesMax, Backoff and the predicates per
+	// method, for example to retry GET aggressively but POST once:
+	//
+	//	MethodPolicies: map[string]httpretry.MethodPolicy{
+	//		http.MethodPost: {RetriesMax: 2},
+	//	},
+	MethodPolicies map[string]MethodPolicy
+
+	// PolicyRouter applies the EndpointPolicy matching the URL of a call,
+	// share one router across the requests of a service.  MethodPolicies
+	// take precedence.
+	PolicyRouter *PolicyRouter
+
+	// RetryBudget caps retries to a fraction of calls, share a single budget
+	// between requests to the same upstream.
+	// defaults to no budget
+	RetryBudget *RetryBudget
+
+	// CircuitBreaker fast-fails calls with ErrCircuitOpen while an upstream
+	// keeps failing, share a single breaker between requests.
+	// defaults to no breaker
+	CircuitBreaker *CircuitBreaker
+
+	// Metrics receives request, retry and latency statistics, for example
+	// NewPrometheusMetrics or NewStatsMetrics.
+	// defaults to no metrics
+	Metrics MetricsCollector
+
+	// Tracer creates a span per call with a child span per attempt and
+	// propagates trace headers, for example NewW3CTracer.
+	// defaults to no tracing
+	Tracer Tracer
+
+	// ClientTrace is attached to every attempt, its hooks see the connection
+	// events of each retry.  Hedged attempts call it concurrently.  See
+	// WithClientTrace to trace a single call.
+	ClientTrace *httptrace.ClientTrace
+
+	// InjectRequestID sends the request ID as the X-Request-ID header.  The ID
+	// is generated per attempt unless the caller sets one with WithRequestID.
+	InjectRequestID bool
+
+	// Client sends the requests instead of the singleton client, for example
+	// to isolate tests or tenants with different TLS configurations.
+	// defaults to GetSingletonHttpClient()
+	Client *http.Client
+
+	// ClientName selects a client of ClientRegistry unless Client or an
+	// option building a dedicated transport is set.  Requests fail with
+	// ErrUnknownClient while the name isn't registered.
+	ClientName string
+
+	// ClientRegistry holds the client selected by ClientName
+	// defaults to DefaultClientRegistry
+	ClientRegistry *ClientRegistry
+
+	// Redirect overrides how redirects are followed, see RedirectPolicy
+	// defaults to the redirect behavior of Client
+	Redirect *RedirectPolicy
+
+	// Jar stores the cookies of the responses and sends them back with the
+	// following attempts and calls, for example to keep the session of a
+	// cookie based API.  It overrides the jar of Client, see also
+	// ClientOptions.Jar to share a jar between requests using a named client.
+	// defaults to the jar of Client, the singleton client has none
+	Jar http.CookieJar
+
+	// Transport sends the requests through a dedicated client using this
+	// round tripper, on top of Client when both are set.
+	Transport http.RoundTripper
+
+	// TLSClientConfig sends the requests through a dedicated transport with
+	// this TLS configuration, for example client certificates built with
+	// TLSOptions.  Ignored when Transport is set.  The dedicated transport is
+	// a clone of the Client transport, or of http.DefaultTransport when that
+	// isn't an *http.Transport, the same goes for the proxy, dial and address
+	// options below.
+	TLSClientConfig *tls.Config
+
+	// ProxyURL sends the requests through a dedicated transport using this
+	// proxy, for example a per tenant or per region egress proxy.  Reuse the
+	// httpRequest, or register a client with ClientRegistry, to keep
+	// connections pooled.  Ignored when Transport is set.
+	ProxyURL *url.URL
+
+	// ProxyFunc is ProxyURL choosing the proxy per request, it takes
+	// precedence over ProxyURL, see http.Transport.Proxy
+	ProxyFunc func(*http.Request) (*url.URL, error)
+
+	// UnixSocket sends the requests to the Unix domain socket at this path
+	// through a dedicated transport, the URL host is only used for the Host
+	// header, for example http://docker/v1.43/containers/json with
+	// /var/run/docker.sock.  Proxies are not used.  Ignored when Transport
+	// or DialContext is set.
+	UnixSocket string
+
+	// DialContext opens the connections of a dedicated transport, for example
+	// to dial through a tunnel or a sidecar.  Ignored when Transport is set.
+	DialContext func(ctx context.Context, network, address string) (net.Conn, error)
+
+	// UseDefaultPolicy retries with DefaultRetryPolicy, IsRetryCondition and
+	// IsRetryError take precedence when set.
+	UseDefaultPolicy bool
+
+	// HedgeDelay enables hedging for GET and HEAD: when an attempt has no
+	// response after HedgeDelay a duplicate request is sent and the first
+	// response wins, the other request is cancelled.  Use it for read paths
+	// sensitive to tail latency, it adds load to the upstream.
+	// defaults to no hedging
+	HedgeDelay time.Duration
+
+	// HedgeMax max number of duplicate requests per attempt
+	// defaults to 1
+	HedgeMax int
+
+	// Logger receives the log lines of this request, for example a logrus
+	// entry with tenant fields.
+	// defaults to the package logger, see SetLogger
+	Logger Logger
+
+	// RetryLogLevel overrides the level of the retry log lines, for example
+	// LogLevelOff for a health check expected to fail.
+	// defaults to the level set by SetRetryLogLevel
+	RetryLogLevel LogLevel
+
+	// Redactor masks credentials like the Authorization header and password
+	// fields in debug dumps.
+	// defaults to DefaultRedactor
+	Redactor *Redactor
+
+	// DisableRedaction logs debug dumps as is, secrets included.
+	DisableRedaction bool
+
+	// MaxDebugBodyBytes truncates the bodies of debug dumps to this many
+	// bytes, followed by a marker with the number of bytes left out.
+	// defaults to dumping whole bodies
+	MaxDebugBodyBytes int
+
+	// DebugContentTypes limits the bodies of debug dumps to these media
+	// types, other bodies are replaced with a marker.  Entries ending with
+	// "/" match a type, entries starting with "+" match a structured syntax
+	// suffix, see TextContentTypes.
+	// defaults to dumping bodies of any type
+	DebugContentTypes []string
+
+	// Hooks are called before attempts, before retries and when giving up.
+	Hooks Hooks
+
+	// Middleware wraps every attempt in order, the first one is the
+	// outermost, see Middleware.
+	Middleware []Middleware
+
+	// Clock tells the time and waits between attempts
+	// defaults to the system clock
+	Clock Clock
+
+	// TokenSource supplies the bearer token for every attempt instead of
+	// Token, unless the Authorization header is set.
+	TokenSource TokenSource
+
+	// RetryOnUnauthorized refreshes the token of TokenSource and retries once
+	// right away when a 401 is returned.  This retry doesn't count against
+	// RetriesMax.
+	RetryOnUnauthorized bool
+
+	// ReauthFunc is called when a 401 is returned to obtain a new token, the
+	// request is retried once right away with the new bearer token.  The token
+	// is kept for later calls of this request.
+	ReauthFunc ReauthFunc
+
+	// RateLimiter is waited on before every attempt, share one limiter
+	// between requests to keep a batch under the upstream rate limit.
+	// defaults to no limit
+	RateLimiter RateLimiter
+
+	// HostLimiter caps concurrent attempts per host, share one limiter
+	// between requests.
+	// defaults to no limit
+	HostLimiter *HostLimiter
+
+	// AddIdempotencyKey sends an Idempotency-Key header with POST and PATCH
+	// requests so upstreams can deduplicate retries.  The key is generated
+	// once per call, reused for every attempt and returned in
+	// Response.IdempotencyKey.  A key already set in Header is kept.
+	AddIdempotencyKey bool
+
+	// SafeRetries doesn't retry POST, PATCH and other non-idempotent
+	// requests after a failure the upstream may have processed them in: an
+	// error once the request was written, or a status other than 408, 425,
+	// 429 and 503.  Requests with an Idempotency-Key, see AddIdempotencyKey,
+	// are retried, AllowUnsafeRetries opts a single call out.
+	SafeRetries bool
+
+	// Cache keeps GET responses with an ETag or Last-Modified header and
+	// revalidates them with conditional requests, a 304 returns the cached
+	// body.  Don't share a cache between requests with different credentials.
+	// defaults to no cache, see NewMemoryCache and NewMemoryCacheWithClock
+	Cache Cache
+
+	// StaleOnError returns the cached response of a GET when every attempt
+	// failed, with Response.Stale set, so read paths degrade gracefully during
+	// upstream outages.  Requires Cache.
+	StaleOnError bool
+
+	// CompressRequest gzips in-memory request bodies and sets
+	// Content-Encoding, the body is compressed once per call
+	CompressRequest bool
+
+	// DecompressResponse decodes gzip and deflate response bodies and sends
+	// Accept-Encoding: gzip, deflate unless Header sets it.  net/http only
+	// decodes responses itself when it added Accept-Encoding, which a caller
+	// set header or a custom transport bypasses.  Brotli isn't supported, a
+	// br response is returned as received with its Content-Encoding.
+	DecompressResponse bool
+
+	// RotateAddresses resolves the host once per call and sends every attempt
+	// to the next of its A and AAAA records, so a single bad backend doesn't
+	// consume all retries.  Requests go through a dedicated transport whose
+	// idle connections are closed before each retry.  Ignored when Transport
+	// is set.
+	RotateAddresses bool
+
+	// HostPolicy restricts the hosts and addresses requests can reach, see
+	// HostPolicy.
+	// defaults to no restriction
+	HostPolicy *HostPolicy
+
+	// FallbackURLs are mirrors the call is replayed against, in order, once
+	// the attempts against the previous host gave up, with their own
+	// RetriesMax attempts each.  Only the scheme and host of a fallback are
+	// used, path and query come from the request.
+	FallbackURLs []*url.URL
+
+	// FallbackStatusCodes also replay the call against the next fallback
+	// when the final response has one of these statuses, for example 404
+	// from a region the resource isn't replicated to
+	FallbackStatusCodes []int
+
+	// AttemptRecorder receives the summary of every attempt of every call,
+	// for example AttemptLog in tests.  Response.History holds the attempts
+	// of a single call.
+	// defaults to no recorder
+	AttemptRecorder AttemptRecorder
+
+	// MaxElapsedTime gives up instead of retrying once the wait before the
+	// next attempt would end past this budget, measured from the start of
+	// the call and including FallbackURLs.  Attempts in flight are not
+	// interrupted, use a context deadline for that.
+	// defaults to no limit
+	MaxElapsedTime time.Duration
+
+	// MaxResponseBytes fails an attempt whose response body is larger with a
+	// BodyTooLargeError, which is not retried.  Streamed responses are left
+	// to the caller.
+	// defaults to no limit
+	MaxResponseBytes int64
+
+	// TruncateResponse returns the first MaxResponseBytes of a larger body
+	// instead of failing
+	TruncateResponse bool
+
+	// ExpectedSHA256 is the hex encoded SHA-256 of the expected 2xx response
+	// body, an attempt returning another body fails with a ChecksumError and
+	// is retried like a connection error.  Streamed responses are not
+	// verified.
+	ExpectedSHA256 string
+
+	// VerifyDigest verifies response bodies against their Digest header,
+	// SHA-256, SHA-512 or MD5, or else their Content-MD5 header, like
+	// ExpectedSHA256.  Responses without these headers pass.
+	VerifyDigest bool
+
+	// GraphQLRetryCodes are the GraphQL error codes, see GraphQLError.Code,
+	// retried by GraphQL when a response has no other errors, for example
+	// RATE_LIMITED.
+	// defaults to not retrying GraphQL errors
+	GraphQLRetryCodes []string
+
+	// PollCursorParam is the query parameter carrying the cursor of Poll
+	// defaults to cursor
+	PollCursorParam string
+
+	// FollowCreated GETs the resource in the Location header of a 201
+	// response, with retries, and returns that response instead, for
+	// create-then-read flows.  A 201 without Location is returned as is.
+	FollowCreated bool
+
+	// ExpectStatus makes calls return an UnexpectedStatusError unless their
+	// final status is one of these codes, see ExpectStatus to set it per
+	// call.
+	// defaults to returning every status without an error
+	ExpectStatus []int
+
+	// DryRun logs the redacted requests and what the retry policy would do
+	// with them instead of sending them, to verify a configuration in
+	// staging.  Calls return a synthetic response with DryRunHeader and the
+	// first ExpectStatus code or 200.
+	DryRun bool
+
+	// AuditSink receives an AuditRecord per call with its final status or
+	// error, for example OpenAuditLog.  With FollowCreated the record is the
+	// one of the 201 response, with the error of the followed GET if any.
+	// defaults to no audit
+	AuditSink AuditSink
+
+	// AuditHashBodies adds the SHA-256 of the request and response bodies to
+	// the audit records instead of leaving them out.
+	AuditHashBodies bool
+
+	// HARRecorder captures every attempt for export as a HAR file, share one
+	// recorder across the requests of an integration being troubleshot.
+	// defaults to no recording
+	HARRecorder *HARRecorder
+
+	// AttachCurl wraps the errors of calls in a CurlError with a redacted
+	// curl command replaying the final attempt, see ToCurl.
+	AttachCurl bool
+}
+
+// requestFactory builds a new request for every attempt.  Requests can't be
+// reused across attempts because the body reader is drained by the first one,
+// so retries would otherwise send an empty body.
+type requestFactory func(ctx context.Context) (*http.Request, error)
+
+func (r httpRequest) newRequestFactory(method string, urlStr string, body []byte) requestFactory {
+	compressed := r.CompressRequest && len(body) > 0
+	var compressErr error
+	if compressed {
+		body, compressErr = gzipBytes(body)
+	}
+	return func(ctx context.Context) (*http.Request, error) {
+		if compressErr != nil {
+			return nil, compressErr
+		}
+		var reader io.Reader
+		if body != nil {
+			reader = bytes.NewReader(body)
+		}
+		req, err := http.NewRequestWithContext(ctx, method, urlStr, reader)
+		if err != nil {
+			return nil, err
+		}
+		req.Header = r.Header.Clone()
+		if compressed {
+			req.Header.Set("Content-Encoding", "gzip")
+		}
+		return req, nil
+	}
 }
 
-func (r httpRequest) doRequest(ctx context.Context, client *http.Client, req *http.Request) (resp *http.Response, respBody []byte, err error) {
-	DebugRequest(ctx, req, r.Token)
-	resp, err = client.Do(req)
+func (r httpRequest) doRequest(ctx context.Context, client *http.Client, req *http.Request, stream bool) (resp *http.Response, respBody []byte, err error) {
+	// dumping a streamed body would buffer it in memory
+	r.dumper().request(ctx, req, !isStreamedBody(req))
+	resp, err = r.chain(client).Do(req)
 	if err != nil {
 		// on error response body can be ignored
 		// https://pkg.go.dev/net/http#Client.Do
 		return
 	}
+	if r.DecompressResponse {
+		if err = decodeResponseBody(resp); err != nil {
+			resp.Body.Close()
+			return
+		}
+	}
+	if stream {
+		// dumping the body would buffer it, defeating streaming
+		r.dumper().response(ctx, resp, false)
+		return
+	}
+	if r.MaxResponseBytes > 0 {
+		// one byte past the limit tells a body of exactly the limit apart
+		resp.Body = limitedBody{Reader: io.LimitReader(resp.Body, r.MaxResponseBytes+1), Closer: resp.Body}
+	}
 	defer resp.Body.Close()
-	DebugResponse(ctx, resp, r.Token)
+	r.dumper().response(ctx, resp, true)
 	respBody, err = io.ReadAll(resp.Body)
+	if err != nil {
+		// headers arrived but the body was cut, wrapped like the errors of
+		// client.Do so retry policies can classify it by method
+		return resp, respBody, &url.Error{Op: urlErrorOp(req.Method), URL: req.URL.String(), Err: err}
+	}
+	if r.MaxResponseBytes > 0 && int64(len(respBody)) > r.MaxResponseBytes {
+		if !r.TruncateResponse {
+			return resp, nil, &BodyTooLargeError{Limit: r.MaxResponseBytes, StatusCode: resp.StatusCode}
+		}
+		respBody = respBody[:r.MaxResponseBytes]
+	}
+	if (r.ExpectedSHA256 != "" || r.VerifyDigest) && resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		if err = r.verifyChecksum(req, resp, respBody); err != nil {
+			return resp, nil, err
+		}
+	}
+	// retry predicates can read the body again
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
 	return
 }
 
@@ -103,50 +626,571 @@ func (r httpRequest) doRequest(ctx context.Context, client *http.Client, req *ht
 // TLS errors for example cause a connection to fail which gets retried.
 // For more information on transport layer parameters see:
 // https://pkg.go.dev/net/http#Transport.
-func (r httpRequest) doRequestWithRetries(ctx context.Context, client *http.Client, req *http.Request) (respBody []byte, statusCode int, err error) {
+func (r httpRequest) doRequestWithRetries(ctx context.Context, client *http.Client, newRequest requestFactory) ([]byte, int, error) {
+	result, err := r.retryLoop(ctx, client, newRequest, false)
+	return result.Body, result.StatusCode, err
+}
+
+// retryLoop runs the attempts of a call against URL, then against each of
+// FallbackURLs while the previous host gave up, and always returns a Response
+// describing the final attempt, even on error.
+func (r httpRequest) retryLoop(ctx context.Context, client *http.Client, newRequest requestFactory, stream bool) (result *Response, err error) {
+	clock := r.clock()
+	start := clock.Now()
+	var deadline time.Time
+	if r.MaxElapsedTime > 0 {
+		deadline = start.Add(r.MaxElapsedTime)
+	}
+	result, err = r.retryAttempts(ctx, client, newRequest, stream, deadline)
+	attempts := result.Attempts
+	history := result.History
+	for _, fallback := range r.FallbackURLs {
+		if ctx.Err() != nil || isShutdown() || !r.shouldFallback(result) {
+			break
+		}
+		if stream && result.raw != nil {
+			result.raw.Body.Close()
+		}
+		r.logRetry(LogLevelWarn, "Request falling back", Fields{
+			"host":       fallback.Host,
+			"attempt":    attempts,
+			"status":     result.StatusCode,
+			"error":      err,
+			"request_id": RequestIDFromContext(ctx),
+		})
+		result, err = r.retryAttempts(ctx, client, fallbackRequestFactory(newRequest, fallback, result.IdempotencyKey), stream, deadline)
+		attempts += result.Attempts
+		history = append(history, result.History...)
+	}
+	// the response of the call, not of the GET of FollowCreated
+	var created *Response
+	if r.AuditSink != nil {
+		defer func() {
+			audited := result
+			if created != nil {
+				audited = created
+			}
+			r.audit(ctx, audited.req, audited, err, start, clock.Now().Sub(start))
+		}()
+	}
+
+	if result.exhausted && r.StaleOnError && result.cached != nil {
+		r.logRetry(LogLevelWarn, "Request failed, returning stale cached response", Fields{"error": err, "request_id": RequestIDFromContext(ctx)})
+		stale := staleResponse(result.cached, attempts, clock.Now().Sub(start))
+		stale.History = history
+		return stale, nil
+	}
+	result.Attempts = attempts
+	result.History = history
+	result.Duration = clock.Now().Sub(start)
+	if r.FollowCreated && err == nil && result.StatusCode == http.StatusCreated {
+		created = result
+		result, err = r.followCreated(ctx, client, result, stream)
+	}
+	if err == nil && !r.expectsStatus(result.StatusCode) {
+		err = newUnexpectedStatusError(r.ExpectStatus, result, r.URL)
+	}
+	if err != nil && r.AttachCurl && result.req != nil {
+		err = &CurlError{Err: err, Command: r.dumper().curl(result.req)}
+	}
+	return result, err
+}
+
+// retryAttempts runs the attempts of a call against a single host, no retry
+// is started that would begin after a non zero deadline.  In stream mode the
+// response body of the final attempt is left open for the caller and Body is
+// nil, bodies of retried attempts are drained and closed.
+func (r httpRequest) retryAttempts(ctx context.Context, client *http.Client, newRequest requestFactory, stream bool, deadline time.Time) (result *Response, err error) {
+	var req *http.Request
 	var resp *http.Response
+	var respBody []byte
 	retryCount := 0
 
-	for retryCount < r.RetriesMax {
+	idempotencyKey := ""
+	cacheKey := ""
+	var cached *CachedResponse
+	fromCache := false
+	gaveUp := false
+	processed := false
+	var addresses []string
+	var history []AttemptSummary
+	clock := r.clock()
+	start := clock.Now()
+	defer func() {
+		result = newResponse(resp, respBody, retryCount, clock.Now().Sub(start))
+		result.IdempotencyKey = idempotencyKey
+		result.FromCache = fromCache
+		result.History = history
+		result.exhausted = gaveUp
+		result.mayHaveProcessed = processed
+		result.cached = cached
+		result.req = req
+	}()
+
+	if r.Metrics != nil {
+		start := clock.Now()
+		defer func() {
+			if req != nil {
+				r.Metrics.ObserveCall(req.Method, req.URL.Host, responseStatusCode(resp), err, clock.Now().Sub(start))
+			}
+		}()
+	}
+	var callSpan Span
+	if r.Tracer != nil {
+		defer func() {
+			if callSpan != nil {
+				callSpan.SetAttribute(AttributeHTTPStatusCode, responseStatusCode(resp))
+				callSpan.SetAttribute(AttributeRetryCount, retryCount)
+				if err != nil {
+					callSpan.RecordError(err)
+				}
+				callSpan.End()
+			}
+		}()
+	}
+	if r.RetryBudget != nil {
+		r.RetryBudget.deposit()
+	}
+	var wait time.Duration
+	callerRequestID := RequestIDFromContext(ctx)
+	maxAttempts := maxAttempts(r.RetriesMax)
+	refreshedToken := ""
+	reauthenticated := false
+
+	for retryCount < maxAttempts {
 		retryCount++
-		ctx = context.WithValue(ctx, "RequestId", uuid.New().String())
-		resp, respBody, err = r.doRequest(ctx, client, req)
+		ctx = attemptContext(ctx, callerRequestID)
+		var reqErr error
+		req, reqErr = newRequest(ctx)
+		if reqErr != nil {
+			resp, respBody = nil, []byte("")
+			return nil, reqErr
+		}
+		if r.InjectRequestID {
+			req.Header.Set(RequestIDHeader, RequestIDFromContext(ctx))
+		}
+		if retryCount == 1 {
+			idempotencyKey = r.idempotencyKey(req)
+			if cacheKey = r.cacheKey(req, stream); cacheKey != "" {
+				cached, _ = r.Cache.Get(cacheKey)
+			}
+			if r.RotateAddresses {
+				addresses = r.resolveAddresses(ctx, req.URL.Hostname())
+			}
+		}
+		if len(addresses) > 0 {
+			if retryCount > 1 {
+				// a pooled connection would still reach the previous address
+				client.CloseIdleConnections()
+			}
+			ctx = withDialAddress(ctx, req.URL.Hostname(), addresses[(retryCount-1)%len(addresses)])
+			req = req.WithContext(ctx)
+		}
+		if cached != nil {
+			setValidators(req, cached)
+		}
+		if idempotencyKey != "" {
+			req.Header.Set(IdempotencyKeyHeader, idempotencyKey)
+		}
+		if r.TokenSource != nil {
+			token := refreshedToken
+			if token == "" {
+				if token, err = r.TokenSource.Token(); err != nil {
+					closeRequestBody(req)
+					resp, respBody = nil, []byte("")
+					return nil, err
+				}
+			}
+			refreshedToken = ""
+			setBearerToken(req, token)
+		} else if token := r.reauthToken.get(); token != "" {
+			setBearerToken(req, token)
+		}
+		if r.RateLimiter != nil {
+			if err = r.RateLimiter.Wait(ctx); err != nil {
+				closeRequestBody(req)
+				return nil, err
+			}
+		}
+		if r.HostPolicy != nil {
+			if err = r.HostPolicy.checkHost(req.URL.Hostname()); err != nil {
+				closeRequestBody(req)
+				gaveUp = true
+				return nil, err
+			}
+		}
+		probe := false
+		if r.CircuitBreaker != nil {
+			var allowed bool
+			if allowed, probe = r.CircuitBreaker.allow(req.URL); !allowed {
+				closeRequestBody(req)
+				gaveUp = true
+				return nil, fmt.Errorf("%w for %s", ErrCircuitOpen, req.URL.Host)
+			}
+		}
+		// a half-open probe that never reaches success or failure must be
+		// released or the circuit would reject every later attempt
+		abortProbe := func() {
+			if probe {
+				r.CircuitBreaker.abort(req.URL)
+			}
+		}
+		var attemptSpan Span
+		if r.Tracer != nil {
+			if callSpan == nil {
+				ctx, callSpan = r.Tracer.Start(ctx, "HTTP "+req.Method)
+				callSpan.SetAttribute(AttributeHTTPMethod, req.Method)
+				callSpan.SetAttribute(AttributeHTTPURL, req.URL.String())
+			}
+			var attemptCtx context.Context
+			attemptCtx, attemptSpan = r.Tracer.Start(ctx, fmt.Sprintf("HTTP %s attempt %d", req.Method, retryCount))
+			attemptSpan.SetAttribute(AttributeRetryCount, retryCount)
+			attemptSpan.SetAttribute(AttributeWait, wait.String())
+			r.Tracer.Inject(attemptCtx, req.Header)
+			req = req.WithContext(attemptCtx)
+		}
+		if r.ClientTrace != nil {
+			req = req.WithContext(httptrace.WithClientTrace(req.Context(), r.ClientTrace))
+		}
+		if r.Hooks.OnRequest != nil {
+			r.Hooks.OnRequest(RetryEvent{Request: req, RetryCount: retryCount, Wait: wait})
+		}
+		var endAttempt func()
+		if endAttempt, err = beginAttempt(); err != nil {
+			closeRequestBody(req)
+			abortProbe()
+			return nil, err
+		}
+		var release func()
+		if r.HostLimiter != nil {
+			if release, err = r.HostLimiter.acquire(ctx, req.URL.Host); err != nil {
+				closeRequestBody(req)
+				endAttempt()
+				abortProbe()
+				return nil, err
+			}
+		}
+		attemptStart := clock.Now()
+		var trace *connTrace
+		if r.isHedged(req, stream) {
+			resp, respBody, err = r.hedgedRequest(ctx, client, req)
+		} else {
+			var traceCtx context.Context
+			traceCtx, trace = withConnTrace(req.Context())
+			resp, respBody, err = r.doRequest(ctx, client, req.WithContext(traceCtx), stream)
+		}
+		endAttempt()
+		if release != nil {
+			if stream && resp != nil {
+				resp.Body = &releaseOnClose{ReadCloser: resp.Body, release: release}
+			} else {
+				release()
+			}
+		}
+		if attemptSpan != nil {
+			attemptSpan.SetAttribute(AttributeHTTPStatusCode, responseStatusCode(resp))
+			if err != nil {
+				attemptSpan.RecordError(err)
+			}
+			attemptSpan.End()
+		}
+		if r.Metrics != nil {
+			r.Metrics.ObserveAttempt(req.Method, req.URL.Host, responseStatusCode(resp), err, clock.Now().Sub(attemptStart))
+		}
+		attempt := AttemptSummary{
+			Attempt:    retryCount,
+			Host:       req.URL.Host,
+			Start:      attemptStart,
+			StatusCode: responseStatusCode(resp),
+			Err:        err,
+			Duration:   clock.Now().Sub(attemptStart),
+			Wait:       wait,
+		}
+		if trace != nil {
+			attempt.Conn = trace.done()
+		}
+		if r.HARRecorder != nil {
+			r.HARRecorder.record(r.dumper(), req, resp, respBody, err, attempt)
+		}
+		history = append(history, attempt)
+		if r.AttemptRecorder != nil {
+			r.AttemptRecorder.RecordAttempt(attempt)
+		}
+		fromCache = false
+		if cached != nil && err == nil && resp.StatusCode == http.StatusNotModified {
+			resp, respBody, fromCache = cachedHttpResponse(cached, resp), cached.Body, true
+		}
+		if ctx.Err() != nil {
+			// cancelled or deadline exceeded, retrying can't succeed
+			abortProbe()
+			return nil, ctx.Err()
+		}
+		if r.canReauthenticate() && !reauthenticated && responseStatusCode(resp) == http.StatusUnauthorized {
+			reauthenticated = true
+			abortProbe()
+			if refreshedToken, err = r.reauthenticate(ctx); err != nil {
+				return nil, err
+			}
+			r.logRetry(LogLevelInfo, "Request unauthorized, retrying with a refreshed token", attemptFields(ctx, req, resp, nil, retryCount))
+			if maxAttempts < math.MaxInt {
+				maxAttempts++
+			}
+			if stream {
+				// drain so the connection can be reused
+				io.Copy(io.Discard, resp.Body)
+				resp.Body.Close()
+			}
+			wait = 0
+			continue
+		}
+		retry, failed := err != nil, err != nil
+		if err == nil {
+			if r.IsRetryCondition != nil {
+				retry = r.IsRetryCondition(resp, retryCount)
+				failed = retry
+			} else {
+				decision := r.StatusClassifier.classify(req, resp.StatusCode)
+				retry, failed = decision == StatusRetry, decision != StatusSucceed
+			}
+		}
+		if r.CircuitBreaker != nil {
+			if failed {
+				r.CircuitBreaker.failure(req.URL)
+			} else {
+				r.CircuitBreaker.success(req.URL)
+			}
+		}
 		if err != nil {
-			logrus.Warnf("Request %p:%s failed. retryCount is %v", req, ctx.Value("RequestId"), retryCount)
+			retryError := !IsPermanentTLSError(err)
+			if r.IsRetryError != nil {
+				retryError = r.IsRetryError(err, retryCount)
+			}
+			if isBodyTooLarge(err) || errors.Is(err, ErrHostNotAllowed) || !retryError {
+				gaveUp = true
+				return nil, err
+			}
+			r.logRetry(LogLevelWarn, "Request failed", attemptFields(ctx, req, nil, err, retryCount))
 		} else {
-			if r.IsRetryCondition == nil || r.IsRetryCondition(resp, retryCount) == false {
-				return respBody, resp.StatusCode, err
+			if !retry {
+				if cacheKey != "" && !fromCache && isCacheable(resp) {
+					r.Cache.Set(cacheKey, &CachedResponse{Body: respBody, StatusCode: resp.StatusCode, Header: resp.Header.Clone(), StoredAt: clock.Now()})
+				}
+				return nil, err
+			}
+			r.logRetry(LogLevelInfo, "Request IsRetryCondition returned true", attemptFields(ctx, req, resp, nil, retryCount))
+		}
+		if r.SafeRetries && mayHaveProcessed(req, resp, err, trace) {
+			r.logRetry(LogLevelWarn, "Request not retried, the upstream may have processed it", attemptFields(ctx, req, resp, err, retryCount))
+			// not gaveUp, a fallback would repeat the request just as a retry
+			// would, hedging only applies to idempotent methods
+			processed = true
+			return nil, err
+		}
+		if retryCount >= maxAttempts {
+			break
+		}
+		wait = r.Backoff.Backoff(retryCount)
+		if r.RespectRetryAfter && err == nil {
+			if retryAfter, ok := retryAfterWait(resp, r.RetryAfterMax, clock.Now()); ok {
+				wait = retryAfter
 			}
-			logrus.Infof("Request %p:%s IsRetryCondition returned true, retryCount is %v", req, ctx.Value("RequestId"), retryCount)
 		}
-		time.Sleep(r.RetriesWait)
+		// context deadlines are wall-clock whatever the Clock
+		if ctxDeadline, ok := ctx.Deadline(); ok && time.Until(ctxDeadline) < wait {
+			remaining := time.Until(ctxDeadline)
+			fields := attemptFields(ctx, req, resp, err, retryCount)
+			fields["wait"] = wait
+			r.logRetry(LogLevelWarn, "Request deadline would be exceeded", fields)
+			gaveUp = true
+			if r.Hooks.OnGiveUp != nil {
+				r.Hooks.OnGiveUp(RetryEvent{Request: req, Response: resp, Err: err, RetryCount: retryCount})
+			}
+			return nil, &DeadlineWouldExceedError{Err: err, StatusCode: responseStatusCode(resp), Attempts: retryCount, Wait: wait, Remaining: remaining}
+		}
+		if !deadline.IsZero() && clock.Now().Add(wait).After(deadline) {
+			fields := attemptFields(ctx, req, resp, err, retryCount)
+			fields["wait"] = wait
+			r.logRetry(LogLevelWarn, "Request max elapsed time exceeded", fields)
+			break
+		}
+		if r.RetryBudget != nil && !r.RetryBudget.withdraw() {
+			fields := attemptFields(ctx, req, resp, err, retryCount)
+			fields["wait"] = wait
+			r.logRetry(LogLevelWarn, "Request retry budget exhausted", fields)
+			break
+		}
+		if r.Metrics != nil {
+			r.Metrics.ObserveRetry(req.Method, req.URL.Host)
+		}
+		if r.Hooks.OnRetry != nil {
+			r.Hooks.OnRetry(RetryEvent{Request: req, Response: resp, Err: err, RetryCount: retryCount, Wait: wait})
+		}
+		if isShutdown() {
+			r.logRetry(LogLevelWarn, "Request not retried, shutting down", attemptFields(ctx, req, resp, err, retryCount))
+			gaveUp = err != nil
+			return nil, err
+		}
+		if stream && err == nil {
+			// drain so the connection can be reused
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		if sleepErr := sleepOrShutdown(ctx, clock, wait); sleepErr != nil {
+			if !errors.Is(sleepErr, ErrShutdown) || (stream && err == nil) {
+				return nil, sleepErr
+			}
+			// the last result is complete unless its body was drained
+			r.logRetry(LogLevelWarn, "Request not retried, shutting down", attemptFields(ctx, req, resp, err, retryCount))
+			gaveUp = err != nil
+			return nil, err
+		}
+	}
+
+	gaveUp = true
+	if r.Hooks.OnGiveUp != nil && req != nil {
+		r.Hooks.OnGiveUp(RetryEvent{Request: req, Response: resp, Err: err, RetryCount: retryCount})
+	}
+	if err != nil {
+		return nil, newRetryExhaustedError(err, history, clock.Now().Sub(start))
 	}
+	return nil, err
+}
 
-	return respBody, resp.StatusCode, err
+// sleepContext waits for the given duration or until the context is done,
+// whichever comes first.
+func sleepContext(ctx context.Context, wait time.Duration) error {
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// responseStatusCode returns 0 when no response was received, for example when
+// every attempt failed with a connection error.
+func responseStatusCode(resp *http.Response) int {
+	if resp == nil {
+		return 0
+	}
+	return resp.StatusCode
 }
 
 func NewHttpRequest(options HttpRequestOptions) httpRequest {
+	customBackoff, customRetriesWait := options.Backoff != nil, options.RetriesWait != 0
 	if options.RetriesMax == 0 {
 		options.RetriesMax = 10
 	}
+	if options.Backoff == nil && options.RetriesWait == 0 && options.RetriesWaitMax == 0 && options.BackoffMultiplier == 0 {
+		options.Backoff = getDefaultBackoff()
+	}
 	if options.RetriesWait == 0 {
 		options.RetriesWait = time.Second * 1
 	}
+	if options.Transport != nil {
+		client := http.Client{}
+		if options.Client != nil {
+			client = *options.Client
+		}
+		client.Transport = options.Transport
+		options.Client = &client
+	} else if options.TLSClientConfig != nil || options.ProxyURL != nil || options.ProxyFunc != nil ||
+		options.UnixSocket != "" || options.DialContext != nil || options.RotateAddresses || options.HostPolicy != nil {
+		logger := options.Logger
+		if logger == nil {
+			logger = getLogger()
+		}
+		options.Client = clientWithTransport(options.Client, logger, func(transport *http.Transport) {
+			if options.TLSClientConfig != nil {
+				transport.TLSClientConfig = options.TLSClientConfig
+			}
+			switch {
+			case options.ProxyFunc != nil:
+				transport.Proxy = options.ProxyFunc
+			case options.ProxyURL != nil:
+				transport.Proxy = http.ProxyURL(options.ProxyURL)
+			}
+			switch {
+			case options.DialContext != nil:
+				transport.DialContext = options.DialContext
+			case options.UnixSocket != "":
+				transport.DialContext = unixDialContext(options.UnixSocket)
+				transport.Proxy = nil
+			}
+			if options.HostPolicy != nil {
+				transport.DialContext = options.HostPolicy.dialContext(transport.DialContext)
+			}
+			// pinning outside the policy dials the pinned address through it
+			if options.RotateAddresses {
+				transport.DialContext = pinnedDialContext(transport.DialContext)
+			}
+		})
+	}
+	if options.UseDefaultPolicy {
+		policy := DefaultRetryPolicy()
+		if options.IsRetryCondition == nil {
+			options.IsRetryCondition = policy.IsRetryCondition
+		}
+		if options.IsRetryError == nil {
+			options.IsRetryError = policy.IsRetryError
+		}
+	} else if options.IsRetryCondition == nil && options.IsRetryError == nil {
+		policy := getDefaultRetryPolicy()
+		options.IsRetryCondition = policy.IsRetryCondition
+		options.IsRetryError = policy.IsRetryError
+	}
+	if options.StatusClassifier == nil {
+		options.StatusClassifier = DefaultStatusClassifier()
+	}
+	if options.HedgeDelay > 0 && options.HedgeMax == 0 {
+		options.HedgeMax = 1
+	}
+	if options.Redactor == nil {
+		options.Redactor = DefaultRedactor
+	}
+	if options.PollCursorParam == "" {
+		options.PollCursorParam = "cursor"
+	}
+	if options.RetryAfterMax == 0 {
+		options.RetryAfterMax = time.Minute
+	}
+	if options.Backoff == nil {
+		if options.RetriesWaitMax > 0 || options.BackoffMultiplier > 0 {
+			options.Backoff = ExponentialBackoff{Base: options.RetriesWait, Max: options.RetriesWaitMax, Multiplier: options.BackoffMultiplier}
+		} else {
+			options.Backoff = ConstantBackoff{Wait: options.RetriesWait}
+		}
+	}
+	if options.ClientRegistry == nil {
+		options.ClientRegistry = DefaultClientRegistry
+	}
 
 	// setting common buildingx headers, don't overwrite caller set options.
 	if options.Header == nil {
 		options.Header = http.Header{}
 	}
-	if options.Header.Get("Accept") == "" {
-		options.Header.Add("Accept", "application/vnd.api+json")
-		options.Header.Add("Accept", "application/json")
-		options.Header.Add("Accept", "*/*")
+	if options.UserAgent != "" && options.Header.Get("User-Agent") == "" {
+		options.Header.Set("User-Agent", options.UserAgent)
+	}
+	if options.DefaultHeaders == nil {
+		options.DefaultHeaders = getDefaultHeaders()
 	}
-	if options.Header.Get("Content-Type") == "" {
-		options.Header.Set("Content-Type", "application/vnd.api+json")
+	addMissingHeaders(options.Header, options.DefaultHeaders)
+	if options.JSONAPIHeaders {
+		addMissingHeaders(options.Header, JSONAPIHeaders())
+	}
+	if options.DecompressResponse && options.Header.Get("Accept-Encoding") == "" {
+		options.Header.Set("Accept-Encoding", "gzip, deflate")
 	}
 	if options.Header.Get("Authorization") == "" {
-		options.Header.Set("Authorization", fmt.Sprintf("Bearer %s", options.Token))
+		if options.TokenSource == nil {
+			options.Header.Set("Authorization", fmt.Sprintf("Bearer %s", options.Token))
+		}
+	} else {
+		// a caller set header wins over the token source
+		options.TokenSource = nil
 	}
 
 	return httpRequest{
@@ -155,101 +1199,192 @@ func NewHttpRequest(options HttpRequestOptions) httpRequest {
 		Header:           options.Header,
 		RetriesMax:       options.RetriesMax,
 		RetriesWait:      options.RetriesWait,
+		Backoff:          options.Backoff,
 		IsRetryCondition: options.IsRetryCondition,
-	}
-}
+		IsRetryError:     options.IsRetryError,
+		StatusClassifier: options.StatusClassifier,
+		MethodPolicies:   options.MethodPolicies,
+		PolicyRouter:     options.PolicyRouter,
 
-func (r httpRequest) HttpGet(ctx context.Context) ([]byte, int, error) {
-	client := GetSingletonHttpClient()
+		RetriesWaitMax:    options.RetriesWaitMax,
+		BackoffMultiplier: options.BackoffMultiplier,
+		customBackoff:     customBackoff,
+		customRetriesWait: customRetriesWait,
 
-	req, err := http.NewRequest(http.MethodGet, r.URL.String(), nil)
-	if err != nil {
-		return []byte(""), 0, err
-	}
+		RespectRetryAfter: options.RespectRetryAfter,
+		RetryAfterMax:     options.RetryAfterMax,
 
-	req.Header = r.Header
+		RetryBudget:    options.RetryBudget,
+		CircuitBreaker: options.CircuitBreaker,
+		Metrics:        options.Metrics,
+		Tracer:         options.Tracer,
+		ClientTrace:    options.ClientTrace,
 
-	return r.doRequestWithRetries(ctx, client, req)
-}
+		InjectRequestID: options.InjectRequestID,
 
-func (r httpRequest) HttpPost(ctx context.Context, object []byte) ([]byte, int, error) {
-	client := GetSingletonHttpClient()
+		Client:         options.Client,
+		ClientName:     options.ClientName,
+		ClientRegistry: options.ClientRegistry,
+		Redirect:       options.Redirect,
+		Jar:            options.Jar,
 
-	req, err := http.NewRequest(http.MethodPost, r.URL.String(), strings.NewReader(string(object)))
-	if err != nil {
-		return []byte(""), 0, err
-	}
+		HedgeDelay: options.HedgeDelay,
+		HedgeMax:   options.HedgeMax,
 
-	req.Header = r.Header
+		Logger:        options.Logger,
+		RetryLogLevel: options.RetryLogLevel,
 
-	return r.doRequestWithRetries(ctx, client, req)
-}
+		Redactor:          options.Redactor,
+		DisableRedaction:  options.DisableRedaction,
+		MaxDebugBodyBytes: options.MaxDebugBodyBytes,
+		DebugContentTypes: options.DebugContentTypes,
 
-func (r httpRequest) HttpPatch(ctx context.Context, object []byte) ([]byte, int, error) {
-	client := GetSingletonHttpClient()
+		Hooks:      options.Hooks,
+		Middleware: options.Middleware,
+		Clock:      options.Clock,
 
-	req, err := http.NewRequest(http.MethodPatch, r.URL.String(), strings.NewReader(string(object)))
-	if err != nil {
-		return []byte(""), 0, err
-	}
+		TokenSource:         options.TokenSource,
+		RetryOnUnauthorized: options.RetryOnUnauthorized,
+		ReauthFunc:          options.ReauthFunc,
+		reauthToken:         &tokenCache{},
 
-	req.Header = r.Header
+		RateLimiter: options.RateLimiter,
+		HostLimiter: options.HostLimiter,
 
-	return r.doRequestWithRetries(ctx, client, req)
-}
+		AddIdempotencyKey: options.AddIdempotencyKey,
+		SafeRetries:       options.SafeRetries,
+
+		Cache:        options.Cache,
+		StaleOnError: options.StaleOnError,
+
+		CompressRequest:    options.CompressRequest,
+		DecompressResponse: options.DecompressResponse,
 
-func (r httpRequest) HttpPut(ctx context.Context, object []byte) ([]byte, int, error) {
-	client := GetSingletonHttpClient()
+		RotateAddresses: options.RotateAddresses,
 
-	req, err := http.NewRequest(http.MethodPut, r.URL.String(), bytes.NewBuffer(object))
+		HostPolicy: options.HostPolicy,
+
+		FallbackURLs:        options.FallbackURLs,
+		FallbackStatusCodes: options.FallbackStatusCodes,
+
+		AttemptRecorder: options.AttemptRecorder,
+
+		MaxElapsedTime: options.MaxElapsedTime,
+
+		MaxResponseBytes: options.MaxResponseBytes,
+		TruncateResponse: options.TruncateResponse,
+
+		ExpectedSHA256: options.ExpectedSHA256,
+		VerifyDigest:   options.VerifyDigest,
+
+		GraphQLRetryCodes: options.GraphQLRetryCodes,
+
+		PollCursorParam: options.PollCursorParam,
+
+		FollowCreated: options.FollowCreated,
+
+		ExpectStatus: options.ExpectStatus,
+
+		DryRun: options.DryRun,
+
+		AuditSink:       options.AuditSink,
+		AuditHashBodies: options.AuditHashBodies,
+		HARRecorder:     options.HARRecorder,
+		AttachCurl:      options.AttachCurl,
+	}
+}
+
+// Do sends a request with any method, for example http.MethodHead or
+// http.MethodOptions.  object is sent as the body unless it is nil.
+func (r httpRequest) Do(ctx context.Context, method string, object []byte, opts ...CallOption) ([]byte, int, error) {
+	r = r.forCall(method, opts)
+	client, err := r.httpClient()
 	if err != nil {
 		return []byte(""), 0, err
 	}
 
-	req.Header = r.Header
+	return r.doRequestWithRetries(ctx, client, r.newRequestFactory(method, r.URL.String(), object))
+}
 
-	return r.doRequestWithRetries(ctx, client, req)
+func (r httpRequest) HttpGet(ctx context.Context, opts ...CallOption) ([]byte, int, error) {
+	return r.Do(ctx, http.MethodGet, nil, opts...)
 }
 
-func (r httpRequest) HttpDelete(ctx context.Context) ([]byte, int, error) {
-	client := GetSingletonHttpClient()
+func (r httpRequest) HttpPost(ctx context.Context, object []byte, opts ...CallOption) ([]byte, int, error) {
+	return r.Do(ctx, http.MethodPost, object, opts...)
+}
 
-	u, err := url.ParseRequestURI(r.URL.String())
-	if err != nil {
-		return []byte(""), 0, err
-	}
-	urlStr := u.String()
+func (r httpRequest) HttpPatch(ctx context.Context, object []byte, opts ...CallOption) ([]byte, int, error) {
+	return r.Do(ctx, http.MethodPatch, object, opts...)
+}
 
-	req, err := http.NewRequest(http.MethodDelete, urlStr, nil)
-	if err != nil {
+func (r httpRequest) HttpPut(ctx context.Context, object []byte, opts ...CallOption) ([]byte, int, error) {
+	return r.Do(ctx, http.MethodPut, object, opts...)
+}
+
+func (r httpRequest) HttpDelete(ctx context.Context, opts ...CallOption) ([]byte, int, error) {
+	if _, err := url.ParseRequestURI(r.URL.String()); err != nil {
 		return []byte(""), 0, err
 	}
 
-	req.Header = r.Header
-
-	return r.doRequestWithRetries(ctx, client, req)
+	return r.Do(ctx, http.MethodDelete, nil, opts...)
 }
 
+// ExtractErrorFromResponse describes an unexpected response as an
+// UnexpectedStatusError.
+//
+// Deprecated: set ExpectStatus to get an UnexpectedStatusError from the call.
 func ExtractErrorFromResponse(expectedStatus int, actualStatusCode int, urlCalled *url.URL, responseBody []byte) error {
-	return fmt.Errorf("expected %d,\nactual: %d,\nURL: %s,\nresponse: %s", expectedStatus, actualStatusCode, urlCalled.String(), string(responseBody))
+	return unexpectedStatusError([]int{expectedStatus}, actualStatusCode, nil, urlCalled, responseBody)
 }
 
 func DebugRequest(ctx context.Context, req *http.Request, token string) {
-	// log := hlogger.Current(ctx)
-	reqBytes, err := httputil.DumpRequest(req, true)
+	dumper{log: getLogger(), redactor: DefaultRedactor, token: token}.request(ctx, req, true)
+}
+
+func DebugResponse(ctx context.Context, resp *http.Response, token string) {
+	dumper{log: getLogger(), redactor: DefaultRedactor, token: token}.response(ctx, resp, true)
+}
+
+// dumper logs requests and responses at debug level with secrets redacted.
+type dumper struct {
+	log          Logger
+	redactor     *Redactor
+	token        string
+	maxBodyBytes int
+	contentTypes []string
+}
+
+func (r httpRequest) dumper() dumper {
+	d := dumper{
+		log:          r.logger(),
+		redactor:     r.Redactor,
+		token:        r.Token,
+		maxBodyBytes: r.MaxDebugBodyBytes,
+		contentTypes: r.DebugContentTypes,
+	}
+	if r.DisableRedaction {
+		d.redactor = nil
+	}
+	return d
+}
+
+func (d dumper) request(ctx context.Context, req *http.Request, body bool) {
+	omitted := body && !d.dumpsBody(req.Header)
+	reqBytes, err := httputil.DumpRequest(req, body && !omitted)
 	if err != nil {
-		logrus.Errorf("DumpRequest failed: %v", err)
+		d.log.Errorf("DumpRequest failed: %v", err)
 		return
 	}
-	logrus.Debugf("Request %p:%s:\n%s", req, ctx.Value("RequestId"), string(reqBytes))
+	d.log.Debugf("Request %s:\n%s", RequestIDFromContext(ctx), d.format(reqBytes, req.Header, omitted))
 }
 
-func DebugResponse(ctx context.Context, resp *http.Response, token string) {
-	// log := hlogger.Current(ctx)
-	respBytes, err := httputil.DumpResponse(resp, true)
+func (d dumper) response(ctx context.Context, resp *http.Response, body bool) {
+	omitted := body && !d.dumpsBody(resp.Header)
+	respBytes, err := httputil.DumpResponse(resp, body && !omitted)
 	if err != nil {
-		logrus.WithError(err).Errorf("DumpResponse failed")
+		d.log.Errorf("DumpResponse failed: %v", err)
 		return
 	}
-	logrus.Debugf("Response for %s:\n%s", ctx.Value("RequestId"), string(respBytes))
+	d.log.Debugf("Response for %s:\n%s", RequestIDFromContext(ctx), d.format(respBytes, resp.Header, omitted))
 }
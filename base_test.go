@@ -7,6 +7,7 @@ import (
 	"net/http/httptest"
 	"net/url"
 	"testing"
+	"time"
 
 	// "github.com/avast/retry-go/v4"
 	"github.com/google/uuid"
@@ -64,3 +65,171 @@ func TestIntegration_HttpPost(t *testing.T) {
 		})
 	})
 }
+
+func TestIntegration_HttpGetContextCancel(t *testing.T) {
+
+	t.Run("GIVEN a server that always returns 503", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer ts.Close()
+
+		t.Run("AND http request with a long wait between retries", func(t *testing.T) {
+			url, err := url.Parse(ts.URL)
+			require.NoError(t, err)
+
+			api := NewHttpRequest(HttpRequestOptions{
+				URL:         url,
+				RetriesWait: time.Hour,
+				IsRetryCondition: func(resp *http.Response, retryCount int) bool {
+					return resp.StatusCode == http.StatusServiceUnavailable
+				},
+			})
+
+			t.Run("WHEN HttpGet is sent with a context that times out", func(t *testing.T) {
+				ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+				defer cancel()
+
+				start := time.Now()
+				_, code, err := api.HttpGet(ctx)
+
+				t.Run("THEN the call returns the context error without waiting", func(t *testing.T) {
+					assert.ErrorIs(t, err, context.DeadlineExceeded)
+					assert.Equal(t, http.StatusServiceUnavailable, code)
+					assert.Less(t, time.Since(start), time.Second)
+				})
+			})
+		})
+	})
+}
+
+func TestIntegration_RequestBodyRewind(t *testing.T) {
+
+	for _, method := range []string{http.MethodPost, http.MethodPatch, http.MethodPut} {
+		method := method
+
+		t.Run("GIVEN a server that returns 503 for 3 requests AND records the last "+method+" body", func(t *testing.T) {
+			attempts := 3
+			var lastBody string
+
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, err := ioutil.ReadAll(r.Body)
+				require.NoError(t, err)
+				lastBody = string(body)
+				if attempts > 0 {
+					w.WriteHeader(http.StatusServiceUnavailable)
+					attempts--
+					return
+				}
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer ts.Close()
+
+			url, err := url.Parse(ts.URL)
+			require.NoError(t, err)
+
+			api := NewHttpRequest(HttpRequestOptions{
+				URL:         url,
+				RetriesWait: time.Millisecond,
+				IsRetryCondition: func(resp *http.Response, retryCount int) bool {
+					return resp.StatusCode == http.StatusServiceUnavailable
+				},
+			})
+
+			t.Run("WHEN the request is retried", func(t *testing.T) {
+				requestBody := uuid.New().String()
+				var code int
+				switch method {
+				case http.MethodPost:
+					_, code, err = api.HttpPost(context.Background(), []byte(requestBody))
+				case http.MethodPatch:
+					_, code, err = api.HttpPatch(context.Background(), []byte(requestBody))
+				case http.MethodPut:
+					_, code, err = api.HttpPut(context.Background(), []byte(requestBody))
+				}
+				require.NoError(t, err)
+
+				t.Run("THEN the last attempt sends the complete body", func(t *testing.T) {
+					assert.Equal(t, http.StatusOK, code)
+					assert.Equal(t, 0, attempts)
+					assert.Equal(t, requestBody, lastBody)
+				})
+			})
+		})
+	}
+}
+
+func TestIntegration_Do(t *testing.T) {
+
+	t.Run("GIVEN a server that echoes the method in a header", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-Method", r.Method)
+			w.WriteHeader(http.StatusOK)
+			if r.Method != http.MethodHead {
+				w.Write([]byte(r.Method))
+			}
+		}))
+		defer ts.Close()
+
+		url, err := url.Parse(ts.URL)
+		require.NoError(t, err)
+		api := NewHttpRequest(HttpRequestOptions{URL: url})
+
+		for _, method := range []string{http.MethodHead, http.MethodOptions, "PROPFIND"} {
+			method := method
+
+			t.Run("WHEN Do is sent with "+method, func(t *testing.T) {
+				body, code, err := api.Do(context.Background(), method, nil)
+				require.NoError(t, err)
+
+				t.Run("THEN the server receives the method", func(t *testing.T) {
+					assert.Equal(t, http.StatusOK, code)
+					if method == http.MethodHead {
+						assert.Empty(t, body)
+					} else {
+						assert.Equal(t, method, string(body))
+					}
+				})
+			})
+		}
+	})
+}
+
+func TestIntegration_MaxElapsedTime(t *testing.T) {
+
+	t.Run("GIVEN a server that always returns 503", func(t *testing.T) {
+		calls := 0
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer ts.Close()
+
+		u, err := url.Parse(ts.URL)
+		require.NoError(t, err)
+
+		t.Run("AND http request with many retries AND a max elapsed time", func(t *testing.T) {
+			api := NewHttpRequest(HttpRequestOptions{
+				URL:            u,
+				RetriesMax:     100,
+				RetriesWait:    20 * time.Millisecond,
+				MaxElapsedTime: 50 * time.Millisecond,
+				IsRetryCondition: func(resp *http.Response, retryCount int) bool {
+					return resp.StatusCode == http.StatusServiceUnavailable
+				},
+			})
+
+			t.Run("WHEN HttpGetFull is sent", func(t *testing.T) {
+				resp, err := api.HttpGetFull(context.Background())
+				require.NoError(t, err)
+
+				t.Run("THEN it gives up within the budget", func(t *testing.T) {
+					assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+					assert.Less(t, resp.Duration, 100*time.Millisecond)
+					assert.GreaterOrEqual(t, calls, 2)
+					assert.Less(t, calls, 100)
+				})
+			})
+		})
+	})
+}
@@ -0,0 +1,93 @@
+package httpretry
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"sync"
+)
+
+// BatchItem is one request of a Batch.
+type BatchItem struct {
+	// Method defaults to GET
+	Method string
+
+	// URL defaults to the URL of the request running the batch
+	URL *url.URL
+
+	// Body is sent unless it is nil
+	Body []byte
+
+	// Options apply to this item only, for example WithHeader
+	Options []CallOption
+}
+
+// BatchResult is the outcome of the BatchItem at the same index.  Response
+// holds the body, status, attempts and history and is nil when the item
+// wasn't sent because ctx was cancelled first.
+type BatchResult struct {
+	Response *Response
+	Err      error
+}
+
+type BatchOptions struct {
+	// Workers is the number of items sent concurrently
+	// defaults to 4
+	Workers int
+
+	// RateLimiter is waited on before every attempt of every item, it takes
+	// precedence over the RateLimiter of the request
+	RateLimiter RateLimiter
+}
+
+// Batch sends items concurrently with a bounded worker pool and returns the
+// results in the order of items, for example to fan out from a lambda
+// without exhausting its connections:
+//
+//	limiter, _ := httpretry.NewRateLimiter(50, 10)
+//	results := api.Batch(ctx, items, httpretry.BatchOptions{Workers: 8, RateLimiter: limiter})
+//
+// Every item is retried on its own like DoFull, so one flaky item doesn't
+// delay the others beyond its worker.
+func (r httpRequest) Batch(ctx context.Context, items []BatchItem, options BatchOptions) []BatchResult {
+	if options.Workers <= 0 {
+		options.Workers = 4
+	}
+	if options.RateLimiter != nil {
+		r.RateLimiter = options.RateLimiter
+	}
+
+	results := make([]BatchResult, len(items))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < options.Workers && w < len(items); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				results[i] = r.batchItem(ctx, items[i])
+			}
+		}()
+	}
+	for i := range items {
+		select {
+		case indexes <- i:
+		case <-ctx.Done():
+			results[i] = BatchResult{Err: ctx.Err()}
+		}
+	}
+	close(indexes)
+	wg.Wait()
+	return results
+}
+
+func (r httpRequest) batchItem(ctx context.Context, item BatchItem) BatchResult {
+	if item.Method == "" {
+		item.Method = http.MethodGet
+	}
+	if item.URL != nil {
+		r.URL = item.URL
+	}
+	resp, err := r.DoFull(ctx, item.Method, item.Body, item.Options...)
+	return BatchResult{Response: resp, Err: err}
+}
@@ -0,0 +1,100 @@
+package httpretry
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntegration_Batch(t *testing.T) {
+
+	t.Run("GIVEN a server failing the first request to /flaky", func(t *testing.T) {
+		var inFlight, maxInFlight int32
+		var mu sync.Mutex
+		flaky := 1
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			n := atomic.AddInt32(&inFlight, 1)
+			defer atomic.AddInt32(&inFlight, -1)
+			for {
+				max := atomic.LoadInt32(&maxInFlight)
+				if n <= max || atomic.CompareAndSwapInt32(&maxInFlight, max, n) {
+					break
+				}
+			}
+			time.Sleep(10 * time.Millisecond)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if r.URL.Path == "/flaky" && flaky > 0 {
+				flaky--
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.Write([]byte(r.Method + " " + r.URL.Path))
+		}))
+		defer ts.Close()
+
+		base, err := url.Parse(ts.URL)
+		require.NoError(t, err)
+		at := func(path string) *url.URL {
+			u, err := base.Parse(path)
+			require.NoError(t, err)
+			return u
+		}
+
+		t.Run("WHEN a batch is sent with 2 workers", func(t *testing.T) {
+			api := NewHttpRequest(HttpRequestOptions{
+				URL:              at("/default"),
+				RetriesWait:      time.Millisecond,
+				IsRetryCondition: RetryOnStatus(http.StatusServiceUnavailable),
+			})
+			items := []BatchItem{
+				{},
+				{URL: at("/flaky")},
+				{Method: http.MethodPost, URL: at("/things"), Body: []byte(`{}`)},
+				{URL: at("/a")},
+				{URL: at("/b")},
+			}
+			results := api.Batch(context.Background(), items, BatchOptions{Workers: 2})
+
+			t.Run("THEN the results are in the order of the items", func(t *testing.T) {
+				require.Len(t, results, 5)
+				expected := []string{"GET /default", "GET /flaky", "POST /things", "GET /a", "GET /b"}
+				for i, result := range results {
+					require.NoError(t, result.Err)
+					assert.Equal(t, expected[i], string(result.Response.Body))
+				}
+			})
+
+			t.Run("AND the flaky item is retried on its own", func(t *testing.T) {
+				assert.Equal(t, 2, results[1].Response.Attempts)
+				assert.Equal(t, 1, results[0].Response.Attempts)
+			})
+
+			t.Run("AND at most 2 requests are in flight", func(t *testing.T) {
+				assert.LessOrEqual(t, atomic.LoadInt32(&maxInFlight), int32(2))
+			})
+		})
+
+		t.Run("WHEN the context is cancelled", func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			api := NewHttpRequest(HttpRequestOptions{URL: at("/a")})
+			results := api.Batch(ctx, []BatchItem{{}, {}}, BatchOptions{})
+
+			t.Run("THEN every item fails with the context error", func(t *testing.T) {
+				for _, result := range results {
+					assert.ErrorIs(t, result.Err, context.Canceled)
+				}
+			})
+		})
+	})
+}
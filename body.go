@@ -0,0 +1,104 @@
+package httpretry
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"os"
+)
+
+// BodyFunc returns a new reader over the complete request body, it is called
+// once per attempt so large payloads can be streamed from their source on
+// every retry instead of being buffered in memory.
+type BodyFunc func() (io.ReadCloser, error)
+
+// FileBody streams the file at path, reopening it for every attempt.
+func FileBody(path string) BodyFunc {
+	return func() (io.ReadCloser, error) {
+		return os.Open(path)
+	}
+}
+
+// ReaderAtBody streams size bytes of r, for example a file already open, and
+// starts over from offset zero for every attempt.
+func ReaderAtBody(r io.ReaderAt, size int64) BodyFunc {
+	return func() (io.ReadCloser, error) {
+		return sectionBody{io.NewSectionReader(r, 0, size)}, nil
+	}
+}
+
+type sectionBody struct {
+	*io.SectionReader
+}
+
+func (sectionBody) Close() error {
+	return nil
+}
+
+// streamedBody marks request bodies that must not be buffered, for example
+// by debug dumps.
+type streamedBody struct {
+	io.ReadCloser
+}
+
+func isStreamedBody(req *http.Request) bool {
+	_, ok := req.Body.(streamedBody)
+	return ok
+}
+
+func (r httpRequest) newBodyFuncRequestFactory(method string, urlStr string, getBody BodyFunc) requestFactory {
+	return func(ctx context.Context) (*http.Request, error) {
+		body, err := getBody()
+		if err != nil {
+			return nil, err
+		}
+		req, err := http.NewRequestWithContext(ctx, method, urlStr, body)
+		if err != nil {
+			body.Close()
+			return nil, err
+		}
+		req.Header = r.Header.Clone()
+		// lets net/http rewind the body itself, for example on redirects
+		req.GetBody = getBody
+		req.ContentLength = bodyLength(body)
+		req.Body = streamedBody{body}
+		return req, nil
+	}
+}
+
+// bodyLength returns the size of files and sections so the body isn't sent
+// with chunked encoding, -1 means unknown.
+func bodyLength(body io.Reader) int64 {
+	switch b := body.(type) {
+	case *os.File:
+		if info, err := b.Stat(); err == nil && info.Mode().IsRegular() {
+			return info.Size()
+		}
+	case sectionBody:
+		return b.Size()
+	}
+	return -1
+}
+
+// DoBody sends a request with a body streamed from getBody, see BodyFunc.
+func (r httpRequest) DoBody(ctx context.Context, method string, getBody BodyFunc, opts ...CallOption) ([]byte, int, error) {
+	r = r.forCall(method, opts)
+	client, err := r.httpClient()
+	if err != nil {
+		return []byte(""), 0, err
+	}
+
+	return r.doRequestWithRetries(ctx, client, r.newBodyFuncRequestFactory(method, r.URL.String(), getBody))
+}
+
+func (r httpRequest) HttpPostBody(ctx context.Context, getBody BodyFunc, opts ...CallOption) ([]byte, int, error) {
+	return r.DoBody(ctx, http.MethodPost, getBody, opts...)
+}
+
+func (r httpRequest) HttpPutBody(ctx context.Context, getBody BodyFunc, opts ...CallOption) ([]byte, int, error) {
+	return r.DoBody(ctx, http.MethodPut, getBody, opts...)
+}
+
+func (r httpRequest) HttpPatchBody(ctx context.Context, getBody BodyFunc, opts ...CallOption) ([]byte, int, error) {
+	return r.DoBody(ctx, http.MethodPatch, getBody, opts...)
+}
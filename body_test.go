@@ -0,0 +1,116 @@
+package httpretry
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntegration_HttpPostBody(t *testing.T) {
+
+	t.Run("GIVEN a server that returns 503 for 2 requests AND records the last body", func(t *testing.T) {
+		attempts := 2
+		var lastBody string
+		var lastLength int64
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, err := io.ReadAll(r.Body)
+			require.NoError(t, err)
+			lastBody = string(body)
+			lastLength = r.ContentLength
+			if attempts > 0 {
+				attempts--
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.WriteHeader(http.StatusCreated)
+		}))
+		defer ts.Close()
+
+		url, err := url.Parse(ts.URL)
+		require.NoError(t, err)
+
+		api := NewHttpRequest(HttpRequestOptions{
+			URL:         url,
+			RetriesWait: time.Millisecond,
+			IsRetryCondition: func(resp *http.Response, retryCount int) bool {
+				return resp.StatusCode == http.StatusServiceUnavailable
+			},
+		})
+
+		t.Run("AND a file to upload", func(t *testing.T) {
+			payload := strings.Repeat("payload", 10000)
+			path := filepath.Join(t.TempDir(), "upload.txt")
+			require.NoError(t, os.WriteFile(path, []byte(payload), 0o600))
+
+			t.Run("WHEN HttpPostBody streams the file", func(t *testing.T) {
+				_, code, err := api.HttpPostBody(context.Background(), FileBody(path))
+				require.NoError(t, err)
+
+				t.Run("THEN the last attempt sends the complete file", func(t *testing.T) {
+					assert.Equal(t, http.StatusCreated, code)
+					assert.Equal(t, payload, lastBody)
+					assert.Equal(t, int64(len(payload)), lastLength)
+				})
+			})
+
+			t.Run("WHEN HttpPutBody streams an open file", func(t *testing.T) {
+				attempts = 2
+				f, err := os.Open(path)
+				require.NoError(t, err)
+				defer f.Close()
+
+				_, code, err := api.HttpPutBody(context.Background(), ReaderAtBody(f, int64(len(payload))))
+				require.NoError(t, err)
+
+				t.Run("THEN the last attempt sends the complete file", func(t *testing.T) {
+					assert.Equal(t, http.StatusCreated, code)
+					assert.Equal(t, payload, lastBody)
+					assert.Equal(t, int64(len(payload)), lastLength)
+				})
+			})
+		})
+	})
+}
+
+type closeRecorder struct {
+	io.Reader
+	closed bool
+}
+
+func (c *closeRecorder) Close() error {
+	c.closed = true
+	return nil
+}
+
+func TestIntegration_HttpPostBodyNotSent(t *testing.T) {
+
+	t.Run("GIVEN http request with an open circuit", func(t *testing.T) {
+		url, err := url.Parse("http://127.0.0.1:1")
+		require.NoError(t, err)
+		breaker := NewCircuitBreaker(CircuitBreakerOptions{Threshold: 1, Cooldown: time.Hour})
+		breaker.failure(url)
+		api := NewHttpRequest(HttpRequestOptions{URL: url, CircuitBreaker: breaker})
+
+		t.Run("WHEN HttpPostBody is sent", func(t *testing.T) {
+			body := &closeRecorder{Reader: strings.NewReader("payload")}
+			_, _, err := api.HttpPostBody(context.Background(), func() (io.ReadCloser, error) {
+				return body, nil
+			})
+
+			t.Run("THEN the body is closed without being sent", func(t *testing.T) {
+				assert.ErrorIs(t, err, ErrCircuitOpen)
+				assert.True(t, body.closed)
+			})
+		})
+	})
+}
@@ -0,0 +1,182 @@
+package httpretry
+
+import (
+	"errors"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned without sending the request while the circuit for
+// its upstream is open.  Check for it with errors.Is to report the upstream as
+// degraded.
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+type CircuitState int
+
+const (
+	// CircuitClosed lets requests through, the upstream is healthy.
+	CircuitClosed CircuitState = iota
+	// CircuitOpen fails requests fast until the cool-down has passed.
+	CircuitOpen
+	// CircuitHalfOpen lets a single probe request through after the
+	// cool-down, its outcome closes or re-opens the circuit.
+	CircuitHalfOpen
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	default:
+		return "closed"
+	}
+}
+
+type CircuitBreakerOptions struct {
+	// Threshold consecutive failed attempts that open the circuit
+	// defaults to 5
+	Threshold int
+
+	// Cooldown amount of time the circuit stays open before a probe
+	// defaults to 30sec
+	Cooldown time.Duration
+
+	// Key groups requests into circuits, for example by URL prefix
+	// defaults to the URL host
+	Key func(u *url.URL) string
+
+	// Clock tells the time the cool-down is measured with
+	// defaults to the system clock
+	Clock Clock
+}
+
+// CircuitBreaker tracks consecutive failures per upstream and fast-fails
+// requests to an upstream that keeps failing instead of burning the whole
+// retry budget on every call.  A failure is an attempt that returned an error,
+// that IsRetryCondition asked to retry or that StatusClassifier retried or
+// failed.
+//
+// A single breaker is safe for concurrent use and meant to be shared between
+// httpRequest instances.
+type CircuitBreaker struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	key       func(u *url.URL) string
+	clock     Clock
+	circuits  map[string]*circuit
+}
+
+type circuit struct {
+	state    CircuitState
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+func NewCircuitBreaker(options CircuitBreakerOptions) *CircuitBreaker {
+	if options.Threshold == 0 {
+		options.Threshold = 5
+	}
+	if options.Cooldown == 0 {
+		options.Cooldown = time.Second * 30
+	}
+	if options.Key == nil {
+		options.Key = func(u *url.URL) string { return u.Host }
+	}
+	if options.Clock == nil {
+		options.Clock = systemClock{}
+	}
+	return &CircuitBreaker{
+		threshold: options.Threshold,
+		cooldown:  options.Cooldown,
+		key:       options.Key,
+		clock:     options.Clock,
+		circuits:  map[string]*circuit{},
+	}
+}
+
+// State returns the state of the circuit for the given URL.
+func (b *CircuitBreaker) State(u *url.URL) CircuitState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	c, ok := b.circuits[b.key(u)]
+	if !ok {
+		return CircuitClosed
+	}
+	if c.state == CircuitOpen && b.clock.Now().Sub(c.openedAt) >= b.cooldown {
+		return CircuitHalfOpen
+	}
+	return c.state
+}
+
+// Reset closes the circuit for the given URL.
+func (b *CircuitBreaker) Reset(u *url.URL) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.circuits, b.key(u))
+}
+
+// allow reports whether an attempt to u may be sent and whether that attempt
+// is the half-open probe, which must be settled with success, failure or
+// abort.
+func (b *CircuitBreaker) allow(u *url.URL) (ok, probe bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	c, found := b.circuits[b.key(u)]
+	if !found {
+		return true, false
+	}
+	switch c.state {
+	case CircuitOpen:
+		if b.clock.Now().Sub(c.openedAt) < b.cooldown {
+			return false, false
+		}
+		c.state = CircuitHalfOpen
+		c.probing = true
+		return true, true
+	case CircuitHalfOpen:
+		if c.probing {
+			return false, false
+		}
+		c.probing = true
+		return true, true
+	}
+	return true, false
+}
+
+// abort releases a half-open probe that was never settled, for example
+// because its context was cancelled, so the next attempt can probe again.
+func (b *CircuitBreaker) abort(u *url.URL) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if c, ok := b.circuits[b.key(u)]; ok && c.state == CircuitHalfOpen {
+		c.probing = false
+	}
+}
+
+func (b *CircuitBreaker) success(u *url.URL) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.circuits, b.key(u))
+}
+
+func (b *CircuitBreaker) failure(u *url.URL) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	key := b.key(u)
+	c, ok := b.circuits[key]
+	if !ok {
+		c = &circuit{}
+		b.circuits[key] = c
+	}
+	c.failures++
+	c.probing = false
+	if c.state == CircuitHalfOpen || c.failures >= b.threshold {
+		c.state = CircuitOpen
+		c.openedAt = b.clock.Now()
+	}
+}
@@ -0,0 +1,148 @@
+package httpretry
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mandric/httpretry/httpretrytest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntegration_CircuitBreaker(t *testing.T) {
+
+	t.Run("GIVEN a server that returns 503 until it is healthy", func(t *testing.T) {
+		healthy := false
+		requests := 0
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			if !healthy {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer ts.Close()
+
+		url, err := url.Parse(ts.URL)
+		require.NoError(t, err)
+
+		t.Run("AND http request with a breaker that opens after 3 failures", func(t *testing.T) {
+			breaker := NewCircuitBreaker(CircuitBreakerOptions{
+				Threshold: 3,
+				Cooldown:  50 * time.Millisecond,
+			})
+			api := NewHttpRequest(HttpRequestOptions{
+				URL:            url,
+				RetriesMax:     5,
+				RetriesWait:    time.Millisecond,
+				CircuitBreaker: breaker,
+				IsRetryCondition: func(resp *http.Response, retryCount int) bool {
+					return resp.StatusCode == http.StatusServiceUnavailable
+				},
+			})
+
+			t.Run("WHEN HttpGet is sent", func(t *testing.T) {
+				_, _, err := api.HttpGet(context.Background())
+
+				t.Run("THEN the call fails fast once the circuit opens", func(t *testing.T) {
+					assert.ErrorIs(t, err, ErrCircuitOpen)
+					assert.Equal(t, 3, requests)
+					assert.Equal(t, CircuitOpen, breaker.State(url))
+				})
+			})
+
+			t.Run("WHEN the upstream recovers AND the cool-down passes", func(t *testing.T) {
+				healthy = true
+				time.Sleep(60 * time.Millisecond)
+				assert.Equal(t, CircuitHalfOpen, breaker.State(url))
+
+				_, code, err := api.HttpGet(context.Background())
+				require.NoError(t, err)
+
+				t.Run("THEN the probe succeeds AND the circuit closes", func(t *testing.T) {
+					assert.Equal(t, http.StatusOK, code)
+					assert.Equal(t, CircuitClosed, breaker.State(url))
+				})
+			})
+		})
+	})
+}
+
+func TestIntegration_CircuitBreakerAbortedProbe(t *testing.T) {
+
+	t.Run("GIVEN a server that fails, then hangs, then recovers", func(t *testing.T) {
+		var phase atomic.Int32
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch phase.Load() {
+			case 0:
+				w.WriteHeader(http.StatusServiceUnavailable)
+			case 1:
+				<-r.Context().Done()
+			default:
+				w.WriteHeader(http.StatusOK)
+			}
+		}))
+		defer ts.Close()
+
+		url, err := url.Parse(ts.URL)
+		require.NoError(t, err)
+
+		t.Run("AND http request with a breaker that opens after 1 failure", func(t *testing.T) {
+			breaker := NewCircuitBreaker(CircuitBreakerOptions{
+				Threshold: 1,
+				Cooldown:  10 * time.Millisecond,
+			})
+			api := NewHttpRequest(HttpRequestOptions{
+				URL:            url,
+				RetriesMax:     1,
+				CircuitBreaker: breaker,
+			})
+
+			_, _, _ = api.HttpGet(context.Background())
+			require.Equal(t, CircuitOpen, breaker.State(url))
+
+			t.Run("WHEN the half-open probe is cancelled", func(t *testing.T) {
+				time.Sleep(20 * time.Millisecond)
+				phase.Store(1)
+				ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+				defer cancel()
+				_, _, err := api.HttpGet(ctx)
+				require.ErrorIs(t, err, context.DeadlineExceeded)
+
+				t.Run("THEN the next call probes again AND closes the circuit", func(t *testing.T) {
+					phase.Store(2)
+					_, code, err := api.HttpGet(context.Background())
+					require.NoError(t, err)
+					assert.Equal(t, http.StatusOK, code)
+					assert.Equal(t, CircuitClosed, breaker.State(url))
+				})
+			})
+		})
+	})
+}
+
+func TestCircuitBreakerClock(t *testing.T) {
+
+	t.Run("GIVEN a breaker with a fake clock that opened", func(t *testing.T) {
+		clock := httpretrytest.NewFakeClock(time.Now())
+		breaker := NewCircuitBreaker(CircuitBreakerOptions{Threshold: 1, Cooldown: time.Hour, Clock: clock})
+		u, err := url.Parse("https://api.example.com")
+		require.NoError(t, err)
+		breaker.failure(u)
+		require.Equal(t, CircuitOpen, breaker.State(u))
+
+		t.Run("WHEN the clock passes the cool-down", func(t *testing.T) {
+			clock.Advance(time.Hour)
+
+			t.Run("THEN the circuit is half-open", func(t *testing.T) {
+				assert.Equal(t, CircuitHalfOpen, breaker.State(u))
+			})
+		})
+	})
+}
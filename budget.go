@@ -0,0 +1,56 @@
+package httpretry
+
+import (
+	"sync"
+)
+
+// RetryBudget limits retries to a fraction of the requests sent, token-bucket
+// style.  Every call deposits ratio tokens and every retry withdraws one, so
+// with a ratio of 0.1 at most about 10% of the traffic is retries once the
+// initial burst is spent.
+//
+// Share one budget across httpRequest instances to stop retry amplification
+// when an upstream is down: instead of every call retrying RetriesMax times
+// retries stop once the budget runs dry and calls fail fast.
+type RetryBudget struct {
+	mu        sync.Mutex
+	ratio     float64
+	maxTokens float64
+	tokens    float64
+}
+
+// NewRetryBudget returns a budget that starts with burst tokens, never holds
+// more than burst tokens and earns ratio tokens per call.
+func NewRetryBudget(ratio float64, burst int) *RetryBudget {
+	return &RetryBudget{
+		ratio:     ratio,
+		maxTokens: float64(burst),
+		tokens:    float64(burst),
+	}
+}
+
+// Tokens returns the number of retries currently available.
+func (b *RetryBudget) Tokens() float64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.tokens
+}
+
+func (b *RetryBudget) deposit() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.tokens += b.ratio
+	if b.tokens > b.maxTokens {
+		b.tokens = b.maxTokens
+	}
+}
+
+func (b *RetryBudget) withdraw() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
@@ -0,0 +1,80 @@
+package httpretry
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetryBudget(t *testing.T) {
+
+	t.Run("GIVEN a budget with a burst of 2", func(t *testing.T) {
+		budget := NewRetryBudget(0.5, 2)
+
+		t.Run("THEN only 2 retries are allowed until calls earn more", func(t *testing.T) {
+			assert.True(t, budget.withdraw())
+			assert.True(t, budget.withdraw())
+			assert.False(t, budget.withdraw())
+
+			budget.deposit()
+			assert.False(t, budget.withdraw())
+			budget.deposit()
+			assert.True(t, budget.withdraw())
+		})
+
+		t.Run("THEN deposits never exceed the burst", func(t *testing.T) {
+			for i := 0; i < 10; i++ {
+				budget.deposit()
+			}
+			assert.Equal(t, float64(2), budget.Tokens())
+		})
+	})
+}
+
+func TestIntegration_RetryBudget(t *testing.T) {
+
+	t.Run("GIVEN a server that always returns 503", func(t *testing.T) {
+		requests := 0
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer ts.Close()
+
+		t.Run("AND two requests sharing a budget of 3 retries", func(t *testing.T) {
+			url, err := url.Parse(ts.URL)
+			require.NoError(t, err)
+
+			budget := NewRetryBudget(0, 3)
+			options := HttpRequestOptions{
+				URL:         url,
+				RetriesWait: time.Millisecond,
+				RetryBudget: budget,
+				IsRetryCondition: func(resp *http.Response, retryCount int) bool {
+					return resp.StatusCode == http.StatusServiceUnavailable
+				},
+			}
+			first := NewHttpRequest(options)
+			second := NewHttpRequest(options)
+
+			t.Run("WHEN both are sent", func(t *testing.T) {
+				_, code, err := first.HttpGet(context.Background())
+				require.NoError(t, err)
+				assert.Equal(t, http.StatusServiceUnavailable, code)
+				_, code, err = second.HttpGet(context.Background())
+				require.NoError(t, err)
+				assert.Equal(t, http.StatusServiceUnavailable, code)
+
+				t.Run("THEN retries stop once the budget is spent", func(t *testing.T) {
+					assert.Equal(t, 5, requests)
+				})
+			})
+		})
+	})
+}
@@ -0,0 +1,164 @@
+package httpretry
+
+import (
+	"container/list"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// CachedResponse is a GET response kept for revalidation with ETag and
+// Last-Modified validators.
+type CachedResponse struct {
+	Body       []byte
+	StatusCode int
+	Header     http.Header
+	StoredAt   time.Time
+}
+
+func (c *CachedResponse) etag() string {
+	return c.Header.Get("ETag")
+}
+
+func (c *CachedResponse) lastModified() string {
+	return c.Header.Get("Last-Modified")
+}
+
+// Cache stores GET responses keyed by URL.  Implementations must be safe for
+// concurrent use, plug in Redis or a disk cache by implementing it.
+type Cache interface {
+	Get(key string) (*CachedResponse, bool)
+	Set(key string, value *CachedResponse)
+	Delete(key string)
+}
+
+// memoryCache is an LRU cache with a time to live.
+type memoryCache struct {
+	mu         sync.Mutex
+	maxEntries int
+	ttl        time.Duration
+	clock      Clock
+	entries    map[string]*list.Element
+	lru        *list.List
+}
+
+type memoryCacheEntry struct {
+	key   string
+	value *CachedResponse
+}
+
+// NewMemoryCache keeps up to maxEntries responses for ttl each, evicting the
+// least recently used entry when full.  Zero values mean no limit.
+func NewMemoryCache(maxEntries int, ttl time.Duration) Cache {
+	return NewMemoryCacheWithClock(maxEntries, ttl, systemClock{})
+}
+
+// NewMemoryCacheWithClock is NewMemoryCache measuring the ttl with clock,
+// pass the Clock of the requests using the cache since it tells StoredAt.
+func NewMemoryCacheWithClock(maxEntries int, ttl time.Duration, clock Clock) Cache {
+	return &memoryCache{
+		maxEntries: maxEntries,
+		ttl:        ttl,
+		clock:      clock,
+		entries:    map[string]*list.Element{},
+		lru:        list.New(),
+	}
+}
+
+func (c *memoryCache) Get(key string) (*CachedResponse, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	element, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	entry := element.Value.(*memoryCacheEntry)
+	if c.ttl > 0 && c.clock.Now().Sub(entry.value.StoredAt) > c.ttl {
+		c.remove(element)
+		return nil, false
+	}
+	c.lru.MoveToFront(element)
+	return entry.value, true
+}
+
+func (c *memoryCache) Set(key string, value *CachedResponse) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if element, ok := c.entries[key]; ok {
+		element.Value.(*memoryCacheEntry).value = value
+		c.lru.MoveToFront(element)
+		return
+	}
+	c.entries[key] = c.lru.PushFront(&memoryCacheEntry{key: key, value: value})
+	if c.maxEntries > 0 && c.lru.Len() > c.maxEntries {
+		c.remove(c.lru.Back())
+	}
+}
+
+func (c *memoryCache) Delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if element, ok := c.entries[key]; ok {
+		c.remove(element)
+	}
+}
+
+func (c *memoryCache) remove(element *list.Element) {
+	c.lru.Remove(element)
+	delete(c.entries, element.Value.(*memoryCacheEntry).key)
+}
+
+// cacheKey returns the key of cacheable requests, only GET responses are
+// cached and never in stream mode.
+func (r httpRequest) cacheKey(req *http.Request, stream bool) string {
+	if r.Cache == nil || stream || req.Method != http.MethodGet {
+		return ""
+	}
+	return req.URL.String()
+}
+
+// setValidators makes the request conditional on the cached response.
+func setValidators(req *http.Request, cached *CachedResponse) {
+	if etag := cached.etag(); etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+	if lastModified := cached.lastModified(); lastModified != "" {
+		req.Header.Set("If-Modified-Since", lastModified)
+	}
+}
+
+// cachedHttpResponse turns a 304 into the cached response, headers of the 304
+// update the cached ones.
+func cachedHttpResponse(cached *CachedResponse, notModified *http.Response) *http.Response {
+	header := cached.Header.Clone()
+	for key, values := range notModified.Header {
+		header[key] = values
+	}
+	return &http.Response{
+		Status:        http.StatusText(cached.StatusCode),
+		StatusCode:    cached.StatusCode,
+		Proto:         notModified.Proto,
+		ProtoMajor:    notModified.ProtoMajor,
+		ProtoMinor:    notModified.ProtoMinor,
+		Header:        header,
+		Body:          http.NoBody,
+		ContentLength: int64(len(cached.Body)),
+		Request:       notModified.Request,
+	}
+}
+
+func isCacheable(resp *http.Response) bool {
+	return resp.StatusCode == http.StatusOK && (resp.Header.Get("ETag") != "" || resp.Header.Get("Last-Modified") != "")
+}
+
+func staleResponse(cached *CachedResponse, attempts int, duration time.Duration) *Response {
+	return &Response{
+		Body:       cached.Body,
+		StatusCode: cached.StatusCode,
+		Header:     cached.Header.Clone(),
+		Attempts:   attempts,
+		Duration:   duration,
+		FromCache:  true,
+		Stale:      true,
+	}
+}
@@ -0,0 +1,157 @@
+package httpretry
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/mandric/httpretry/httpretrytest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryCache(t *testing.T) {
+
+	t.Run("GIVEN a cache of 2 entries", func(t *testing.T) {
+		cache := NewMemoryCache(2, 0)
+		cache.Set("a", &CachedResponse{Body: []byte("a"), StoredAt: time.Now()})
+		cache.Set("b", &CachedResponse{Body: []byte("b"), StoredAt: time.Now()})
+
+		t.Run("WHEN a third entry is added after reading the first", func(t *testing.T) {
+			_, ok := cache.Get("a")
+			require.True(t, ok)
+			cache.Set("c", &CachedResponse{Body: []byte("c"), StoredAt: time.Now()})
+
+			t.Run("THEN the least recently used entry is evicted", func(t *testing.T) {
+				_, ok := cache.Get("b")
+				assert.False(t, ok)
+				_, ok = cache.Get("a")
+				assert.True(t, ok)
+				_, ok = cache.Get("c")
+				assert.True(t, ok)
+			})
+		})
+	})
+
+	t.Run("GIVEN a cache with a TTL", func(t *testing.T) {
+		cache := NewMemoryCache(0, time.Minute)
+		cache.Set("old", &CachedResponse{StoredAt: time.Now().Add(-time.Hour)})
+
+		t.Run("THEN expired entries are not returned", func(t *testing.T) {
+			_, ok := cache.Get("old")
+			assert.False(t, ok)
+		})
+	})
+
+	t.Run("GIVEN a cache with a TTL AND a fake clock", func(t *testing.T) {
+		clock := httpretrytest.NewFakeClock(time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC))
+		cache := NewMemoryCacheWithClock(0, time.Minute, clock)
+		cache.Set("entry", &CachedResponse{StoredAt: clock.Now()})
+
+		t.Run("THEN the entry is returned until the clock passes the TTL", func(t *testing.T) {
+			_, ok := cache.Get("entry")
+			assert.True(t, ok)
+			clock.Advance(2 * time.Minute)
+			_, ok = cache.Get("entry")
+			assert.False(t, ok)
+		})
+	})
+}
+
+func TestIntegration_Cache(t *testing.T) {
+
+	t.Run("GIVEN a server that supports ETags", func(t *testing.T) {
+		var ifNoneMatch []string
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ifNoneMatch = append(ifNoneMatch, r.Header.Get("If-None-Match"))
+			if r.Header.Get("If-None-Match") == `"v1"` {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			w.Header().Set("ETag", `"v1"`)
+			w.Write([]byte("resource"))
+		}))
+		defer ts.Close()
+
+		url, err := url.Parse(ts.URL)
+		require.NoError(t, err)
+
+		t.Run("AND http request with a cache", func(t *testing.T) {
+			api := NewHttpRequest(HttpRequestOptions{
+				URL:   url,
+				Cache: NewMemoryCache(10, time.Minute),
+				IsRetryCondition: func(resp *http.Response, retryCount int) bool {
+					return resp.StatusCode != http.StatusOK
+				},
+			})
+
+			t.Run("WHEN HttpGetFull is sent twice", func(t *testing.T) {
+				first, err := api.HttpGetFull(context.Background())
+				require.NoError(t, err)
+				second, err := api.HttpGetFull(context.Background())
+				require.NoError(t, err)
+
+				t.Run("THEN the second call revalidates AND returns the cached body", func(t *testing.T) {
+					assert.Equal(t, []string{"", `"v1"`}, ifNoneMatch)
+					assert.False(t, first.FromCache)
+					assert.True(t, second.FromCache)
+					assert.Equal(t, http.StatusOK, second.StatusCode)
+					assert.Equal(t, "resource", string(second.Body))
+					assert.Equal(t, 1, second.Attempts)
+				})
+			})
+		})
+	})
+}
+
+func TestIntegration_StaleOnError(t *testing.T) {
+
+	t.Run("GIVEN a server that fails after its first response", func(t *testing.T) {
+		calls := 0
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			if calls > 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.Header().Set("ETag", `"v1"`)
+			w.Write([]byte("resource"))
+		}))
+		defer ts.Close()
+
+		url, err := url.Parse(ts.URL)
+		require.NoError(t, err)
+
+		t.Run("AND http request with a cache AND StaleOnError", func(t *testing.T) {
+			api := NewHttpRequest(HttpRequestOptions{
+				URL:          url,
+				RetriesMax:   2,
+				RetriesWait:  time.Millisecond,
+				Cache:        NewMemoryCache(10, time.Minute),
+				StaleOnError: true,
+				IsRetryCondition: func(resp *http.Response, retryCount int) bool {
+					return resp.StatusCode >= http.StatusInternalServerError
+				},
+			})
+
+			t.Run("WHEN HttpGetFull is sent after the server starts failing", func(t *testing.T) {
+				_, err := api.HttpGetFull(context.Background())
+				require.NoError(t, err)
+				stale, err := api.HttpGetFull(context.Background())
+				require.NoError(t, err)
+
+				t.Run("THEN the cached body is returned AND marked stale", func(t *testing.T) {
+					assert.Equal(t, 3, calls)
+					assert.True(t, stale.Stale)
+					assert.True(t, stale.FromCache)
+					assert.Equal(t, http.StatusOK, stale.StatusCode)
+					assert.Equal(t, "resource", string(stale.Body))
+					assert.Equal(t, 2, stale.Attempts)
+				})
+			})
+		})
+	})
+}
@@ -0,0 +1,45 @@
+package httpretry
+
+import (
+	"net/http"
+	"net/http/httptrace"
+)
+
+// CallOption changes a single call without touching the configured request,
+// so one request object can be reused with call specific settings:
+//
+//	body, status, err := api.HttpGet(ctx, httpretry.WithHeader("If-None-Match", etag))
+type CallOption func(r *httpRequest)
+
+// WithHeader sets the header key to value for a single call, replacing the
+// configured value.
+func WithHeader(key string, value string) CallOption {
+	return func(r *httpRequest) {
+		r.Header = r.Header.Clone()
+		if r.Header == nil {
+			r.Header = http.Header{}
+		}
+		r.Header.Set(key, value)
+	}
+}
+
+// WithClientTrace attaches trace to every attempt of a single call, see
+// HttpRequestOptions.ClientTrace.
+func WithClientTrace(trace *httptrace.ClientTrace) CallOption {
+	return func(r *httpRequest) {
+		r.ClientTrace = trace
+	}
+}
+
+// forCall returns the request sending a call: the PolicyRouter and
+// MethodPolicies applied, then the call options.
+func (r httpRequest) forCall(method string, opts []CallOption) httpRequest {
+	return r.withEndpointPolicy().withMethodPolicy(method).withCallOptions(opts)
+}
+
+func (r httpRequest) withCallOptions(opts []CallOption) httpRequest {
+	for _, opt := range opts {
+		opt(&r)
+	}
+	return r
+}
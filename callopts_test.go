@@ -0,0 +1,99 @@
+package httpretry
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httptrace"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/mandric/httpretry/httpretrytest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntegration_WithHeader(t *testing.T) {
+
+	t.Run("GIVEN a server recording the If-Match header", func(t *testing.T) {
+		var ifMatch []string
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ifMatch = append(ifMatch, r.Header.Get("If-Match"))
+		}))
+		defer ts.Close()
+
+		url, err := url.Parse(ts.URL)
+		require.NoError(t, err)
+		api := NewHttpRequest(HttpRequestOptions{URL: url, Logger: NopLogger{}})
+
+		t.Run("WHEN calls are sent with and without WithHeader", func(t *testing.T) {
+			_, _, err := api.HttpPut(context.Background(), []byte(`{}`), WithHeader("If-Match", `"v1"`))
+			require.NoError(t, err)
+			_, err = api.HttpGetFull(context.Background())
+			require.NoError(t, err)
+
+			t.Run("THEN only the first call has the header", func(t *testing.T) {
+				assert.Equal(t, []string{`"v1"`, ""}, ifMatch)
+			})
+
+			t.Run("AND the configured header is unchanged", func(t *testing.T) {
+				assert.Empty(t, api.Header.Get("If-Match"))
+			})
+		})
+
+		t.Run("WHEN WithHeader overrides the JSON content type", func(t *testing.T) {
+			var contentType string
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				contentType = r.Header.Get("Content-Type")
+			}))
+			defer ts.Close()
+
+			url, err := url.Parse(ts.URL)
+			require.NoError(t, err)
+			api := NewHttpRequest(HttpRequestOptions{URL: url, Logger: NopLogger{}})
+			_, _, err = api.PostJSON(context.Background(), map[string]string{"a": "b"}, nil, WithHeader("Content-Type", "application/merge-patch+json"))
+			require.NoError(t, err)
+
+			t.Run("THEN the per-call value wins", func(t *testing.T) {
+				assert.Equal(t, "application/merge-patch+json", contentType)
+			})
+		})
+	})
+}
+
+func TestIntegration_WithClientTrace(t *testing.T) {
+
+	t.Run("GIVEN a server failing twice", func(t *testing.T) {
+		ts := httpretrytest.NewFlakyServer(2, http.StatusServiceUnavailable)
+		defer ts.Close()
+
+		url, err := url.Parse(ts.URL)
+		require.NoError(t, err)
+		api := NewHttpRequest(HttpRequestOptions{
+			URL:              url,
+			RetriesWait:      time.Millisecond,
+			IsRetryCondition: RetryOnStatus(http.StatusServiceUnavailable),
+		})
+
+		t.Run("WHEN HttpGetFull is sent WithClientTrace", func(t *testing.T) {
+			var gotConns, wrote int
+			resp, err := api.HttpGetFull(context.Background(), WithClientTrace(&httptrace.ClientTrace{
+				GotConn:      func(httptrace.GotConnInfo) { gotConns++ },
+				WroteRequest: func(httptrace.WroteRequestInfo) { wrote++ },
+			}))
+			require.NoError(t, err)
+
+			t.Run("THEN the trace sees every attempt", func(t *testing.T) {
+				assert.Equal(t, 3, resp.Attempts)
+				assert.Equal(t, 3, gotConns)
+				assert.Equal(t, 3, wrote)
+			})
+
+			t.Run("AND the connection stats are still recorded", func(t *testing.T) {
+				assert.True(t, resp.History[2].Conn.Reused)
+			})
+		})
+	})
+}
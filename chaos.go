@@ -0,0 +1,110 @@
+package httpretry
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"syscall"
+	"time"
+)
+
+// FaultInjectedHeader is set on the responses made up by a fault injector.
+const FaultInjectedHeader = "X-Fault-Injected"
+
+type FaultInjectorOptions struct {
+	// Transport is the wrapped round tripper
+	// defaults to http.DefaultTransport
+	Transport http.RoundTripper
+
+	// LatencyRate is the share of requests, between 0 and 1, delayed by a
+	// random duration up to Latency before being sent.
+	LatencyRate float64
+	Latency     time.Duration
+
+	// DropRate is the share of requests failing with a connection reset
+	// without being sent, see IsConnectionReset.
+	DropRate float64
+
+	// ErrorRate is the share of requests answered with ErrorStatus without
+	// being sent.
+	ErrorRate float64
+
+	// ErrorStatus of the injected responses
+	// defaults to 503
+	ErrorStatus int
+
+	// Rand draws the faults, NewRand with a fixed seed injects the same
+	// faults on every run.
+	// defaults to the package Rand, see SetJitterSeed
+	Rand *Rand
+}
+
+type faultInjector struct {
+	options FaultInjectorOptions
+}
+
+// NewFaultInjector wraps a round tripper with random latency, dropped
+// connections and error responses so retry predicates, budgets and breakers
+// can be tested against a chaotic upstream:
+//
+//	api := httpretry.NewHttpRequest(httpretry.HttpRequestOptions{
+//		URL: url,
+//		Transport: httpretry.NewFaultInjector(httpretry.FaultInjectorOptions{
+//			DropRate:  0.1,
+//			ErrorRate: 0.2,
+//		}),
+//	})
+//
+// Injected faults never reach the upstream.  Don't use it in production.
+func NewFaultInjector(options FaultInjectorOptions) http.RoundTripper {
+	if options.Transport == nil {
+		options.Transport = http.DefaultTransport
+	}
+	if options.ErrorStatus == 0 {
+		options.ErrorStatus = http.StatusServiceUnavailable
+	}
+	return &faultInjector{options: options}
+}
+
+func (f *faultInjector) RoundTrip(req *http.Request) (*http.Response, error) {
+	rand := orDefaultRand(f.options.Rand)
+	if f.options.Latency > 0 && rand.Float64() < f.options.LatencyRate {
+		delay := time.Duration(rand.Int63n(int64(f.options.Latency))) + 1
+		if err := sleepContext(req.Context(), delay); err != nil {
+			closeRequestBody(req)
+			return nil, err
+		}
+	}
+	if rand.Float64() < f.options.DropRate {
+		closeRequestBody(req)
+		return nil, connectionResetError()
+	}
+	if rand.Float64() < f.options.ErrorRate {
+		closeRequestBody(req)
+		return &http.Response{
+			Status:     fmt.Sprintf("%d %s", f.options.ErrorStatus, http.StatusText(f.options.ErrorStatus)),
+			StatusCode: f.options.ErrorStatus,
+			Proto:      "HTTP/1.1",
+			ProtoMajor: 1,
+			ProtoMinor: 1,
+			Header:     http.Header{FaultInjectedHeader: {"true"}},
+			Body:       http.NoBody,
+			Request:    req,
+		}, nil
+	}
+	return f.options.Transport.RoundTrip(req)
+}
+
+// closeRequestBody closes the body of a request that isn't sent, as the
+// RoundTripper contract requires.
+func closeRequestBody(req *http.Request) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+}
+
+// connectionResetError is the error of a connection reset by the peer.
+func connectionResetError() error {
+	return &net.OpError{Op: "read", Net: "tcp", Err: os.NewSyscallError("read", syscall.ECONNRESET)}
+}
@@ -0,0 +1,65 @@
+package httpretry
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/mandric/httpretry/httpretrytest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntegration_FaultInjector(t *testing.T) {
+
+	t.Run("GIVEN a healthy server", func(t *testing.T) {
+		ts := httpretrytest.NewFlakyServer(0, http.StatusServiceUnavailable)
+		defer ts.Close()
+
+		url, err := url.Parse(ts.URL)
+		require.NoError(t, err)
+		send := func(options FaultInjectorOptions) (*Response, error) {
+			return NewHttpRequest(HttpRequestOptions{
+				URL:        url,
+				RetriesMax: 3,
+				Clock:      httpretrytest.NewFakeClock(time.Now()),
+				Transport:  NewFaultInjector(options),
+			}).HttpGetFull(context.Background())
+		}
+
+		t.Run("WHEN every request is answered with an injected error", func(t *testing.T) {
+			resp, err := send(FaultInjectorOptions{ErrorRate: 1})
+			require.NoError(t, err)
+
+			t.Run("THEN the injected 503 is retried without reaching the server", func(t *testing.T) {
+				assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+				assert.Equal(t, "true", resp.Header.Get(FaultInjectedHeader))
+				assert.Equal(t, 3, resp.Attempts)
+				assert.Equal(t, 0, ts.Requests())
+			})
+		})
+
+		t.Run("WHEN every connection is dropped", func(t *testing.T) {
+			resp, err := send(FaultInjectorOptions{DropRate: 1})
+
+			t.Run("THEN the connection reset is retried", func(t *testing.T) {
+				require.Error(t, err)
+				assert.True(t, IsConnectionReset(err))
+				assert.Equal(t, 3, resp.Attempts)
+				assert.Equal(t, 0, ts.Requests())
+			})
+		})
+
+		t.Run("WHEN every request is delayed", func(t *testing.T) {
+			resp, err := send(FaultInjectorOptions{LatencyRate: 1, Latency: time.Millisecond})
+			require.NoError(t, err)
+
+			t.Run("THEN it reaches the server", func(t *testing.T) {
+				assert.Equal(t, http.StatusOK, resp.StatusCode)
+				assert.Equal(t, 1, ts.Requests())
+			})
+		})
+	})
+}
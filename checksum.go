@@ -0,0 +1,88 @@
+package httpretry
+
+import (
+	"bytes"
+	"crypto/md5"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// ChecksumError is returned when a response body doesn't match its expected
+// checksum, for example after corruption on a flaky network.  It is wrapped
+// in a *url.Error like the errors of http.Client so DefaultRetryPolicy
+// retries it for idempotent methods.
+type ChecksumError struct {
+	// Algorithm is "sha-256", "sha-512" or "md5"
+	Algorithm string
+	Expected  string
+	Actual    string
+}
+
+func (e *ChecksumError) Error() string {
+	return fmt.Sprintf("%s checksum mismatch: expected %s, got %s", e.Algorithm, e.Expected, e.Actual)
+}
+
+// verifyChecksum checks body against ExpectedSHA256 and, with VerifyDigest,
+// against the Digest and Content-MD5 headers of resp.
+func (r httpRequest) verifyChecksum(req *http.Request, resp *http.Response, body []byte) error {
+	var err error
+	if r.ExpectedSHA256 != "" {
+		sum := sha256.Sum256(body)
+		if actual := hex.EncodeToString(sum[:]); !strings.EqualFold(actual, r.ExpectedSHA256) {
+			err = &ChecksumError{Algorithm: "sha-256", Expected: r.ExpectedSHA256, Actual: actual}
+		}
+	}
+	if err == nil && r.VerifyDigest {
+		err = verifyDigestHeaders(resp.Header, body)
+	}
+	if err != nil {
+		return &url.Error{Op: urlErrorOp(req.Method), URL: req.URL.String(), Err: err}
+	}
+	return nil
+}
+
+// verifyDigestHeaders checks the RFC 3230 Digest header, using the first
+// supported algorithm, or else the Content-MD5 header.  Responses without
+// either header pass.
+func verifyDigestHeaders(header http.Header, body []byte) error {
+	for _, digest := range strings.Split(header.Get("Digest"), ",") {
+		algorithm, expected, ok := strings.Cut(strings.TrimSpace(digest), "=")
+		if !ok {
+			continue
+		}
+		algorithm = strings.ToLower(algorithm)
+		var h hash.Hash
+		switch algorithm {
+		case "sha-256":
+			h = sha256.New()
+		case "sha-512":
+			h = sha512.New()
+		case "md5":
+			h = md5.New()
+		default:
+			continue
+		}
+		return compareDigest(algorithm, h, body, expected)
+	}
+	if expected := header.Get("Content-MD5"); expected != "" {
+		return compareDigest("md5", md5.New(), body, expected)
+	}
+	return nil
+}
+
+func compareDigest(algorithm string, h hash.Hash, body []byte, expected string) error {
+	h.Write(body)
+	sum := h.Sum(nil)
+	decoded, err := base64.StdEncoding.DecodeString(expected)
+	if err != nil || !bytes.Equal(decoded, sum) {
+		return &ChecksumError{Algorithm: algorithm, Expected: expected, Actual: base64.StdEncoding.EncodeToString(sum)}
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package httpretry
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntegration_Checksum(t *testing.T) {
+	content := []byte("the complete download")
+	sha := sha256.Sum256(content)
+	md := md5.Sum(content)
+
+	t.Run("GIVEN a server corrupting the first transfer", func(t *testing.T) {
+		calls := 0
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			w.Header().Set("Digest", "SHA-256="+base64.StdEncoding.EncodeToString(sha[:]))
+			if calls == 1 {
+				w.Write([]byte("the c0mplete download"))
+				return
+			}
+			w.Write(content)
+		}))
+		defer ts.Close()
+
+		u, err := url.Parse(ts.URL)
+		require.NoError(t, err)
+
+		for name, options := range map[string]HttpRequestOptions{
+			"ExpectedSHA256": {ExpectedSHA256: hex.EncodeToString(sha[:])},
+			"VerifyDigest":   {VerifyDigest: true},
+		} {
+			t.Run("WHEN HttpGet is sent with "+name, func(t *testing.T) {
+				calls = 0
+				options.URL = u
+				options.RetriesWait = time.Millisecond
+				options.UseDefaultPolicy = true
+				body, _, err := NewHttpRequest(options).HttpGet(context.Background())
+				require.NoError(t, err)
+
+				t.Run("THEN the corrupted attempt is retried", func(t *testing.T) {
+					assert.Equal(t, 2, calls)
+					assert.Equal(t, content, body)
+				})
+			})
+		}
+	})
+
+	t.Run("GIVEN a server always sending a wrong Content-MD5", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-MD5", base64.StdEncoding.EncodeToString(md[:]))
+			w.Write([]byte("something else"))
+		}))
+		defer ts.Close()
+
+		u, err := url.Parse(ts.URL)
+		require.NoError(t, err)
+
+		t.Run("WHEN HttpGet is sent with VerifyDigest", func(t *testing.T) {
+			api := NewHttpRequest(HttpRequestOptions{URL: u, VerifyDigest: true, RetriesMax: 2, RetriesWait: time.Millisecond, Logger: NopLogger{}})
+			_, _, err := api.HttpGet(context.Background())
+
+			t.Run("THEN it fails with a ChecksumError", func(t *testing.T) {
+				var checksumErr *ChecksumError
+				require.True(t, errors.As(err, &checksumErr))
+				assert.Equal(t, "md5", checksumErr.Algorithm)
+			})
+		})
+	})
+}
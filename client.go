@@ -0,0 +1,203 @@
+package httpretry
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// ErrClientInitialized is returned by SetDefaultClientOptions once the
+// singleton client has been created.
+var ErrClientInitialized = errors.New("singleton http client already initialized")
+
+var (
+	httpClient     *http.Client
+	httpClientOnce sync.Once
+
+	clientOptionsMu      sync.Mutex
+	clientOptions        ClientOptions
+	clientOptionsApplied bool
+)
+
+// ClientOptions tune the singleton client, zero values keep the net/http
+// defaults.
+type ClientOptions struct {
+	// Timeout limits the time of a single attempt including reading the body.
+	Timeout time.Duration
+
+	DialTimeout           time.Duration
+	TLSHandshakeTimeout   time.Duration
+	ResponseHeaderTimeout time.Duration
+	IdleConnTimeout       time.Duration
+	MaxIdleConnsPerHost   int
+
+	// MaxIdleConns limits the idle connections kept across all hosts
+	// defaults to 100
+	MaxIdleConns int
+
+	// MaxConnsPerHost limits the connections to a host, dialing, active and
+	// idle, so a burst waits for a connection instead of exhausting the
+	// file handles of a lambda
+	// defaults to no limit
+	MaxConnsPerHost int
+
+	// DisableHTTP2 sends HTTP/1.1 only.  The dedicated transport otherwise
+	// keeps ForceAttemptHTTP2 of http.DefaultTransport, so HTTP/2 is
+	// negotiated even with a custom dialer or TLS config.
+	DisableHTTP2 bool
+
+	Proxy           func(*http.Request) (*url.URL, error)
+	TLSClientConfig *tls.Config
+
+	// Jar keeps cookies across the requests sent by the client, see
+	// net/http/cookiejar.
+	Jar http.CookieJar
+}
+
+func (o ClientOptions) configuresTransport() bool {
+	return o.DialTimeout != 0 || o.TLSHandshakeTimeout != 0 || o.ResponseHeaderTimeout != 0 ||
+		o.IdleConnTimeout != 0 || o.MaxIdleConnsPerHost != 0 || o.MaxIdleConns != 0 || o.MaxConnsPerHost != 0 ||
+		o.DisableHTTP2 || o.Proxy != nil || o.TLSClientConfig != nil
+}
+
+// SetDefaultClientOptions configures the singleton client.  It must be called
+// before the first request, typically from main or init, and returns
+// ErrClientInitialized afterwards.
+func SetDefaultClientOptions(options ClientOptions) error {
+	clientOptionsMu.Lock()
+	defer clientOptionsMu.Unlock()
+	if clientOptionsApplied {
+		return ErrClientInitialized
+	}
+	clientOptions = options
+	return nil
+}
+
+// GetSingletonHttpClient returns the client shared by every httpRequest
+// without a Client option.  It is safe for concurrent use.
+func GetSingletonHttpClient() *http.Client {
+	httpClientOnce.Do(func() {
+		clientOptionsMu.Lock()
+		defer clientOptionsMu.Unlock()
+		clientOptionsApplied = true
+		httpClient = NewHttpClient(clientOptions)
+	})
+	return httpClient
+}
+
+// NewHttpClient builds a client from options.  Without transport options the
+// client uses http.DefaultTransport like a zero http.Client does.
+func NewHttpClient(options ClientOptions) *http.Client {
+	client := &http.Client{Timeout: options.Timeout, Jar: options.Jar}
+	if !options.configuresTransport() {
+		return client
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if options.DialTimeout != 0 {
+		transport.DialContext = (&net.Dialer{
+			Timeout:   options.DialTimeout,
+			KeepAlive: 30 * time.Second,
+		}).DialContext
+	}
+	if options.TLSHandshakeTimeout != 0 {
+		transport.TLSHandshakeTimeout = options.TLSHandshakeTimeout
+	}
+	if options.ResponseHeaderTimeout != 0 {
+		transport.ResponseHeaderTimeout = options.ResponseHeaderTimeout
+	}
+	if options.IdleConnTimeout != 0 {
+		transport.IdleConnTimeout = options.IdleConnTimeout
+	}
+	if options.MaxIdleConnsPerHost != 0 {
+		transport.MaxIdleConnsPerHost = options.MaxIdleConnsPerHost
+	}
+	if options.MaxIdleConns != 0 {
+		transport.MaxIdleConns = options.MaxIdleConns
+	}
+	if options.MaxConnsPerHost != 0 {
+		transport.MaxConnsPerHost = options.MaxConnsPerHost
+	}
+	if options.Proxy != nil {
+		transport.Proxy = options.Proxy
+	}
+	if options.TLSClientConfig != nil {
+		transport.TLSClientConfig = options.TLSClientConfig
+	}
+	if options.DisableHTTP2 {
+		// a non-nil empty map turns off the automatic HTTP/2 upgrade
+		transport.ForceAttemptHTTP2 = false
+		transport.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+	}
+	client.Transport = transport
+	return client
+}
+
+func (r httpRequest) httpClient() (*http.Client, error) {
+	client := r.Client
+	switch {
+	case client != nil:
+	case r.ClientName != "":
+		var err error
+		if client, err = r.ClientRegistry.Client(r.ClientName); err != nil {
+			return nil, err
+		}
+	default:
+		client = GetSingletonHttpClient()
+	}
+	if r.Redirect == nil && r.Jar == nil && r.HostPolicy == nil && !r.DryRun {
+		return client, nil
+	}
+	// a shallow copy shares the transport and its connection pool
+	dedicated := *client
+	if r.Redirect != nil {
+		dedicated.CheckRedirect = r.Redirect.checkRedirect
+	}
+	if r.HostPolicy != nil {
+		checkRedirect := dedicated.CheckRedirect
+		if checkRedirect == nil {
+			// a zero policy behaves like the net/http default
+			checkRedirect = (&RedirectPolicy{}).checkRedirect
+		}
+		dedicated.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+			if err := r.HostPolicy.checkHost(req.URL.Hostname()); err != nil {
+				return err
+			}
+			return checkRedirect(req, via)
+		}
+	}
+	if r.Jar != nil {
+		dedicated.Jar = r.Jar
+	}
+	if r.DryRun {
+		dedicated.Transport = dryRunTransport{r: r}
+	}
+	return &dedicated, nil
+}
+
+// clientWithTransport returns a copy of client sending requests through a
+// dedicated clone of its transport changed by configure.  A transport that
+// isn't an *http.Transport, for example a tracing wrapper, can't be cloned and
+// is replaced by a clone of http.DefaultTransport, which is logged.
+func clientWithTransport(client *http.Client, logger Logger, configure func(*http.Transport)) *http.Client {
+	dedicated := http.Client{}
+	if client != nil {
+		dedicated = *client
+	}
+	transport, ok := dedicated.Transport.(*http.Transport)
+	if !ok {
+		if dedicated.Transport != nil {
+			logWithFields(logger, LogLevelWarn, "Client transport can't be configured, replacing it with a dedicated transport", Fields{"transport": fmt.Sprintf("%T", dedicated.Transport)})
+		}
+		transport = http.DefaultTransport.(*http.Transport)
+	}
+	transport = transport.Clone()
+	configure(transport)
+	dedicated.Transport = transport
+	return &dedicated
+}
@@ -0,0 +1,212 @@
+package httpretry
+
+import (
+	"context"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSingletonHttpClient(t *testing.T) {
+
+	t.Run("GIVEN concurrent callers", func(t *testing.T) {
+		clients := make([]*http.Client, 10)
+		var wg sync.WaitGroup
+		for i := range clients {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				clients[i] = GetSingletonHttpClient()
+			}(i)
+		}
+		wg.Wait()
+
+		t.Run("THEN they all get the same client", func(t *testing.T) {
+			for _, client := range clients {
+				assert.Same(t, clients[0], client)
+			}
+		})
+
+		t.Run("THEN default options can no longer be changed", func(t *testing.T) {
+			err := SetDefaultClientOptions(ClientOptions{Timeout: time.Second})
+			assert.ErrorIs(t, err, ErrClientInitialized)
+		})
+	})
+}
+
+func TestNewHttpClient(t *testing.T) {
+
+	t.Run("GIVEN no transport options", func(t *testing.T) {
+		client := NewHttpClient(ClientOptions{Timeout: time.Second})
+
+		t.Run("THEN the default transport is used", func(t *testing.T) {
+			assert.Nil(t, client.Transport)
+			assert.Equal(t, time.Second, client.Timeout)
+		})
+	})
+
+	t.Run("GIVEN transport options", func(t *testing.T) {
+		client := NewHttpClient(ClientOptions{
+			MaxIdleConnsPerHost:   50,
+			ResponseHeaderTimeout: 5 * time.Second,
+		})
+
+		t.Run("THEN they are applied to a dedicated transport", func(t *testing.T) {
+			transport, ok := client.Transport.(*http.Transport)
+			require.True(t, ok)
+			assert.NotSame(t, http.DefaultTransport, transport)
+			assert.Equal(t, 50, transport.MaxIdleConnsPerHost)
+			assert.Equal(t, 5*time.Second, transport.ResponseHeaderTimeout)
+			assert.True(t, transport.ForceAttemptHTTP2)
+		})
+	})
+
+	t.Run("GIVEN connection limits AND DisableHTTP2", func(t *testing.T) {
+		client := NewHttpClient(ClientOptions{
+			MaxIdleConns:    10,
+			MaxConnsPerHost: 4,
+			DisableHTTP2:    true,
+		})
+
+		t.Run("THEN the transport is limited to HTTP/1.1", func(t *testing.T) {
+			transport, ok := client.Transport.(*http.Transport)
+			require.True(t, ok)
+			assert.Equal(t, 10, transport.MaxIdleConns)
+			assert.Equal(t, 4, transport.MaxConnsPerHost)
+			assert.False(t, transport.ForceAttemptHTTP2)
+			assert.NotNil(t, transport.TLSNextProto)
+			assert.Empty(t, transport.TLSNextProto)
+		})
+	})
+}
+
+func TestIntegration_CustomClient(t *testing.T) {
+
+	t.Run("GIVEN a slow server", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			time.Sleep(200 * time.Millisecond)
+		}))
+		defer ts.Close()
+
+		url, err := url.Parse(ts.URL)
+		require.NoError(t, err)
+
+		t.Run("AND http request with its own client timing out quickly", func(t *testing.T) {
+			api := NewHttpRequest(HttpRequestOptions{
+				URL:        url,
+				RetriesMax: 1,
+				Client:     &http.Client{Timeout: 10 * time.Millisecond},
+			})
+
+			t.Run("WHEN HttpGet is sent", func(t *testing.T) {
+				_, _, err := api.HttpGet(context.Background())
+
+				t.Run("THEN the client timeout applies", func(t *testing.T) {
+					assert.True(t, IsTimeoutError(err))
+				})
+			})
+		})
+	})
+}
+
+func TestIntegration_CookieJar(t *testing.T) {
+
+	t.Run("GIVEN a server starting a session on a failing first attempt", func(t *testing.T) {
+		var mu sync.Mutex
+		var sessions []string
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			defer mu.Unlock()
+			cookie, err := r.Cookie("session")
+			if err != nil {
+				sessions = append(sessions, "")
+				http.SetCookie(w, &http.Cookie{Name: "session", Value: "s1"})
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			sessions = append(sessions, cookie.Value)
+		}))
+		defer ts.Close()
+
+		url, err := url.Parse(ts.URL)
+		require.NoError(t, err)
+
+		t.Run("WHEN requests with a Jar are sent", func(t *testing.T) {
+			jar, err := cookiejar.New(nil)
+			require.NoError(t, err)
+			api := NewHttpRequest(HttpRequestOptions{
+				URL:              url,
+				RetriesMax:       2,
+				IsRetryCondition: RetryOnStatus(http.StatusServiceUnavailable),
+				Jar:              jar,
+			})
+			_, status, err := api.HttpGet(context.Background())
+			require.NoError(t, err)
+			_, _, err = api.HttpGet(context.Background())
+			require.NoError(t, err)
+
+			t.Run("THEN the retry and the next call send the session cookie", func(t *testing.T) {
+				assert.Equal(t, http.StatusOK, status)
+				assert.Equal(t, []string{"", "s1", "s1"}, sessions)
+			})
+
+			t.Run("AND the singleton client has no jar", func(t *testing.T) {
+				assert.Nil(t, GetSingletonHttpClient().Jar)
+			})
+		})
+	})
+}
+
+type roundTripperFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestIntegration_CustomTransport(t *testing.T) {
+
+	t.Run("GIVEN a server", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		defer ts.Close()
+
+		url, err := url.Parse(ts.URL)
+		require.NoError(t, err)
+
+		t.Run("AND http request with a transport AND a client", func(t *testing.T) {
+			var hosts []string
+			client := &http.Client{Timeout: time.Second}
+			api := NewHttpRequest(HttpRequestOptions{
+				URL:    url,
+				Client: client,
+				Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+					hosts = append(hosts, req.URL.Host)
+					return http.DefaultTransport.RoundTrip(req)
+				}),
+			})
+
+			t.Run("WHEN HttpGet is sent", func(t *testing.T) {
+				_, code, err := api.HttpGet(context.Background())
+				require.NoError(t, err)
+
+				t.Run("THEN the request goes through the transport", func(t *testing.T) {
+					assert.Equal(t, http.StatusNoContent, code)
+					assert.Equal(t, []string{url.Host}, hosts)
+				})
+
+				t.Run("THEN the caller's client is left untouched", func(t *testing.T) {
+					assert.Nil(t, client.Transport)
+					assert.Equal(t, time.Second, api.Client.Timeout)
+				})
+			})
+		})
+	})
+}
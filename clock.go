@@ -0,0 +1,33 @@
+package httpretry
+
+import (
+	"context"
+	"time"
+)
+
+// Clock tells the time and waits for the retry loop and the helpers built on
+// it.  Tests of long backoff schedules can use a fake clock, like
+// httpretrytest.FakeClock, to run instantly.
+type Clock interface {
+	Now() time.Time
+
+	// Sleep waits for d or until ctx is done, returning ctx.Err() then.
+	Sleep(ctx context.Context, d time.Duration) error
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (systemClock) Sleep(ctx context.Context, d time.Duration) error {
+	return sleepContext(ctx, d)
+}
+
+func (r httpRequest) clock() Clock {
+	if r.Clock != nil {
+		return r.Clock
+	}
+	return systemClock{}
+}
@@ -0,0 +1,117 @@
+package httpretry
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/mandric/httpretry/httpretrytest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntegration_Clock(t *testing.T) {
+
+	t.Run("GIVEN a server failing 3 times AND an hourly backoff", func(t *testing.T) {
+		ts := httpretrytest.NewFlakyServer(3, http.StatusServiceUnavailable)
+		defer ts.Close()
+
+		url, err := url.Parse(ts.URL)
+		require.NoError(t, err)
+		clock := httpretrytest.NewFakeClock(time.Now())
+		api := NewHttpRequest(HttpRequestOptions{
+			URL:              url,
+			Backoff:          ExponentialBackoff{Base: time.Hour, Max: 4 * time.Hour},
+			IsRetryCondition: RetryOnStatus(http.StatusServiceUnavailable),
+			MaxElapsedTime:   5 * time.Hour,
+			Clock:            clock,
+		})
+
+		t.Run("WHEN HttpGetFull is sent with a fake clock", func(t *testing.T) {
+			started := time.Now()
+			resp, err := api.HttpGetFull(context.Background())
+			require.NoError(t, err)
+
+			t.Run("THEN the schedule runs instantly", func(t *testing.T) {
+				assert.Less(t, time.Since(started), time.Second)
+				assert.Equal(t, []time.Duration{time.Hour, 2 * time.Hour}, clock.Sleeps())
+			})
+
+			t.Run("AND MaxElapsedTime is measured on the clock", func(t *testing.T) {
+				assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+				assert.Equal(t, 3, resp.Attempts)
+				assert.GreaterOrEqual(t, resp.Duration, 3*time.Hour)
+			})
+		})
+	})
+}
+
+func TestIntegration_ClockRetryAfter(t *testing.T) {
+
+	t.Run("GIVEN a fake clock in the past AND a server asking to retry two hours later", func(t *testing.T) {
+		clock := httpretrytest.NewFakeClock(time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC))
+		retryAt := clock.Now().Add(2 * time.Hour).Format(http.TimeFormat)
+		attempts := 1
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if attempts > 0 {
+				attempts--
+				w.Header().Set("Retry-After", retryAt)
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer ts.Close()
+
+		url, err := url.Parse(ts.URL)
+		require.NoError(t, err)
+		api := NewHttpRequest(HttpRequestOptions{
+			URL:               url,
+			RespectRetryAfter: true,
+			RetryAfterMax:     3 * time.Hour,
+			IsRetryCondition:  RetryOnStatus(http.StatusServiceUnavailable),
+			Clock:             clock,
+		})
+
+		t.Run("WHEN HttpGet is sent", func(t *testing.T) {
+			_, status, err := api.HttpGet(context.Background())
+			require.NoError(t, err)
+
+			t.Run("THEN the HTTP-date is measured on the clock", func(t *testing.T) {
+				assert.Equal(t, http.StatusOK, status)
+				assert.Equal(t, []time.Duration{2 * time.Hour}, clock.Sleeps())
+			})
+		})
+	})
+}
+
+func TestIntegration_ClockContextDeadline(t *testing.T) {
+
+	t.Run("GIVEN a server failing once AND a fake clock an hour ahead", func(t *testing.T) {
+		ts := httpretrytest.NewFlakyServer(1, http.StatusServiceUnavailable)
+		defer ts.Close()
+
+		url, err := url.Parse(ts.URL)
+		require.NoError(t, err)
+		api := NewHttpRequest(HttpRequestOptions{
+			URL:              url,
+			RetriesWait:      time.Millisecond,
+			IsRetryCondition: RetryOnStatus(http.StatusServiceUnavailable),
+			Clock:            httpretrytest.NewFakeClock(time.Now().Add(time.Hour)),
+		})
+
+		t.Run("WHEN HttpGet is sent with a context deadline", func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+			_, status, err := api.HttpGet(ctx)
+
+			t.Run("THEN the deadline is measured on the wall clock", func(t *testing.T) {
+				require.NoError(t, err)
+				assert.Equal(t, http.StatusOK, status)
+			})
+		})
+	})
+}
@@ -0,0 +1,180 @@
+package httpretry
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// Clone returns a copy of the request that shares nothing mutable with it,
+// its URL, Header and option slices can be changed without affecting r.
+// Collaborators like the client, limiters, breakers and the token refreshed
+// by ReauthFunc stay shared.
+func (r httpRequest) Clone() httpRequest {
+	if r.URL != nil {
+		u := *r.URL
+		if u.User != nil {
+			user := *u.User
+			u.User = &user
+		}
+		r.URL = &u
+	}
+	r.Header = r.Header.Clone()
+	r.DebugContentTypes = append([]string(nil), r.DebugContentTypes...)
+	r.Middleware = append([]Middleware(nil), r.Middleware...)
+	r.FallbackURLs = append([]*url.URL(nil), r.FallbackURLs...)
+	r.FallbackStatusCodes = append([]int(nil), r.FallbackStatusCodes...)
+	r.GraphQLRetryCodes = append([]string(nil), r.GraphQLRetryCodes...)
+	if r.MethodPolicies != nil {
+		policies := make(map[string]MethodPolicy, len(r.MethodPolicies))
+		for method, policy := range r.MethodPolicies {
+			policies[method] = policy
+		}
+		r.MethodPolicies = policies
+	}
+	return r
+}
+
+// With returns a variant of the request with opts applied on top of its
+// configuration, so an SDK can build its endpoints from one base request:
+//
+//	base := httpretry.NewRequest(u, httpretry.WithToken(token), httpretry.WithRetries(5))
+//	users := base.With(httpretry.WithPath("users"))
+//	upload := base.With(httpretry.WithPath("files"), httpretry.WithRetryCondition(retryUpload))
+//
+// The variant is built by NewHttpRequest like any other request, r is left
+// untouched.
+func (r httpRequest) With(opts ...Option) httpRequest {
+	options := r.Clone().options()
+	// the client already pins addresses and checks the policy, don't wrap
+	// its transport again
+	rotate, policy := options.RotateAddresses, options.HostPolicy
+	options.RotateAddresses, options.HostPolicy = false, nil
+	for _, opt := range opts {
+		opt(&options)
+	}
+	variant := NewHttpRequest(options)
+	// a token refreshed by ReauthFunc stays valid for the variant
+	variant.reauthToken = r.reauthToken
+	variant.RotateAddresses = variant.RotateAddresses || rotate
+	if variant.HostPolicy == nil {
+		variant.HostPolicy = policy
+	}
+	return variant
+}
+
+// options returns the HttpRequestOptions building r, the Authorization header
+// derived from Token is dropped so a new token replaces it.  Backoff and
+// RetriesWait are only returned when the caller set them, so options changing
+// the wait derive a new Backoff.
+func (r httpRequest) options() HttpRequestOptions {
+	header := r.Header
+	if r.TokenSource == nil && header.Get("Authorization") == fmt.Sprintf("Bearer %s", r.Token) {
+		header = header.Clone()
+		header.Del("Authorization")
+	}
+	backoff, wait := r.Backoff, r.RetriesWait
+	if !r.customBackoff {
+		backoff = nil
+	}
+	if !r.customRetriesWait {
+		wait = 0
+	}
+	return HttpRequestOptions{
+		URL:              r.URL,
+		Token:            r.Token,
+		Header:           header,
+		DefaultHeaders:   http.Header{},
+		RetriesMax:       r.RetriesMax,
+		RetriesWait:      wait,
+		Backoff:          backoff,
+		IsRetryCondition: r.IsRetryCondition,
+		IsRetryError:     r.IsRetryError,
+		StatusClassifier: r.StatusClassifier,
+		MethodPolicies:   r.MethodPolicies,
+		PolicyRouter:     r.PolicyRouter,
+
+		RetriesWaitMax:    r.RetriesWaitMax,
+		BackoffMultiplier: r.BackoffMultiplier,
+
+		RespectRetryAfter: r.RespectRetryAfter,
+		RetryAfterMax:     r.RetryAfterMax,
+
+		RetryBudget:    r.RetryBudget,
+		CircuitBreaker: r.CircuitBreaker,
+		Metrics:        r.Metrics,
+		Tracer:         r.Tracer,
+		ClientTrace:    r.ClientTrace,
+
+		InjectRequestID: r.InjectRequestID,
+
+		Client:         r.Client,
+		ClientName:     r.ClientName,
+		ClientRegistry: r.ClientRegistry,
+		Redirect:       r.Redirect,
+		Jar:            r.Jar,
+
+		HedgeDelay: r.HedgeDelay,
+		HedgeMax:   r.HedgeMax,
+
+		Logger:        r.Logger,
+		RetryLogLevel: r.RetryLogLevel,
+
+		Redactor:          r.Redactor,
+		DisableRedaction:  r.DisableRedaction,
+		MaxDebugBodyBytes: r.MaxDebugBodyBytes,
+		DebugContentTypes: r.DebugContentTypes,
+
+		Hooks:      r.Hooks,
+		Middleware: r.Middleware,
+		Clock:      r.Clock,
+
+		TokenSource:         r.TokenSource,
+		RetryOnUnauthorized: r.RetryOnUnauthorized,
+		ReauthFunc:          r.ReauthFunc,
+
+		RateLimiter: r.RateLimiter,
+		HostLimiter: r.HostLimiter,
+
+		AddIdempotencyKey: r.AddIdempotencyKey,
+		SafeRetries:       r.SafeRetries,
+
+		Cache:        r.Cache,
+		StaleOnError: r.StaleOnError,
+
+		CompressRequest:    r.CompressRequest,
+		DecompressResponse: r.DecompressResponse,
+
+		RotateAddresses: r.RotateAddresses,
+
+		HostPolicy: r.HostPolicy,
+
+		FallbackURLs:        r.FallbackURLs,
+		FallbackStatusCodes: r.FallbackStatusCodes,
+
+		AttemptRecorder: r.AttemptRecorder,
+
+		MaxElapsedTime: r.MaxElapsedTime,
+
+		MaxResponseBytes: r.MaxResponseBytes,
+		TruncateResponse: r.TruncateResponse,
+
+		ExpectedSHA256: r.ExpectedSHA256,
+		VerifyDigest:   r.VerifyDigest,
+
+		GraphQLRetryCodes: r.GraphQLRetryCodes,
+
+		PollCursorParam: r.PollCursorParam,
+
+		FollowCreated: r.FollowCreated,
+
+		ExpectStatus: r.ExpectStatus,
+
+		DryRun: r.DryRun,
+
+		AuditSink:       r.AuditSink,
+		AuditHashBodies: r.AuditHashBodies,
+		HARRecorder:     r.HARRecorder,
+		AttachCurl:      r.AttachCurl,
+	}
+}
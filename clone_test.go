@@ -0,0 +1,123 @@
+package httpretry
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClone(t *testing.T) {
+
+	t.Run("GIVEN a base request", func(t *testing.T) {
+		u, err := url.Parse("https://api.example.com/v1")
+		require.NoError(t, err)
+		base := NewRequest(u, WithToken("t0k3n"), WithRequestHeader("X-Tenant", "acme"))
+
+		t.Run("WHEN the clone is changed", func(t *testing.T) {
+			clone := base.Clone()
+			clone.URL.Path = "/v2"
+			clone.Header.Set("X-Tenant", "other")
+
+			t.Run("THEN the base is untouched", func(t *testing.T) {
+				assert.Equal(t, "/v1", base.URL.Path)
+				assert.Equal(t, "acme", base.Header.Get("X-Tenant"))
+			})
+		})
+
+		t.Run("WHEN a variant is derived With a path and a token", func(t *testing.T) {
+			users := base.With(WithPath("users"), WithToken("n3w"), WithRetries(3))
+
+			t.Run("THEN the options apply on top of the base configuration", func(t *testing.T) {
+				assert.Equal(t, "https://api.example.com/v1/users", users.URL.String())
+				assert.Equal(t, "Bearer n3w", users.Header.Get("Authorization"))
+				assert.Equal(t, "acme", users.Header.Get("X-Tenant"))
+				assert.Equal(t, 3, users.RetriesMax)
+				assert.Equal(t, base.Backoff, users.Backoff)
+			})
+
+			t.Run("AND the base is untouched", func(t *testing.T) {
+				assert.Equal(t, "https://api.example.com/v1", base.URL.String())
+				assert.Equal(t, "Bearer t0k3n", base.Header.Get("Authorization"))
+				assert.Equal(t, 10, base.RetriesMax)
+			})
+		})
+
+		t.Run("WHEN a variant overrides the wait", func(t *testing.T) {
+			slow := base.With(WithRetriesWait(time.Hour))
+			fast := slow.With(WithRetriesWait(time.Millisecond))
+			growing := slow.With(func(options *HttpRequestOptions) {
+				options.RetriesWaitMax = 4 * time.Hour
+				options.BackoffMultiplier = 2
+			})
+
+			t.Run("THEN the variant derives its backoff from the new wait", func(t *testing.T) {
+				assert.Equal(t, time.Hour, slow.Backoff.Backoff(1))
+				assert.Equal(t, time.Millisecond, fast.Backoff.Backoff(1))
+				assert.Equal(t, 4*time.Hour, growing.Backoff.Backoff(3))
+			})
+		})
+
+		t.Run("WHEN a variant is derived from a base with its own Backoff", func(t *testing.T) {
+			custom := base.With(WithBackoff(ConstantBackoff{Wait: time.Minute}))
+			variant := custom.With(WithPath("users"))
+
+			t.Run("THEN the variant keeps it", func(t *testing.T) {
+				assert.Equal(t, time.Minute, variant.Backoff.Backoff(1))
+			})
+		})
+
+		t.Run("WHEN the base refreshed a token", func(t *testing.T) {
+			base.reauthToken.set("r3fr3sh3d")
+			defer base.reauthToken.set("")
+			variant := base.With(WithPath("users"))
+
+			t.Run("THEN the variant shares it", func(t *testing.T) {
+				assert.Equal(t, "r3fr3sh3d", variant.reauthToken.get())
+			})
+		})
+
+		t.Run("WHEN a caller set Authorization header is on the base", func(t *testing.T) {
+			custom := base.Clone()
+			custom.Header.Set("Authorization", "Basic dXNlcg==")
+			variant := custom.With(WithPath("users"))
+
+			t.Run("THEN the variant keeps it", func(t *testing.T) {
+				assert.Equal(t, "Basic dXNlcg==", variant.Header.Get("Authorization"))
+			})
+		})
+	})
+}
+
+func TestIntegration_With(t *testing.T) {
+
+	t.Run("GIVEN a base request with a retry condition", func(t *testing.T) {
+		calls := map[string]int{}
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls[r.URL.Path]++
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer ts.Close()
+
+		u, err := url.Parse(ts.URL)
+		require.NoError(t, err)
+		base := NewRequest(u, WithRetries(3), WithRetriesWait(1), WithRetryCondition(RetryOnStatus(http.StatusServiceUnavailable)))
+
+		t.Run("WHEN one endpoint overrides the predicate", func(t *testing.T) {
+			_, _, err := base.With(WithPath("items")).HttpGet(context.Background())
+			require.NoError(t, err)
+			_, _, err = base.With(WithPath("uploads"), WithRetryCondition(RetryOnStatus(http.StatusTooManyRequests))).HttpGet(context.Background())
+			require.NoError(t, err)
+
+			t.Run("THEN each endpoint uses its own policy", func(t *testing.T) {
+				assert.Equal(t, 3, calls["/items"])
+				assert.Equal(t, 1, calls["/uploads"])
+			})
+		})
+	})
+}
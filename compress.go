@@ -0,0 +1,73 @@
+package httpretry
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+func gzipBytes(body []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write(body); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// decodeResponseBody replaces a gzip or deflate encoded body by a decoding
+// reader and removes the headers describing the encoded body, like net/http
+// does for the responses it decodes.  Other encodings, for example br which
+// would need a decoder outside the standard library, are left untouched.
+func decodeResponseBody(resp *http.Response) error {
+	encoding := strings.ToLower(strings.TrimSpace(resp.Header.Get("Content-Encoding")))
+	if encoding != "gzip" && encoding != "deflate" {
+		return nil
+	}
+
+	// HEAD, 204 and 304 responses are encoded but have no body
+	body := bufio.NewReader(resp.Body)
+	if _, err := body.Peek(1); errors.Is(err, io.EOF) {
+		return nil
+	}
+
+	var decoder io.ReadCloser
+	var err error
+	if encoding == "gzip" {
+		decoder, err = gzip.NewReader(body)
+	} else {
+		decoder, err = zlib.NewReader(body)
+	}
+	if err != nil {
+		return fmt.Errorf("decoding %s response: %w", encoding, err)
+	}
+	resp.Body = decodedBody{decoder: decoder, body: resp.Body}
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Content-Length")
+	resp.ContentLength = -1
+	resp.Uncompressed = true
+	return nil
+}
+
+type decodedBody struct {
+	decoder io.ReadCloser
+	body    io.ReadCloser
+}
+
+func (b decodedBody) Read(p []byte) (int, error) {
+	return b.decoder.Read(p)
+}
+
+func (b decodedBody) Close() error {
+	b.decoder.Close()
+	return b.body.Close()
+}
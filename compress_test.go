@@ -0,0 +1,114 @@
+package httpretry
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntegration_CompressRequest(t *testing.T) {
+
+	t.Run("GIVEN a server that fails the first request", func(t *testing.T) {
+		var bodies []string
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			require.Equal(t, "gzip", r.Header.Get("Content-Encoding"))
+			zr, err := gzip.NewReader(r.Body)
+			require.NoError(t, err)
+			body, err := io.ReadAll(zr)
+			require.NoError(t, err)
+			bodies = append(bodies, string(body))
+			if len(bodies) == 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}
+		}))
+		defer ts.Close()
+
+		url, err := url.Parse(ts.URL)
+		require.NoError(t, err)
+
+		t.Run("WHEN HttpPost is sent with CompressRequest", func(t *testing.T) {
+			api := NewHttpRequest(HttpRequestOptions{
+				URL:             url,
+				RetriesMax:      2,
+				RetriesWait:     time.Millisecond,
+				CompressRequest: true,
+				IsRetryCondition: func(resp *http.Response, retryCount int) bool {
+					return resp.StatusCode == http.StatusServiceUnavailable
+				},
+			})
+			_, status, err := api.HttpPost(context.Background(), []byte(`{"data":{}}`))
+			require.NoError(t, err)
+
+			t.Run("THEN every attempt sends the gzipped body", func(t *testing.T) {
+				assert.Equal(t, http.StatusOK, status)
+				assert.Equal(t, []string{`{"data":{}}`, `{"data":{}}`}, bodies)
+			})
+		})
+	})
+}
+
+func TestIntegration_DecompressResponse(t *testing.T) {
+
+	t.Run("GIVEN a server that encodes responses as requested", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.Header.Get("Accept-Encoding") {
+			case "gzip, deflate", "gzip":
+				w.Header().Set("Content-Encoding", "gzip")
+				zw := gzip.NewWriter(w)
+				zw.Write([]byte("compressed"))
+				zw.Close()
+			case "deflate":
+				w.Header().Set("Content-Encoding", "deflate")
+				zw := zlib.NewWriter(w)
+				zw.Write([]byte("compressed"))
+				zw.Close()
+			case "br":
+				w.Header().Set("Content-Encoding", "br")
+				w.Write([]byte("brotli"))
+			default:
+				w.Write([]byte("plain"))
+			}
+		}))
+		defer ts.Close()
+
+		url, err := url.Parse(ts.URL)
+		require.NoError(t, err)
+
+		for _, acceptEncoding := range []string{"", "gzip", "deflate"} {
+			t.Run("WHEN HttpGetFull is sent with DecompressResponse AND Accept-Encoding "+acceptEncoding, func(t *testing.T) {
+				header := http.Header{}
+				if acceptEncoding != "" {
+					header.Set("Accept-Encoding", acceptEncoding)
+				}
+				api := NewHttpRequest(HttpRequestOptions{URL: url, Header: header, DecompressResponse: true})
+				resp, err := api.HttpGetFull(context.Background())
+				require.NoError(t, err)
+
+				t.Run("THEN the body is decoded", func(t *testing.T) {
+					assert.Equal(t, "compressed", string(resp.Body))
+					assert.Empty(t, resp.Header.Get("Content-Encoding"))
+				})
+			})
+		}
+
+		t.Run("WHEN HttpGetFull is sent with DecompressResponse AND Accept-Encoding br", func(t *testing.T) {
+			api := NewHttpRequest(HttpRequestOptions{URL: url, Header: http.Header{"Accept-Encoding": []string{"br"}}, DecompressResponse: true})
+			resp, err := api.HttpGetFull(context.Background())
+			require.NoError(t, err)
+
+			t.Run("THEN the body is returned as received", func(t *testing.T) {
+				assert.Equal(t, "brotli", string(resp.Body))
+				assert.Equal(t, "br", resp.Header.Get("Content-Encoding"))
+			})
+		})
+	})
+}
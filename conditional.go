@@ -0,0 +1,96 @@
+package httpretry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// ErrNoETag is returned by PutIfMatch and PatchIfMatch when the prior
+// response has no ETag to make the update conditional on.
+var ErrNoETag = errors.New("response without an ETag")
+
+// maxMerges bounds how many times a conflicting update is merged and sent
+// again before giving up with the ConflictError.
+const maxMerges = 3
+
+// ConflictError is returned when a conditional update is answered with 412
+// Precondition Failed because the resource changed since ETag was read.  It
+// is never retried as is, see MergeFunc.
+type ConflictError struct {
+	// ETag sent in If-Match
+	ETag string
+	URL  string
+	Body []byte
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("conflict updating %s: resource changed since ETag %s", e.URL, e.ETag)
+}
+
+// MergeFunc builds the body to send again after a ConflictError from the
+// current version of the resource, returning an error gives up with it.
+type MergeFunc func(current *Response) ([]byte, error)
+
+// PutIfMatch puts object only if the resource still has the ETag of prior,
+// typically the response of the GET the update is based on.  On a 412 the
+// resource is fetched again and merge builds a new body, up to 3 times, a nil
+// merge returns the ConflictError right away.
+//
+//	current, err := api.HttpGetFull(ctx)
+//	...
+//	_, err = api.PutIfMatch(ctx, current, update, func(latest *httpretry.Response) ([]byte, error) {
+//		return applyUpdate(latest.Body)
+//	})
+func (r httpRequest) PutIfMatch(ctx context.Context, prior *Response, object []byte, merge MergeFunc, opts ...CallOption) (*Response, error) {
+	return r.doIfMatch(ctx, http.MethodPut, prior, object, merge, opts)
+}
+
+// PatchIfMatch is PutIfMatch with a PATCH.
+func (r httpRequest) PatchIfMatch(ctx context.Context, prior *Response, object []byte, merge MergeFunc, opts ...CallOption) (*Response, error) {
+	return r.doIfMatch(ctx, http.MethodPatch, prior, object, merge, opts)
+}
+
+func (r httpRequest) doIfMatch(ctx context.Context, method string, prior *Response, object []byte, merge MergeFunc, opts []CallOption) (*Response, error) {
+	etag := prior.Header.Get("ETag")
+	for merges := 0; ; merges++ {
+		if etag == "" {
+			return prior, ErrNoETag
+		}
+		callOpts := append(opts[:len(opts):len(opts)], WithHeader("If-Match", etag), retryUnlessPreconditionFailed)
+		resp, err := r.DoFull(ctx, method, object, callOpts...)
+		if err != nil || resp.StatusCode != http.StatusPreconditionFailed {
+			return resp, err
+		}
+		conflict := &ConflictError{ETag: etag, URL: r.URL.String(), Body: resp.Body}
+		if merge == nil || merges >= maxMerges {
+			return resp, conflict
+		}
+
+		current, err := r.HttpGetFull(ctx, opts...)
+		if err != nil {
+			return current, err
+		}
+		if current.StatusCode != http.StatusOK {
+			return current, newUnexpectedStatusError([]int{http.StatusOK}, current, r.URL)
+		}
+		if object, err = merge(current); err != nil {
+			return current, err
+		}
+		etag = current.Header.Get("ETag")
+	}
+}
+
+// retryUnlessPreconditionFailed never retries a 412, it won't change by
+// sending the same precondition again.  As a call option it wraps the
+// predicate of the method policy.
+func retryUnlessPreconditionFailed(r *httpRequest) {
+	retry := r.IsRetryCondition
+	if retry == nil {
+		retry = r.StatusClassifier.RetryPredicate()
+	}
+	r.IsRetryCondition = func(resp *http.Response, retryCount int) bool {
+		return resp.StatusCode != http.StatusPreconditionFailed && retry(resp, retryCount)
+	}
+}
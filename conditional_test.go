@@ -0,0 +1,109 @@
+package httpretry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// versionedServer stores a document and answers 412 to updates with a stale
+// If-Match.
+type versionedServer struct {
+	mu      sync.Mutex
+	version int
+	body    string
+	puts    int
+}
+
+func (s *versionedServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	etag := fmt.Sprintf(`"v%d"`, s.version)
+	switch r.Method {
+	case http.MethodGet:
+		w.Header().Set("ETag", etag)
+		io.WriteString(w, s.body)
+	case http.MethodPut:
+		s.puts++
+		if r.Header.Get("If-Match") != etag {
+			w.WriteHeader(http.StatusPreconditionFailed)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		s.version++
+		s.body = string(body)
+		w.Header().Set("ETag", fmt.Sprintf(`"v%d"`, s.version))
+	}
+}
+
+func (s *versionedServer) update(body string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.version++
+	s.body = body
+}
+
+func TestIntegration_PutIfMatch(t *testing.T) {
+
+	t.Run("GIVEN a document changed after it was read", func(t *testing.T) {
+		server := &versionedServer{body: "a"}
+		ts := httptest.NewServer(server)
+		defer ts.Close()
+
+		url, err := url.Parse(ts.URL)
+		require.NoError(t, err)
+		api := NewHttpRequest(HttpRequestOptions{
+			URL:              url,
+			RetriesWait:      1,
+			IsRetryCondition: RetryOnStatusClass(4),
+		})
+		prior, err := api.HttpGetFull(context.Background())
+		require.NoError(t, err)
+		server.update("a,b")
+
+		t.Run("WHEN it is put without a merge function", func(t *testing.T) {
+			server.puts = 0
+			resp, err := api.PutIfMatch(context.Background(), prior, []byte("a,c"), nil)
+
+			t.Run("THEN a ConflictError is returned without retrying", func(t *testing.T) {
+				var conflict *ConflictError
+				require.True(t, errors.As(err, &conflict))
+				assert.Equal(t, `"v0"`, conflict.ETag)
+				assert.Equal(t, http.StatusPreconditionFailed, resp.StatusCode)
+				assert.Equal(t, 1, server.puts)
+				assert.Equal(t, "a,b", server.body)
+			})
+		})
+
+		t.Run("WHEN it is put with a merge function", func(t *testing.T) {
+			server.puts = 0
+			resp, err := api.PutIfMatch(context.Background(), prior, []byte("a,c"), func(current *Response) ([]byte, error) {
+				return append(current.Body, ",c"...), nil
+			})
+			require.NoError(t, err)
+
+			t.Run("THEN the update is merged into the current version", func(t *testing.T) {
+				assert.Equal(t, http.StatusOK, resp.StatusCode)
+				assert.Equal(t, 2, server.puts)
+				assert.Equal(t, "a,b,c", server.body)
+			})
+		})
+
+		t.Run("WHEN the prior response has no ETag", func(t *testing.T) {
+			_, err := api.PutIfMatch(context.Background(), &Response{}, []byte("x"), nil)
+
+			t.Run("THEN ErrNoETag is returned", func(t *testing.T) {
+				assert.ErrorIs(t, err, ErrNoETag)
+			})
+		})
+	})
+}
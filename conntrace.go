@@ -0,0 +1,173 @@
+package httpretry
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http/httptrace"
+	"sync"
+	"time"
+)
+
+// ConnStats describes the connection used by an attempt, see
+// AttemptSummary.Conn.  Phases which didn't happen, like DNS and Connect on a
+// reused connection, are 0.
+type ConnStats struct {
+	// Reused is true when the connection came from the pool
+	Reused bool
+
+	// IdleTime the connection spent in the pool before being reused
+	IdleTime time.Duration
+
+	DNS     time.Duration
+	Connect time.Duration
+	TLS     time.Duration
+
+	// TTFB is the time from asking for a connection to the first response
+	// byte
+	TTFB time.Duration
+}
+
+// ConnPoolStats are totals over the attempts of every request since the
+// process started, see GetConnPoolStats.
+type ConnPoolStats struct {
+	// Conns number of attempts which got a connection
+	Conns  int64
+	Reused int64
+
+	DNS     time.Duration
+	Connect time.Duration
+	TLS     time.Duration
+}
+
+// ReuseRatio returns the fraction of attempts sent on a pooled connection, a
+// low ratio under load means connections aren't kept alive, for example
+// because response bodies aren't drained or MaxIdleConnsPerHost is too low.
+func (s ConnPoolStats) ReuseRatio() float64 {
+	if s.Conns == 0 {
+		return 0
+	}
+	return float64(s.Reused) / float64(s.Conns)
+}
+
+var (
+	connPoolStatsMu sync.Mutex
+	connPoolStats   ConnPoolStats
+)
+
+// GetConnPoolStats returns the connection totals of every request.
+func GetConnPoolStats() ConnPoolStats {
+	connPoolStatsMu.Lock()
+	defer connPoolStatsMu.Unlock()
+	return connPoolStats
+}
+
+func recordConnPoolStats(stats ConnStats) {
+	connPoolStatsMu.Lock()
+	defer connPoolStatsMu.Unlock()
+	connPoolStats.Conns++
+	if stats.Reused {
+		connPoolStats.Reused++
+	}
+	connPoolStats.DNS += stats.DNS
+	connPoolStats.Connect += stats.Connect
+	connPoolStats.TLS += stats.TLS
+}
+
+// connTrace collects the ConnStats of an attempt.  The transport can finish
+// a dial in the background after the attempt got another connection, hence
+// the lock.
+type connTrace struct {
+	mu           sync.Mutex
+	start        time.Time
+	dnsStart     time.Time
+	connectStart time.Time
+	tlsStart     time.Time
+	gotConn      bool
+	wrote        bool
+	stats        ConnStats
+}
+
+// withConnTrace returns ctx tracing the connection of a request, hooks of a
+// trace already in ctx are still called.
+func withConnTrace(ctx context.Context) (context.Context, *connTrace) {
+	t := &connTrace{}
+	return httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		GetConn: func(string) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.start = time.Now()
+		},
+		DNSStart: func(httptrace.DNSStartInfo) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.dnsStart = time.Now()
+		},
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.stats.DNS = time.Since(t.dnsStart)
+		},
+		ConnectStart: func(string, string) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			if t.connectStart.IsZero() {
+				t.connectStart = time.Now()
+			}
+		},
+		ConnectDone: func(string, string, error) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			if !t.gotConn {
+				t.stats.Connect = time.Since(t.connectStart)
+			}
+		},
+		TLSHandshakeStart: func() {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.tlsStart = time.Now()
+		},
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			if !t.gotConn {
+				t.stats.TLS = time.Since(t.tlsStart)
+			}
+		},
+		GotConn: func(info httptrace.GotConnInfo) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.gotConn = true
+			t.stats.Reused = info.Reused
+			t.stats.IdleTime = info.IdleTime
+		},
+		WroteRequest: func(httptrace.WroteRequestInfo) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.wrote = true
+		},
+		GotFirstResponseByte: func() {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.stats.TTFB = time.Since(t.start)
+		},
+	}), t
+}
+
+// done returns the stats of the attempt and adds them to GetConnPoolStats
+// when it got a connection.
+func (t *connTrace) done() ConnStats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.gotConn {
+		recordConnPoolStats(t.stats)
+	}
+	return t.stats
+}
+
+// wroteRequest reports whether the request was written, even partly, so the
+// upstream may have received it.
+func (t *connTrace) wroteRequest() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.wrote
+}
@@ -0,0 +1,69 @@
+package httpretry
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntegration_ConnStats(t *testing.T) {
+
+	t.Run("GIVEN a TLS server AND a dedicated client", func(t *testing.T) {
+		ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("ok"))
+		}))
+		defer ts.Close()
+
+		url, err := url.Parse(ts.URL)
+		require.NoError(t, err)
+		api := NewHttpRequest(HttpRequestOptions{URL: url, Client: ts.Client()})
+
+		t.Run("WHEN two calls are sent", func(t *testing.T) {
+			before := GetConnPoolStats()
+			first, err := api.HttpGetFull(context.Background())
+			require.NoError(t, err)
+			second, err := api.HttpGetFull(context.Background())
+			require.NoError(t, err)
+			after := GetConnPoolStats()
+
+			t.Run("THEN the first attempt opens a connection", func(t *testing.T) {
+				conn := first.History[0].Conn
+				assert.False(t, conn.Reused)
+				assert.Positive(t, conn.Connect)
+				assert.Positive(t, conn.TLS)
+				assert.Positive(t, conn.TTFB)
+			})
+
+			t.Run("AND the second reuses it", func(t *testing.T) {
+				conn := second.History[0].Conn
+				assert.True(t, conn.Reused)
+				assert.Zero(t, conn.Connect)
+				assert.Zero(t, conn.TLS)
+				assert.Positive(t, conn.TTFB)
+			})
+
+			t.Run("AND the pool totals count both", func(t *testing.T) {
+				assert.Equal(t, int64(2), after.Conns-before.Conns)
+				assert.Equal(t, int64(1), after.Reused-before.Reused)
+				assert.Greater(t, after.TLS, before.TLS)
+			})
+		})
+	})
+}
+
+func TestConnPoolStats(t *testing.T) {
+
+	t.Run("GIVEN pool totals", func(t *testing.T) {
+		stats := ConnPoolStats{Conns: 4, Reused: 3}
+
+		t.Run("THEN ReuseRatio is the fraction of reused connections", func(t *testing.T) {
+			assert.Equal(t, 0.75, stats.ReuseRatio())
+			assert.Zero(t, ConnPoolStats{}.ReuseRatio())
+		})
+	})
+}
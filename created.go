@@ -0,0 +1,72 @@
+package httpretry
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+)
+
+// ErrNoLocation is returned by CreatedLocation for a 201 response without a
+// Location header.
+var ErrNoLocation = errors.New("created response without a Location header")
+
+// Location returns the Location header of the response resolved against the
+// URL of the final request, nil when there is none.
+func (r *Response) Location() (*url.URL, error) {
+	location := r.Header.Get("Location")
+	if location == "" {
+		return nil, nil
+	}
+	if r.raw != nil && r.raw.Request != nil {
+		return r.raw.Request.URL.Parse(location)
+	}
+	return url.Parse(location)
+}
+
+// CreatedLocation posts object and returns the URL of the created resource
+// from the Location header of the 201 response, so the caller can follow up
+// without querying for it:
+//
+//	location, _, err := api.CreatedLocation(ctx, body)
+//	thing := api.With(httpretry.WithURL(location))
+//
+// Statuses other than 201 return an error like the other helpers, the
+// Response is never nil.  FollowCreated is ignored.
+func (r httpRequest) CreatedLocation(ctx context.Context, object []byte, opts ...CallOption) (*url.URL, *Response, error) {
+	r.FollowCreated = false
+	r.ExpectStatus = []int{http.StatusCreated}
+	resp, err := r.DoFull(ctx, http.MethodPost, object, opts...)
+	if err != nil {
+		return nil, resp, err
+	}
+	location, err := resp.Location()
+	if err == nil && location == nil {
+		err = ErrNoLocation
+	}
+	return location, resp, err
+}
+
+// followCreated GETs the resource created returns the Location of, see
+// HttpRequestOptions.FollowCreated.  The attempts of both calls are counted
+// in the returned Response.  A Location on another host is requested
+// without credentials.
+func (r httpRequest) followCreated(ctx context.Context, client *http.Client, created *Response, stream bool) (*Response, error) {
+	location, err := created.Location()
+	if err != nil || location == nil {
+		return created, err
+	}
+	if stream && created.raw != nil {
+		created.raw.Body.Close()
+	}
+
+	// ExpectStatus is checked once on the followed response and the call is
+	// audited once
+	r.FollowCreated, r.ExpectStatus, r.AuditSink = false, nil, nil
+	r = r.sendingTo(location)
+	followed, err := r.retryLoop(ctx, client, r.newRequestFactory(http.MethodGet, location.String(), nil), stream)
+	followed.Attempts += created.Attempts
+	followed.History = append(created.History, followed.History...)
+	followed.Duration += created.Duration
+	return followed, err
+}
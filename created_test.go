@@ -0,0 +1,176 @@
+package httpretry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntegration_CreatedLocation(t *testing.T) {
+
+	t.Run("GIVEN a server creating things", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/v1/things":
+				w.Header().Set("Location", "things/42")
+				w.WriteHeader(http.StatusCreated)
+			case "/v1/anonymous":
+				w.WriteHeader(http.StatusCreated)
+			default:
+				w.WriteHeader(http.StatusBadRequest)
+			}
+		}))
+		defer ts.Close()
+
+		u, err := url.Parse(ts.URL + "/v1/")
+		require.NoError(t, err)
+		api := NewRequest(u)
+
+		t.Run("WHEN a thing is created", func(t *testing.T) {
+			location, resp, err := api.With(WithPath("things")).CreatedLocation(context.Background(), []byte(`{}`))
+			require.NoError(t, err)
+
+			t.Run("THEN the Location is resolved against the request URL", func(t *testing.T) {
+				assert.Equal(t, http.StatusCreated, resp.StatusCode)
+				assert.Equal(t, ts.URL+"/v1/things/42", location.String())
+			})
+		})
+
+		t.Run("WHEN the 201 has no Location", func(t *testing.T) {
+			_, _, err := api.With(WithPath("anonymous")).CreatedLocation(context.Background(), nil)
+
+			t.Run("THEN ErrNoLocation is returned", func(t *testing.T) {
+				assert.ErrorIs(t, err, ErrNoLocation)
+			})
+		})
+
+		t.Run("WHEN the thing isn't created", func(t *testing.T) {
+			location, resp, err := api.With(WithPath("invalid")).CreatedLocation(context.Background(), nil)
+
+			t.Run("THEN the status is returned as an error", func(t *testing.T) {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), "actual: 400")
+				assert.Nil(t, location)
+				assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+			})
+		})
+	})
+}
+
+func TestIntegration_FollowCreated(t *testing.T) {
+
+	t.Run("GIVEN a server creating things readable after a replication delay", func(t *testing.T) {
+		reads := 0
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch {
+			case r.Method == http.MethodPost:
+				w.Header().Set("Location", "/things/42")
+				w.WriteHeader(http.StatusCreated)
+			case reads == 0:
+				reads++
+				w.WriteHeader(http.StatusServiceUnavailable)
+			default:
+				reads++
+				w.Write([]byte(`{"id":"42","name":"thing"}`))
+			}
+		}))
+		defer ts.Close()
+
+		u, err := url.Parse(ts.URL + "/things")
+		require.NoError(t, err)
+		api := NewRequest(u, WithRetriesWait(1), func(o *HttpRequestOptions) {
+			o.FollowCreated = true
+		})
+
+		t.Run("WHEN a thing is posted", func(t *testing.T) {
+			var thing struct {
+				ID   string `json:"id"`
+				Name string `json:"name"`
+			}
+			_, status, err := api.PostJSON(context.Background(), map[string]string{"name": "thing"}, &thing)
+			require.NoError(t, err)
+
+			t.Run("THEN the created thing is read with retries", func(t *testing.T) {
+				assert.Equal(t, http.StatusOK, status)
+				assert.Equal(t, "42", thing.ID)
+				assert.Equal(t, 2, reads)
+			})
+		})
+
+		t.Run("WHEN the call is sent with DoFull", func(t *testing.T) {
+			resp, err := api.HttpPostFull(context.Background(), []byte(`{}`))
+			require.NoError(t, err)
+
+			t.Run("THEN the attempts of both requests are counted", func(t *testing.T) {
+				assert.Equal(t, 2, resp.Attempts)
+				assert.Equal(t, http.StatusCreated, resp.History[0].StatusCode)
+				assert.Equal(t, http.StatusOK, resp.History[1].StatusCode)
+			})
+		})
+
+		t.Run("WHEN CreatedLocation is used", func(t *testing.T) {
+			location, resp, err := api.CreatedLocation(context.Background(), []byte(`{}`))
+			require.NoError(t, err)
+
+			t.Run("THEN the created resource isn't followed", func(t *testing.T) {
+				assert.Equal(t, http.StatusCreated, resp.StatusCode)
+				assert.Equal(t, ts.URL+"/things/42", location.String())
+			})
+		})
+	})
+}
+
+func TestIntegration_FollowCreatedCrossHost(t *testing.T) {
+
+	t.Run("GIVEN a server creating things stored on another host", func(t *testing.T) {
+		var readAuthorization string
+		storage := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			readAuthorization = r.Header.Get("Authorization")
+			w.Write([]byte(`{"id":"42"}`))
+		}))
+		defer storage.Close()
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Location", storage.URL+"/things/42")
+			w.WriteHeader(http.StatusCreated)
+		}))
+		defer ts.Close()
+
+		u, err := url.Parse(ts.URL + "/things")
+		require.NoError(t, err)
+		var audit bytes.Buffer
+		api := NewRequest(u, WithToken("s3cr3t"), func(o *HttpRequestOptions) {
+			o.FollowCreated = true
+			o.AuditSink = NewJSONLinesAuditSink(&audit)
+		})
+
+		t.Run("WHEN a thing is posted", func(t *testing.T) {
+			body, status, err := api.HttpPost(context.Background(), []byte(`{}`))
+			require.NoError(t, err)
+
+			t.Run("THEN the thing is read without the credentials", func(t *testing.T) {
+				assert.Equal(t, http.StatusOK, status)
+				assert.Equal(t, `{"id":"42"}`, string(body))
+				assert.Empty(t, readAuthorization)
+			})
+
+			t.Run("AND a single record audits the POST", func(t *testing.T) {
+				lines := strings.Split(strings.TrimSpace(audit.String()), "\n")
+				require.Len(t, lines, 1)
+				var record AuditRecord
+				require.NoError(t, json.Unmarshal([]byte(lines[0]), &record))
+				assert.Equal(t, http.MethodPost, record.Method)
+				assert.Equal(t, ts.URL+"/things", record.URL)
+				assert.Equal(t, http.StatusCreated, record.StatusCode)
+				assert.Equal(t, 1, record.Attempts)
+			})
+		})
+	})
+}
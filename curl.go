@@ -0,0 +1,95 @@
+package httpretry
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// CurlError wraps the error of a call with a curl command replaying its
+// final attempt, see HttpRequestOptions.AttachCurl.
+type CurlError struct {
+	Err     error
+	Command string
+}
+
+func (e *CurlError) Error() string {
+	return e.Err.Error() + "\nreplay: " + e.Command
+}
+
+func (e *CurlError) Unwrap() error {
+	return e.Err
+}
+
+// ToCurl returns the curl command sending the call Do would send with
+// method and object, redacted like a debug dump.  Tokens of TokenSource are
+// requested per attempt and left out.
+func (r httpRequest) ToCurl(method string, object []byte, opts ...CallOption) (string, error) {
+	r = r.forCall(method, opts)
+	req, err := r.newRequestFactory(method, r.URL.String(), object)(context.Background())
+	if err != nil {
+		return "", err
+	}
+	return r.dumper().curl(req), nil
+}
+
+// curl formats req as a curl command, binary and streamed bodies are read
+// from stdin.
+func (d dumper) curl(req *http.Request) string {
+	args := []string{"curl"}
+	switch req.Method {
+	case http.MethodGet:
+	case http.MethodHead:
+		args = append(args, "--head")
+	default:
+		args = append(args, "-X", req.Method)
+	}
+	args = append(args, shellQuote(string(d.redactor.Redact([]byte(req.URL.Redacted()), d.token))))
+
+	names := make([]string, 0, len(req.Header))
+	for name := range req.Header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, value := range req.Header[name] {
+			args = append(args, "-H", shellQuote(name+": "+d.headerValue(name, value)))
+		}
+	}
+
+	if req.GetBody != nil && req.ContentLength != 0 {
+		body := d.curlBody(req)
+		if body == "" {
+			args = append(args, "--data-binary", "@-")
+		} else {
+			args = append(args, "--data-raw", shellQuote(body))
+		}
+	}
+	return strings.Join(args, " ")
+}
+
+// curlBody returns the redacted text body of req, empty when it is binary or
+// streamed.
+func (d dumper) curlBody(req *http.Request) string {
+	if isStreamedBody(req) || req.Header.Get("Content-Encoding") != "" {
+		return ""
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return ""
+	}
+	defer body.Close()
+	content, err := io.ReadAll(body)
+	if err != nil || !utf8.Valid(content) {
+		return ""
+	}
+	return string(d.redactor.Redact(content, d.token))
+}
+
+// shellQuote quotes s for a POSIX shell.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,77 @@
+package httpretry
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/mandric/httpretry/httpretrytest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestToCurl(t *testing.T) {
+
+	t.Run("GIVEN a request with a token", func(t *testing.T) {
+		url, err := url.Parse("https://api.example.com/orders?page=2")
+		require.NoError(t, err)
+		api := NewHttpRequest(HttpRequestOptions{
+			URL:    url,
+			Token:  "secret-token",
+			Header: http.Header{"Content-Type": {"application/json"}},
+		})
+
+		t.Run("WHEN a POST is converted", func(t *testing.T) {
+			command, err := api.ToCurl(http.MethodPost, []byte(`{"name":"it's","password":"hunter2"}`))
+			require.NoError(t, err)
+
+			t.Run("THEN the command replays it redacted", func(t *testing.T) {
+				assert.Equal(t, `curl -X POST 'https://api.example.com/orders?page=2'`+
+					` -H 'Authorization: [REDACTED]'`+
+					` -H 'Content-Type: application/json'`+
+					` -H 'User-Agent: `+DefaultUserAgent+`'`+
+					` --data-raw '{"name":"it'\''s","password":"[REDACTED]"}'`, command)
+			})
+		})
+
+		t.Run("WHEN a GET is converted", func(t *testing.T) {
+			command, err := api.ToCurl(http.MethodGet, nil)
+			require.NoError(t, err)
+
+			t.Run("THEN neither a method nor a body is set", func(t *testing.T) {
+				assert.NotContains(t, command, "-X")
+				assert.NotContains(t, command, "--data")
+			})
+		})
+	})
+}
+
+func TestIntegration_AttachCurl(t *testing.T) {
+
+	t.Run("GIVEN a server failing AND AttachCurl", func(t *testing.T) {
+		ts := httpretrytest.NewFlakyServer(1, http.StatusNotFound)
+		defer ts.Close()
+
+		url, err := url.Parse(ts.URL)
+		require.NoError(t, err)
+		api := NewHttpRequest(HttpRequestOptions{URL: url, RetriesMax: NoRetries, AttachCurl: true})
+
+		t.Run("WHEN a call returns an error", func(t *testing.T) {
+			_, err := api.HttpGetFull(context.Background(), ExpectStatus(http.StatusOK))
+
+			t.Run("THEN the error carries the curl command", func(t *testing.T) {
+				var curlErr *CurlError
+				require.True(t, errors.As(err, &curlErr))
+				assert.Equal(t, "curl '"+ts.URL+"' -H 'Authorization: [REDACTED]' -H 'User-Agent: "+DefaultUserAgent+"'", curlErr.Command)
+				assert.Contains(t, err.Error(), "\nreplay: curl")
+			})
+
+			t.Run("AND the original error is kept", func(t *testing.T) {
+				var statusErr *UnexpectedStatusError
+				assert.True(t, errors.As(err, &statusErr))
+			})
+		})
+	})
+}
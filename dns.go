@@ -0,0 +1,69 @@
+package httpretry
+
+import (
+	"context"
+	"net"
+	"time"
+)
+
+type dialAddressKey struct{}
+
+// dialAddress pins the connections to host to ip.
+type dialAddress struct {
+	host string
+	ip   string
+}
+
+func withDialAddress(ctx context.Context, host string, ip string) context.Context {
+	return context.WithValue(ctx, dialAddressKey{}, dialAddress{host: host, ip: ip})
+}
+
+// pinnedDialContext wraps dial to connect to the address pinned in the
+// context instead of resolving the host again.  Dials to other hosts, for
+// example a proxy, are left alone.
+func pinnedDialContext(dial func(ctx context.Context, network, address string) (net.Conn, error)) func(ctx context.Context, network, address string) (net.Conn, error) {
+	if dial == nil {
+		dial = (&net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext
+	}
+	return func(ctx context.Context, network, address string) (net.Conn, error) {
+		pinned, ok := ctx.Value(dialAddressKey{}).(dialAddress)
+		if !ok {
+			return dial(ctx, network, address)
+		}
+		host, port, err := net.SplitHostPort(address)
+		if err != nil || host != pinned.host {
+			return dial(ctx, network, address)
+		}
+		return dial(ctx, network, net.JoinHostPort(pinned.ip, port))
+	}
+}
+
+// unixDialContext dials the Unix domain socket at path whatever the address.
+func unixDialContext(path string) func(ctx context.Context, network, address string) (net.Conn, error) {
+	dialer := net.Dialer{Timeout: 30 * time.Second}
+	return func(ctx context.Context, _, _ string) (net.Conn, error) {
+		return dialer.DialContext(ctx, "unix", path)
+	}
+}
+
+// resolveAddresses looks up the A and AAAA records of host once per call so
+// attempts can rotate over them, see HttpRequestOptions.RotateAddresses.  IP
+// literals and failed lookups return nil, the dial then reports the error.
+func (r httpRequest) resolveAddresses(ctx context.Context, host string) []string {
+	if net.ParseIP(host) != nil {
+		return nil
+	}
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		r.logger().Warnf("Resolving %s failed: %v", host, err)
+		return nil
+	}
+	ips := make([]string, 0, len(addrs))
+	for _, addr := range addrs {
+		ips = append(ips, addr.String())
+	}
+	return ips
+}
@@ -0,0 +1,156 @@
+package httpretry
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPinnedDialContext(t *testing.T) {
+
+	t.Run("GIVEN a listener on 127.0.0.1", func(t *testing.T) {
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		defer ln.Close()
+		_, port, err := net.SplitHostPort(ln.Addr().String())
+		require.NoError(t, err)
+
+		var dialed []string
+		dial := pinnedDialContext(func(ctx context.Context, network, address string) (net.Conn, error) {
+			dialed = append(dialed, address)
+			return (&net.Dialer{}).DialContext(ctx, network, address)
+		})
+
+		t.Run("WHEN a host is dialed with an address pinned for it", func(t *testing.T) {
+			ctx := withDialAddress(context.Background(), "api.example.invalid", "127.0.0.1")
+			conn, err := dial(ctx, "tcp", net.JoinHostPort("api.example.invalid", port))
+			require.NoError(t, err)
+			conn.Close()
+
+			t.Run("THEN the pinned address is dialed", func(t *testing.T) {
+				assert.Equal(t, []string{net.JoinHostPort("127.0.0.1", port)}, dialed)
+			})
+		})
+
+		t.Run("WHEN another host is dialed", func(t *testing.T) {
+			dialed = nil
+			ctx := withDialAddress(context.Background(), "api.example.invalid", "10.0.0.1")
+			conn, err := dial(ctx, "tcp", ln.Addr().String())
+			require.NoError(t, err)
+			conn.Close()
+
+			t.Run("THEN its own address is dialed", func(t *testing.T) {
+				assert.Equal(t, []string{ln.Addr().String()}, dialed)
+			})
+		})
+	})
+}
+
+func TestIntegration_RotateAddresses(t *testing.T) {
+
+	t.Run("GIVEN a server listening on 127.0.0.1 only", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(r.Host))
+		}))
+		defer ts.Close()
+
+		u, err := url.Parse(ts.URL)
+		require.NoError(t, err)
+		u.Host = net.JoinHostPort("localhost", u.Port())
+
+		t.Run("WHEN HttpGet is sent to localhost with RotateAddresses", func(t *testing.T) {
+			addresses := NewHttpRequest(HttpRequestOptions{}).resolveAddresses(context.Background(), "localhost")
+			require.NotEmpty(t, addresses)
+
+			api := NewHttpRequest(HttpRequestOptions{
+				URL:             u,
+				RetriesMax:      len(addresses),
+				RetriesWait:     time.Millisecond,
+				RotateAddresses: true,
+				Logger:          NopLogger{},
+			})
+			body, status, err := api.HttpGet(context.Background())
+
+			t.Run("THEN an attempt reaches the server through one of the addresses", func(t *testing.T) {
+				require.NoError(t, err)
+				assert.Equal(t, http.StatusOK, status)
+				assert.Equal(t, u.Host, string(body))
+			})
+		})
+	})
+}
+
+func TestIntegration_UnixSocket(t *testing.T) {
+
+	t.Run("GIVEN a server on a Unix socket failing once", func(t *testing.T) {
+		socket := filepath.Join(t.TempDir(), "api.sock")
+		ln, err := net.Listen("unix", socket)
+		require.NoError(t, err)
+		calls := 0
+		ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			if calls == 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.Write([]byte(r.Host + r.URL.Path))
+		}))
+		ts.Listener = ln
+		ts.Start()
+		defer ts.Close()
+
+		u, err := url.Parse("http://docker/v1.43/info")
+		require.NoError(t, err)
+		api := NewHttpRequest(HttpRequestOptions{
+			URL:              u,
+			UnixSocket:       socket,
+			RetriesWait:      time.Millisecond,
+			IsRetryCondition: RetryOnStatus(http.StatusServiceUnavailable),
+		})
+
+		t.Run("WHEN HttpGet is sent", func(t *testing.T) {
+			body, status, err := api.HttpGet(context.Background())
+			require.NoError(t, err)
+
+			t.Run("THEN it is retried over the socket", func(t *testing.T) {
+				assert.Equal(t, http.StatusOK, status)
+				assert.Equal(t, "docker/v1.43/info", string(body))
+				assert.Equal(t, 2, calls)
+			})
+		})
+	})
+
+	t.Run("GIVEN a custom DialContext", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		defer ts.Close()
+
+		var dialed []string
+		u, err := url.Parse("http://sidecar.internal/")
+		require.NoError(t, err)
+		api := NewHttpRequest(HttpRequestOptions{
+			URL: u,
+			DialContext: func(ctx context.Context, network, address string) (net.Conn, error) {
+				dialed = append(dialed, address)
+				return (&net.Dialer{}).DialContext(ctx, network, ts.Listener.Addr().String())
+			},
+		})
+
+		t.Run("WHEN HttpGet is sent", func(t *testing.T) {
+			_, status, err := api.HttpGet(context.Background())
+			require.NoError(t, err)
+
+			t.Run("THEN the connection is opened by it", func(t *testing.T) {
+				assert.Equal(t, http.StatusOK, status)
+				assert.Equal(t, []string{"sidecar.internal:80"}, dialed)
+			})
+		})
+	})
+}
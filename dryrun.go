@@ -0,0 +1,100 @@
+package httpretry
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+)
+
+// DryRunHeader is set on the synthetic responses of DryRun.
+const DryRunHeader = "X-Dry-Run"
+
+// dryRunStatusCodes are the failures a dry run checks the retry policy
+// against.
+var dryRunStatusCodes = []int{
+	http.StatusRequestTimeout,
+	http.StatusTooEarly,
+	http.StatusTooManyRequests,
+	http.StatusInternalServerError,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// maxDryRunWaits limits the backoff waits logged by a dry run.
+const maxDryRunWaits = 10
+
+// dryRunTransport answers the requests of DryRun without sending them.
+type dryRunTransport struct {
+	r httpRequest
+}
+
+func (t dryRunTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	d := t.r.dumper()
+	omitted := !d.dumpsBody(req.Header)
+	dump, err := httputil.DumpRequest(req, !omitted)
+	closeRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+	fields := t.r.retryPlan(req)
+	fields["request"] = d.format(dump, req.Header, omitted)
+	t.r.logRetry(LogLevelInfo, "Request dry run", fields)
+
+	status := http.StatusOK
+	if len(t.r.ExpectStatus) > 0 {
+		status = t.r.ExpectStatus[0]
+	}
+	return &http.Response{
+		Status:     fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		StatusCode: status,
+		Proto:      "HTTP/1.1",
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Header:     http.Header{DryRunHeader: {"true"}},
+		Body:       http.NoBody,
+		Request:    req,
+	}, nil
+}
+
+// retryPlan describes what the retry loop would do with req: the attempts
+// and waits, the failure statuses it retries and whether it retries a
+// connection reset.
+func (r httpRequest) retryPlan(req *http.Request) Fields {
+	fields := attemptFields(req.Context(), req, nil, nil, 1)
+	delete(fields, "attempt")
+
+	attempts := maxAttempts(r.RetriesMax)
+	if r.RetriesMax == UnlimitedRetries {
+		fields["attempts_max"] = "unlimited"
+	} else {
+		fields["attempts_max"] = attempts
+	}
+	var waits []string
+	for retryCount := 1; retryCount < attempts && retryCount <= maxDryRunWaits; retryCount++ {
+		waits = append(waits, r.Backoff.Backoff(retryCount).String())
+	}
+	fields["waits"] = waits
+
+	var statuses []int
+	for _, code := range dryRunStatusCodes {
+		resp := &http.Response{StatusCode: code, Header: http.Header{}, Body: http.NoBody, Request: req}
+		retry := r.StatusClassifier.classify(req, code) == StatusRetry
+		if r.IsRetryCondition != nil {
+			retry = r.IsRetryCondition(resp, 1)
+		}
+		if retry && !(r.SafeRetries && mayHaveProcessed(req, resp, nil, nil)) {
+			statuses = append(statuses, code)
+		}
+	}
+	fields["retry_statuses"] = statuses
+
+	err := &url.Error{Op: urlErrorOp(req.Method), URL: req.URL.String(), Err: connectionResetError()}
+	retryError := true
+	if r.IsRetryError != nil {
+		retryError = r.IsRetryError(err, 1)
+	}
+	fields["retry_connection_errors"] = retryError && !(r.SafeRetries && mayHaveProcessed(req, nil, err, nil))
+	return fields
+}
@@ -0,0 +1,76 @@
+package httpretry
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mandric/httpretry/httpretrytest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntegration_DryRun(t *testing.T) {
+
+	t.Run("GIVEN a server AND a request in dry run", func(t *testing.T) {
+		ts := httpretrytest.NewFlakyServer(0, http.StatusServiceUnavailable)
+		defer ts.Close()
+
+		url, err := url.Parse(ts.URL)
+		require.NoError(t, err)
+		logger := &testLogger{}
+		api := NewHttpRequest(HttpRequestOptions{
+			URL:         url,
+			Token:       "secret-token",
+			RetriesMax:  3,
+			RetriesWait: time.Second,
+			SafeRetries: true,
+			Logger:      logger,
+			DryRun:      true,
+		})
+
+		t.Run("WHEN a GET is sent", func(t *testing.T) {
+			resp, err := api.HttpGetFull(context.Background())
+			require.NoError(t, err)
+			logs := strings.Join(logger.lines, "\n")
+
+			t.Run("THEN a synthetic response is returned without reaching the server", func(t *testing.T) {
+				assert.Equal(t, http.StatusOK, resp.StatusCode)
+				assert.Equal(t, "true", resp.Header.Get(DryRunHeader))
+				assert.Equal(t, 0, ts.Requests())
+			})
+
+			t.Run("AND the redacted request is logged", func(t *testing.T) {
+				assert.Contains(t, logs, "Request dry run")
+				assert.Contains(t, logs, "GET / HTTP/1.1")
+				assert.NotContains(t, logs, "secret-token")
+			})
+
+			t.Run("AND the retry plan is logged", func(t *testing.T) {
+				assert.Contains(t, logs, "attempts_max=3")
+				assert.Contains(t, logs, "waits=[1s 1s]")
+				assert.Contains(t, logs, "retry_statuses=[408 425 429 500 502 503 504]")
+				assert.Contains(t, logs, "retry_connection_errors=true")
+			})
+		})
+
+		t.Run("WHEN a POST expecting 201 is sent", func(t *testing.T) {
+			logger.lines = nil
+			resp, err := api.HttpPostFull(context.Background(), []byte(`{}`), ExpectStatus(http.StatusCreated))
+			require.NoError(t, err)
+			logs := strings.Join(logger.lines, "\n")
+
+			t.Run("THEN the synthetic response has the expected status", func(t *testing.T) {
+				assert.Equal(t, http.StatusCreated, resp.StatusCode)
+			})
+
+			t.Run("AND only the unprocessed failures are retried", func(t *testing.T) {
+				assert.Contains(t, logs, "retry_statuses=[408 425 429 503]")
+				assert.Contains(t, logs, "retry_connection_errors=false")
+			})
+		})
+	})
+}
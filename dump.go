@@ -0,0 +1,74 @@
+package httpretry
+
+import (
+	"bytes"
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// TextContentTypes are the media types of textual bodies, use them as
+// DebugContentTypes to keep binary payloads out of debug dumps.
+var TextContentTypes = []string{
+	"text/",
+	"application/json",
+	"application/xml",
+	"application/x-www-form-urlencoded",
+	"+json",
+	"+xml",
+}
+
+// dumpsBody reports whether the body described by header matches the
+// content types of the dumper.  Bodies without a Content-Type are dumped.
+func (d dumper) dumpsBody(header http.Header) bool {
+	if len(d.contentTypes) == 0 || header.Get("Content-Type") == "" {
+		return true
+	}
+	mediaType, _, err := mime.ParseMediaType(header.Get("Content-Type"))
+	if err != nil {
+		return false
+	}
+	for _, contentType := range d.contentTypes {
+		switch {
+		case strings.HasSuffix(contentType, "/"):
+			if strings.HasPrefix(mediaType, contentType) {
+				return true
+			}
+		case strings.HasPrefix(contentType, "+"):
+			if strings.HasSuffix(mediaType, contentType) {
+				return true
+			}
+		case mediaType == contentType:
+			return true
+		}
+	}
+	return false
+}
+
+// format redacts dump, then truncates its body to maxBodyBytes or marks the
+// body omitted when its content type isn't dumped.
+func (d dumper) format(dump []byte, header http.Header, omitted bool) string {
+	dump = d.redactor.Redact(dump, d.token)
+	if omitted {
+		return fmt.Sprintf("%s[%s body omitted]", dump, header.Get("Content-Type"))
+	}
+	if d.maxBodyBytes <= 0 {
+		return string(dump)
+	}
+	end := bytes.Index(dump, []byte("\r\n\r\n"))
+	if end < 0 {
+		return string(dump)
+	}
+	bodyStart := end + len("\r\n\r\n")
+	if len(dump)-bodyStart <= d.maxBodyBytes {
+		return string(dump)
+	}
+	return fmt.Sprintf("%s... [%d bytes truncated]", dump[:bodyStart+d.maxBodyBytes], len(dump)-bodyStart-d.maxBodyBytes)
+}
+
+// headerValue redacts the value of a header like in a dump.
+func (d dumper) headerValue(name string, value string) string {
+	line := string(d.redactor.Redact([]byte(name+": "+value), d.token))
+	return strings.TrimPrefix(line, name+": ")
+}
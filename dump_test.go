@@ -0,0 +1,83 @@
+package httpretry
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntegration_DebugDumpLimits(t *testing.T) {
+
+	t.Run("GIVEN a server returning a large text body and an image", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/image" {
+				w.Header().Set("Content-Type", "image/png")
+				w.Write([]byte("\x89PNG-pixels"))
+				return
+			}
+			w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+			w.Write([]byte(strings.Repeat("a", 100)))
+		}))
+		defer ts.Close()
+
+		t.Run("WHEN MaxDebugBodyBytes is set", func(t *testing.T) {
+			url, err := url.Parse(ts.URL)
+			require.NoError(t, err)
+			logger := &testLogger{}
+			api := NewHttpRequest(HttpRequestOptions{URL: url, Logger: logger, MaxDebugBodyBytes: 10})
+			body, _, err := api.HttpGet(context.Background())
+			require.NoError(t, err)
+
+			t.Run("THEN the dumped body is truncated with a marker", func(t *testing.T) {
+				logs := strings.Join(logger.lines, "\n")
+				assert.Contains(t, logs, "\r\n\r\naaaaaaaaaa... [90 bytes truncated]")
+				assert.NotContains(t, logs, strings.Repeat("a", 11))
+			})
+
+			t.Run("AND the returned body is complete", func(t *testing.T) {
+				assert.Len(t, body, 100)
+			})
+		})
+
+		t.Run("WHEN DebugContentTypes is TextContentTypes", func(t *testing.T) {
+			url, err := url.Parse(ts.URL + "/image")
+			require.NoError(t, err)
+			logger := &testLogger{}
+			api := NewHttpRequest(HttpRequestOptions{URL: url, Logger: logger, DebugContentTypes: TextContentTypes})
+			body, _, err := api.HttpGet(context.Background())
+			require.NoError(t, err)
+
+			t.Run("THEN the binary body is omitted", func(t *testing.T) {
+				logs := strings.Join(logger.lines, "\n")
+				assert.Contains(t, logs, "[image/png body omitted]")
+				assert.NotContains(t, logs, "PNG-pixels")
+				assert.Equal(t, "\x89PNG-pixels", string(body))
+			})
+		})
+	})
+}
+
+func TestDumperContentTypes(t *testing.T) {
+
+	t.Run("GIVEN a dumper limited to text content types", func(t *testing.T) {
+		d := dumper{contentTypes: TextContentTypes}
+
+		t.Run("THEN textual media types are dumped", func(t *testing.T) {
+			for _, contentType := range []string{"text/html; charset=utf-8", "application/json", "application/problem+json", "application/atom+xml", ""} {
+				assert.True(t, d.dumpsBody(http.Header{"Content-Type": {contentType}}), contentType)
+			}
+		})
+
+		t.Run("AND binary media types are not", func(t *testing.T) {
+			for _, contentType := range []string{"image/png", "application/octet-stream", "application/pdf"} {
+				assert.False(t, d.dumpsBody(http.Header{"Content-Type": {contentType}}), contentType)
+			}
+		})
+	})
+}
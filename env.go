@@ -0,0 +1,140 @@
+package httpretry
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LoadOptionsFromEnv reads retry settings from the environment so a deployed
+// service, like a lambda, can be tuned without changing code.  Pass the
+// option last to let the environment win over the code:
+//
+//	env, err := httpretry.LoadOptionsFromEnv()
+//	if err != nil {
+//		return err
+//	}
+//	api := httpretry.NewRequest(u, httpretry.WithRetries(3), env)
+//
+// Unset variables keep the options of the code:
+//
+//	HTTPRETRY_MAX_RETRIES          max number of attempts, 0 or "unlimited"
+//	HTTPRETRY_WAIT                 wait between attempts, for example 500ms
+//	HTTPRETRY_WAIT_MAX             grow the wait exponentially up to this
+//	HTTPRETRY_BACKOFF_MULTIPLIER   growth of the exponential wait
+//	HTTPRETRY_TIMEOUT              MaxElapsedTime of a call
+//	HTTPRETRY_LOG_LEVEL            RetryLogLevel, see ParseLogLevel
+//	HTTPRETRY_RESPECT_RETRY_AFTER  wait for Retry-After, true or false
+//	HTTPRETRY_MAX_RESPONSE_BYTES   MaxResponseBytes
+//	HTTPRETRY_DRY_RUN              DryRun, true or false
+//
+// The wait variables replace a Backoff set by the code.  Every invalid
+// variable is reported in the error.
+func LoadOptionsFromEnv() (Option, error) {
+	var opts []Option
+	var errs []error
+	lookup := func(name string, parse func(value string) (Option, error)) {
+		value, ok := os.LookupEnv(name)
+		if !ok || strings.TrimSpace(value) == "" {
+			return
+		}
+		opt, err := parse(strings.TrimSpace(value))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+			return
+		}
+		opts = append(opts, opt)
+	}
+
+	lookup("HTTPRETRY_MAX_RETRIES", func(value string) (Option, error) {
+		if strings.EqualFold(value, "unlimited") {
+			return WithRetries(UnlimitedRetries), nil
+		}
+		attempts, err := strconv.Atoi(value)
+		if err != nil || attempts < 0 {
+			return nil, fmt.Errorf("invalid number of attempts %q", value)
+		}
+		return WithRetries(attempts), nil
+	})
+	lookup("HTTPRETRY_WAIT", durationOption(func(o *HttpRequestOptions, d time.Duration) {
+		o.RetriesWait = d
+		o.Backoff = nil
+	}))
+	lookup("HTTPRETRY_WAIT_MAX", durationOption(func(o *HttpRequestOptions, d time.Duration) {
+		o.RetriesWaitMax = d
+		o.Backoff = nil
+	}))
+	lookup("HTTPRETRY_BACKOFF_MULTIPLIER", func(value string) (Option, error) {
+		multiplier, err := strconv.ParseFloat(value, 64)
+		if err != nil || multiplier < 1 {
+			return nil, fmt.Errorf("invalid multiplier %q", value)
+		}
+		return func(o *HttpRequestOptions) {
+			o.BackoffMultiplier = multiplier
+			o.Backoff = nil
+		}, nil
+	})
+	lookup("HTTPRETRY_TIMEOUT", durationOption(func(o *HttpRequestOptions, d time.Duration) {
+		o.MaxElapsedTime = d
+	}))
+	lookup("HTTPRETRY_LOG_LEVEL", func(value string) (Option, error) {
+		level, err := ParseLogLevel(value)
+		if err != nil {
+			return nil, err
+		}
+		return func(o *HttpRequestOptions) {
+			o.RetryLogLevel = level
+		}, nil
+	})
+	lookup("HTTPRETRY_RESPECT_RETRY_AFTER", boolOption(func(o *HttpRequestOptions, b bool) {
+		o.RespectRetryAfter = b
+	}))
+	lookup("HTTPRETRY_MAX_RESPONSE_BYTES", func(value string) (Option, error) {
+		limit, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || limit < 0 {
+			return nil, fmt.Errorf("invalid number of bytes %q", value)
+		}
+		return func(o *HttpRequestOptions) {
+			o.MaxResponseBytes = limit
+		}, nil
+	})
+	lookup("HTTPRETRY_DRY_RUN", boolOption(func(o *HttpRequestOptions, b bool) {
+		o.DryRun = b
+	}))
+
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return func(o *HttpRequestOptions) {
+		for _, opt := range opts {
+			opt(o)
+		}
+	}, nil
+}
+
+func durationOption(set func(o *HttpRequestOptions, d time.Duration)) func(value string) (Option, error) {
+	return func(value string) (Option, error) {
+		d, err := time.ParseDuration(value)
+		if err != nil || d < 0 {
+			return nil, fmt.Errorf("invalid duration %q", value)
+		}
+		return func(o *HttpRequestOptions) {
+			set(o, d)
+		}, nil
+	}
+}
+
+func boolOption(set func(o *HttpRequestOptions, b bool)) func(value string) (Option, error) {
+	return func(value string) (Option, error) {
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid boolean %q", value)
+		}
+		return func(o *HttpRequestOptions) {
+			set(o, b)
+		}, nil
+	}
+}
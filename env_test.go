@@ -0,0 +1,67 @@
+package httpretry
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadOptionsFromEnv(t *testing.T) {
+	u, err := url.Parse("https://api.example.com")
+	require.NoError(t, err)
+
+	t.Run("GIVEN retry settings in the environment", func(t *testing.T) {
+		t.Setenv("HTTPRETRY_MAX_RETRIES", "4")
+		t.Setenv("HTTPRETRY_WAIT", "250ms")
+		t.Setenv("HTTPRETRY_WAIT_MAX", "5s")
+		t.Setenv("HTTPRETRY_TIMEOUT", "20s")
+		t.Setenv("HTTPRETRY_LOG_LEVEL", "debug")
+		t.Setenv("HTTPRETRY_RESPECT_RETRY_AFTER", "true")
+
+		t.Run("WHEN the options are applied after the code's", func(t *testing.T) {
+			env, err := LoadOptionsFromEnv()
+			require.NoError(t, err)
+			api := NewRequest(u, WithRetries(10), WithBackoff(ConstantBackoff{Wait: time.Second}), env)
+
+			t.Run("THEN the environment wins", func(t *testing.T) {
+				assert.Equal(t, 4, api.RetriesMax)
+				assert.Equal(t, ExponentialBackoff{Base: 250 * time.Millisecond, Max: 5 * time.Second}, api.Backoff)
+				assert.Equal(t, 20*time.Second, api.MaxElapsedTime)
+				assert.Equal(t, LogLevelDebug, api.RetryLogLevel)
+				assert.True(t, api.RespectRetryAfter)
+			})
+		})
+	})
+
+	t.Run("GIVEN no settings in the environment", func(t *testing.T) {
+
+		t.Run("WHEN the options are applied", func(t *testing.T) {
+			env, err := LoadOptionsFromEnv()
+			require.NoError(t, err)
+			api := NewRequest(u, WithRetries(3), env)
+
+			t.Run("THEN the code's options are kept", func(t *testing.T) {
+				assert.Equal(t, 3, api.RetriesMax)
+				assert.Equal(t, ConstantBackoff{Wait: time.Second}, api.Backoff)
+			})
+		})
+	})
+
+	t.Run("GIVEN invalid settings in the environment", func(t *testing.T) {
+		t.Setenv("HTTPRETRY_MAX_RETRIES", "many")
+		t.Setenv("HTTPRETRY_WAIT", "soon")
+
+		t.Run("WHEN the options are loaded", func(t *testing.T) {
+			_, err := LoadOptionsFromEnv()
+
+			t.Run("THEN every invalid variable is reported", func(t *testing.T) {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), `HTTPRETRY_MAX_RETRIES: invalid number of attempts "many"`)
+				assert.Contains(t, err.Error(), `HTTPRETRY_WAIT: invalid duration "soon"`)
+			})
+		})
+	})
+}
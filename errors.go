@@ -0,0 +1,73 @@
+package httpretry
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"syscall"
+)
+
+// RetryErrorPredicate decides if a failed attempt is retried based on the
+// error returned by the client, for example retry when the connection is
+// refused but give up on DNS failures.
+type RetryErrorPredicate func(err error, retryCount int) bool
+
+// IsTimeoutError reports whether err is a network timeout, including client
+// and transport timeouts.
+func IsTimeoutError(err error) bool {
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// IsConnectionRefused reports whether the server refused the connection.
+func IsConnectionRefused(err error) bool {
+	return errors.Is(err, syscall.ECONNREFUSED)
+}
+
+// IsConnectionReset reports whether the connection was reset by the peer.
+func IsConnectionReset(err error) bool {
+	return errors.Is(err, syscall.ECONNRESET)
+}
+
+// IsDNSError reports whether the host name could not be resolved.
+func IsDNSError(err error) bool {
+	var dnsErr *net.DNSError
+	return errors.As(err, &dnsErr)
+}
+
+// IsUnexpectedEOF reports whether the connection closed before the response
+// was complete, for example in the middle of the body.
+func IsUnexpectedEOF(err error) bool {
+	return errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// IsPermanentTLSError reports whether the TLS handshake failed in a way
+// retrying can't fix: the certificate is expired, for another host or signed
+// by an unknown authority, or the server doesn't speak TLS at all.  Attempts
+// failing with it are not retried unless IsRetryError says otherwise.
+func IsPermanentTLSError(err error) bool {
+	var (
+		verifyErr    *tls.CertificateVerificationError
+		invalidErr   x509.CertificateInvalidError
+		hostnameErr  x509.HostnameError
+		authorityErr x509.UnknownAuthorityError
+		recordErr    tls.RecordHeaderError
+	)
+	if errors.As(err, &verifyErr) || errors.As(err, &invalidErr) || errors.As(err, &hostnameErr) ||
+		errors.As(err, &authorityErr) || errors.As(err, &recordErr) {
+		return true
+	}
+	// net/http replaces the tls.RecordHeaderError of a plain HTTP server
+	return err != nil && strings.Contains(err.Error(), "server gave HTTP response to HTTPS client")
+}
+
+// IsTLSHandshakeTimeout reports whether the transport gave up waiting for
+// the TLS handshake, see http.Transport.TLSHandshakeTimeout.  It is transient
+// like a reset during the handshake, which IsConnectionReset reports.
+func IsTLSHandshakeTimeout(err error) bool {
+	// net/http doesn't export the error type
+	return IsTimeoutError(err) && strings.Contains(err.Error(), "TLS handshake timeout")
+}
@@ -0,0 +1,186 @@
+package httpretry
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntegration_IsRetryError(t *testing.T) {
+
+	t.Run("GIVEN a closed server", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		url, err := url.Parse(ts.URL)
+		require.NoError(t, err)
+		ts.Close()
+
+		t.Run("AND http request that only retries on DNS errors", func(t *testing.T) {
+			calls := 0
+			api := NewHttpRequest(HttpRequestOptions{
+				URL:         url,
+				RetriesWait: time.Millisecond,
+				IsRetryError: func(err error, retryCount int) bool {
+					calls++
+					return IsDNSError(err)
+				},
+			})
+
+			t.Run("WHEN HttpGet is sent", func(t *testing.T) {
+				_, code, err := api.HttpGet(context.Background())
+
+				t.Run("THEN the connection refused error is returned without retrying", func(t *testing.T) {
+					assert.True(t, IsConnectionRefused(err))
+					assert.Equal(t, 0, code)
+					assert.Equal(t, 1, calls)
+				})
+			})
+		})
+	})
+}
+
+func TestErrorClassifiers(t *testing.T) {
+
+	t.Run("GIVEN a DNS error", func(t *testing.T) {
+		err := &url.Error{Op: "Get", URL: "http://nowhere.invalid", Err: &net.DNSError{Err: "no such host", Name: "nowhere.invalid", IsNotFound: true}}
+
+		t.Run("THEN it is classified as a DNS error only", func(t *testing.T) {
+			assert.True(t, IsDNSError(err))
+			assert.False(t, IsTimeoutError(err))
+			assert.False(t, IsConnectionRefused(err))
+		})
+	})
+
+	t.Run("GIVEN a client timeout", func(t *testing.T) {
+		_, err := (&net.Dialer{Timeout: time.Nanosecond}).Dial("tcp", "10.255.255.1:80")
+
+		t.Run("THEN it is classified as a timeout", func(t *testing.T) {
+			require.Error(t, err)
+			assert.True(t, IsTimeoutError(err))
+		})
+	})
+}
+
+func TestIntegration_UnexpectedEOF(t *testing.T) {
+
+	t.Run("GIVEN a server cutting the first body short", func(t *testing.T) {
+		calls := 0
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			if calls == 1 {
+				w.Header().Set("Content-Length", "100")
+				w.WriteHeader(http.StatusOK)
+				w.Write([]byte("partial"))
+				conn, _, err := w.(http.Hijacker).Hijack()
+				require.NoError(t, err)
+				conn.Close()
+				return
+			}
+			w.Write([]byte("complete"))
+		}))
+		defer ts.Close()
+
+		u, err := url.Parse(ts.URL)
+		require.NoError(t, err)
+
+		t.Run("WHEN HttpGet is sent with the default policy", func(t *testing.T) {
+			var retriedErr error
+			api := NewHttpRequest(HttpRequestOptions{
+				URL:              u,
+				RetriesWait:      time.Millisecond,
+				UseDefaultPolicy: true,
+				Hooks: Hooks{OnRetry: func(event RetryEvent) {
+					retriedErr = event.Err
+				}},
+			})
+			body, _, err := api.HttpGet(context.Background())
+			require.NoError(t, err)
+
+			t.Run("THEN the cut body is retried", func(t *testing.T) {
+				assert.Equal(t, 2, calls)
+				assert.Equal(t, "complete", string(body))
+				assert.True(t, IsUnexpectedEOF(retriedErr))
+			})
+		})
+	})
+}
+
+func TestIntegration_TLSErrors(t *testing.T) {
+
+	t.Run("GIVEN a TLS server with an untrusted certificate", func(t *testing.T) {
+		ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		defer ts.Close()
+
+		url, err := url.Parse(ts.URL)
+		require.NoError(t, err)
+		api := NewHttpRequest(HttpRequestOptions{URL: url, RetriesWait: time.Millisecond})
+
+		t.Run("WHEN HttpGetFull is sent", func(t *testing.T) {
+			resp, err := api.HttpGetFull(context.Background())
+
+			t.Run("THEN it fails fast with a permanent TLS error", func(t *testing.T) {
+				assert.True(t, IsPermanentTLSError(err))
+				assert.Equal(t, 1, resp.Attempts)
+			})
+		})
+	})
+
+	t.Run("GIVEN a plain HTTP server called over https", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		defer ts.Close()
+
+		url, err := url.Parse(ts.URL)
+		require.NoError(t, err)
+		url.Scheme = "https"
+		api := NewHttpRequest(HttpRequestOptions{URL: url, RetriesWait: time.Millisecond})
+
+		t.Run("WHEN HttpGetFull is sent", func(t *testing.T) {
+			resp, err := api.HttpGetFull(context.Background())
+
+			t.Run("THEN it is not retried", func(t *testing.T) {
+				assert.True(t, IsPermanentTLSError(err))
+				assert.Equal(t, 1, resp.Attempts)
+			})
+		})
+	})
+
+	t.Run("GIVEN a server never completing the handshake", func(t *testing.T) {
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		defer ln.Close()
+		go func() {
+			for {
+				conn, err := ln.Accept()
+				if err != nil {
+					return
+				}
+				defer conn.Close()
+			}
+		}()
+
+		url, err := url.Parse("https://" + ln.Addr().String())
+		require.NoError(t, err)
+		api := NewHttpRequest(HttpRequestOptions{
+			URL:         url,
+			RetriesMax:  2,
+			RetriesWait: time.Millisecond,
+			Client:      NewHttpClient(ClientOptions{TLSHandshakeTimeout: 50 * time.Millisecond}),
+		})
+
+		t.Run("WHEN HttpGetFull is sent", func(t *testing.T) {
+			resp, err := api.HttpGetFull(context.Background())
+
+			t.Run("THEN the handshake timeout is retried", func(t *testing.T) {
+				assert.True(t, IsTLSHandshakeTimeout(err))
+				assert.False(t, IsPermanentTLSError(err))
+				assert.Equal(t, 2, resp.Attempts)
+			})
+		})
+	})
+}
@@ -0,0 +1,94 @@
+package httpretry
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// RetryExhaustedError is returned when every attempt of a call failed with an
+// error.  It wraps the error of the last attempt, so errors.Is and errors.As
+// see through it:
+//
+//	var exhausted *httpretry.RetryExhaustedError
+//	if errors.As(err, &exhausted) {
+//		log.Printf("gave up after %d attempts", exhausted.Attempts)
+//	}
+//
+// Calls whose final response still meets IsRetryCondition return that
+// response without an error, like before.
+type RetryExhaustedError struct {
+	// Err of the last attempt
+	Err error
+
+	// StatusCode of the last response received by any attempt, 0 if none
+	StatusCode int
+
+	Attempts int
+	Elapsed  time.Duration
+	History  []AttemptSummary
+}
+
+func (e *RetryExhaustedError) Error() string {
+	return fmt.Sprintf("giving up after %d attempts in %s: %v", e.Attempts, e.Elapsed.Round(time.Millisecond), e.Err)
+}
+
+func (e *RetryExhaustedError) Unwrap() error {
+	return e.Err
+}
+
+func newRetryExhaustedError(err error, history []AttemptSummary, elapsed time.Duration) *RetryExhaustedError {
+	exhausted := &RetryExhaustedError{
+		Err:      err,
+		Attempts: len(history),
+		Elapsed:  elapsed,
+		History:  history,
+	}
+	for _, attempt := range history {
+		if attempt.StatusCode != 0 {
+			exhausted.StatusCode = attempt.StatusCode
+		}
+	}
+	return exhausted
+}
+
+// DeadlineWouldExceedError is returned instead of waiting for the next
+// attempt when the wait would outlast the deadline of the context, so the
+// call fails right away rather than after sleeping.  It matches
+// context.DeadlineExceeded with errors.Is, like the error of the sleep it
+// skips, and wraps the error of the last attempt, if any.
+type DeadlineWouldExceedError struct {
+	// Err of the last attempt, nil when it received a retryable response
+	Err error
+
+	// StatusCode of the last response, 0 if none
+	StatusCode int
+
+	Attempts int
+
+	// Wait the next attempt needed
+	Wait time.Duration
+
+	// Remaining time until the deadline of the context
+	Remaining time.Duration
+}
+
+func (e *DeadlineWouldExceedError) Error() string {
+	msg := fmt.Sprintf("giving up after %d attempts: waiting %s would exceed the context deadline in %s",
+		e.Attempts, e.Wait.Round(time.Millisecond), e.Remaining.Round(time.Millisecond))
+	switch {
+	case e.Err != nil:
+		return fmt.Sprintf("%s: %v", msg, e.Err)
+	case e.StatusCode != 0:
+		return fmt.Sprintf("%s: last status %d", msg, e.StatusCode)
+	}
+	return msg
+}
+
+func (e *DeadlineWouldExceedError) Unwrap() error {
+	return e.Err
+}
+
+func (e *DeadlineWouldExceedError) Is(target error) bool {
+	return target == context.DeadlineExceeded
+}
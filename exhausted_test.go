@@ -0,0 +1,97 @@
+package httpretry
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntegration_RetryExhaustedError(t *testing.T) {
+
+	t.Run("GIVEN a transport refusing every connection", func(t *testing.T) {
+		transport := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			return nil, syscall.ECONNREFUSED
+		})
+		u, err := url.Parse("http://api.example.invalid/things")
+		require.NoError(t, err)
+
+		api := NewHttpRequest(HttpRequestOptions{
+			URL:         u,
+			Transport:   transport,
+			RetriesMax:  3,
+			RetriesWait: time.Millisecond,
+			Logger:      NopLogger{},
+		})
+
+		t.Run("WHEN HttpGet is sent", func(t *testing.T) {
+			_, _, err := api.HttpGet(context.Background())
+
+			t.Run("THEN a RetryExhaustedError describes every attempt", func(t *testing.T) {
+				var exhausted *RetryExhaustedError
+				require.True(t, errors.As(err, &exhausted))
+				assert.Equal(t, 3, exhausted.Attempts)
+				assert.Equal(t, 0, exhausted.StatusCode)
+				require.Len(t, exhausted.History, 3)
+				assert.Equal(t, time.Duration(0), exhausted.History[0].Wait)
+				assert.Equal(t, time.Millisecond, exhausted.History[1].Wait)
+				assert.Equal(t, 3, exhausted.History[2].Attempt)
+				assert.Error(t, exhausted.History[2].Err)
+				assert.Contains(t, err.Error(), "giving up after 3 attempts")
+			})
+
+			t.Run("AND the last error is wrapped", func(t *testing.T) {
+				assert.True(t, errors.Is(err, syscall.ECONNREFUSED))
+			})
+		})
+	})
+}
+
+func TestIntegration_DeadlineWouldExceedError(t *testing.T) {
+
+	t.Run("GIVEN a server answering 503 AND a 1 minute backoff", func(t *testing.T) {
+		calls := 0
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer ts.Close()
+
+		url, err := url.Parse(ts.URL)
+		require.NoError(t, err)
+		api := NewHttpRequest(HttpRequestOptions{URL: url, RetriesWait: time.Minute})
+
+		t.Run("WHEN HttpGet is sent with a 5 seconds deadline", func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			started := time.Now()
+			_, status, err := api.HttpGet(ctx)
+
+			t.Run("THEN it gives up without waiting", func(t *testing.T) {
+				assert.Less(t, time.Since(started), time.Second)
+				assert.Equal(t, 1, calls)
+				assert.Equal(t, http.StatusServiceUnavailable, status)
+			})
+
+			t.Run("AND the error tells the remaining budget", func(t *testing.T) {
+				var wouldExceed *DeadlineWouldExceedError
+				require.True(t, errors.As(err, &wouldExceed))
+				assert.Equal(t, time.Minute, wouldExceed.Wait)
+				assert.Greater(t, wouldExceed.Remaining, 4*time.Second)
+				assert.Equal(t, 1, wouldExceed.Attempts)
+				assert.Equal(t, http.StatusServiceUnavailable, wouldExceed.StatusCode)
+			})
+
+			t.Run("AND it is a deadline exceeded error", func(t *testing.T) {
+				assert.ErrorIs(t, err, context.DeadlineExceeded)
+			})
+		})
+	})
+}
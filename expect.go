@@ -0,0 +1,94 @@
+package httpretry
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// maxErrorBody bounds the body kept in an UnexpectedStatusError.
+const maxErrorBody = 4096
+
+// UnexpectedStatusError is returned when the final status of a call isn't
+// one of ExpectStatus, and by helpers expecting a specific status.  It wraps
+// what the body decodes to, *ProblemDetails or APIErrors, so errors.As sees
+// through it.
+type UnexpectedStatusError struct {
+	Expected   []int
+	StatusCode int
+	URL        string
+
+	// Body of the response, truncated to 4KiB
+	Body []byte
+
+	// Attempts of the call, 0 when unknown
+	Attempts int
+
+	// Err is the decoded problem or JSON:API errors of the body, if any
+	Err error
+}
+
+func (e *UnexpectedStatusError) Error() string {
+	expected := make([]string, len(e.Expected))
+	for i, code := range e.Expected {
+		expected[i] = strconv.Itoa(code)
+	}
+	return fmt.Sprintf("expected %s,\nactual: %d,\nURL: %s,\nresponse: %s", strings.Join(expected, " or "), e.StatusCode, e.URL, string(e.Body))
+}
+
+func (e *UnexpectedStatusError) Unwrap() error {
+	return e.Err
+}
+
+// ExpectStatus makes a single call return an UnexpectedStatusError unless
+// its final status is one of codes, see HttpRequestOptions.ExpectStatus:
+//
+//	body, _, err := api.HttpPost(ctx, object, httpretry.ExpectStatus(http.StatusCreated))
+func ExpectStatus(codes ...int) CallOption {
+	return func(r *httpRequest) {
+		r.ExpectStatus = codes
+	}
+}
+
+// expectsStatus reports whether code meets ExpectStatus.
+func (r httpRequest) expectsStatus(code int) bool {
+	if len(r.ExpectStatus) == 0 {
+		return true
+	}
+	for _, expected := range r.ExpectStatus {
+		if code == expected {
+			return true
+		}
+	}
+	return false
+}
+
+// newUnexpectedStatusError describes the final response of a call.
+func newUnexpectedStatusError(expected []int, resp *Response, u *url.URL) *UnexpectedStatusError {
+	if resp.raw != nil && resp.raw.Request != nil {
+		u = resp.raw.Request.URL
+	}
+	err := unexpectedStatusError(expected, resp.StatusCode, resp.Header, u, resp.Body)
+	err.Attempts = resp.Attempts
+	return err
+}
+
+func unexpectedStatusError(expected []int, statusCode int, header http.Header, u *url.URL, body []byte) *UnexpectedStatusError {
+	err := &UnexpectedStatusError{
+		Expected:   expected,
+		StatusCode: statusCode,
+		URL:        u.String(),
+		Body:       body,
+	}
+	if len(body) > maxErrorBody {
+		err.Body = body[:maxErrorBody]
+	}
+	if problem := ParseProblemDetails(header, body); problem != nil {
+		err.Err = problem
+	} else if apiErrors := ParseAPIErrors(body); apiErrors != nil {
+		err.Err = apiErrors
+	}
+	return err
+}
@@ -0,0 +1,69 @@
+package httpretry
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntegration_ExpectStatus(t *testing.T) {
+
+	t.Run("GIVEN a server answering 503 with a large body", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte(strings.Repeat("x", 2*maxErrorBody)))
+		}))
+		defer ts.Close()
+
+		url, err := url.Parse(ts.URL)
+		require.NoError(t, err)
+		api := NewHttpRequest(HttpRequestOptions{
+			URL:          url,
+			RetriesMax:   2,
+			RetriesWait:  time.Millisecond,
+			ExpectStatus: []int{http.StatusOK, http.StatusNoContent},
+		})
+
+		t.Run("WHEN HttpGet is sent", func(t *testing.T) {
+			body, status, err := api.HttpGet(context.Background())
+
+			t.Run("THEN an UnexpectedStatusError is returned", func(t *testing.T) {
+				var unexpected *UnexpectedStatusError
+				require.True(t, errors.As(err, &unexpected))
+				assert.Equal(t, []int{http.StatusOK, http.StatusNoContent}, unexpected.Expected)
+				assert.Equal(t, http.StatusServiceUnavailable, unexpected.StatusCode)
+				assert.Equal(t, ts.URL, unexpected.URL)
+				assert.Equal(t, 2, unexpected.Attempts)
+				assert.Contains(t, err.Error(), "expected 200 or 204")
+			})
+
+			t.Run("AND its body is truncated", func(t *testing.T) {
+				var unexpected *UnexpectedStatusError
+				require.True(t, errors.As(err, &unexpected))
+				assert.Len(t, unexpected.Body, maxErrorBody)
+			})
+
+			t.Run("AND the response is still returned", func(t *testing.T) {
+				assert.Equal(t, http.StatusServiceUnavailable, status)
+				assert.Len(t, body, 2*maxErrorBody)
+			})
+		})
+
+		t.Run("WHEN a call expects the status", func(t *testing.T) {
+			_, status, err := api.HttpGet(context.Background(), ExpectStatus(http.StatusServiceUnavailable))
+
+			t.Run("THEN no error is returned", func(t *testing.T) {
+				require.NoError(t, err)
+				assert.Equal(t, http.StatusServiceUnavailable, status)
+			})
+		})
+	})
+}
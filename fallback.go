@@ -0,0 +1,42 @@
+package httpretry
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+)
+
+// fallbackRequestFactory sends the requests of newRequest to the scheme and
+// host of fallback, with the idempotency key of the previous host so a
+// mirror can recognize a replayed call.
+func fallbackRequestFactory(newRequest requestFactory, fallback *url.URL, idempotencyKey string) requestFactory {
+	return func(ctx context.Context) (*http.Request, error) {
+		req, err := newRequest(ctx)
+		if err != nil {
+			return nil, err
+		}
+		req.URL.Scheme = fallback.Scheme
+		req.URL.Host = fallback.Host
+		req.Host = fallback.Host
+		if idempotencyKey != "" {
+			req.Header.Set(IdempotencyKeyHeader, idempotencyKey)
+		}
+		return req, nil
+	}
+}
+
+func (r httpRequest) shouldFallback(result *Response) bool {
+	if result.mayHaveProcessed {
+		// a mirror would process the request a second time
+		return false
+	}
+	if result.exhausted {
+		return true
+	}
+	for _, code := range r.FallbackStatusCodes {
+		if result.StatusCode == code {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,79 @@
+package httpretry
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntegration_FallbackURLs(t *testing.T) {
+
+	t.Run("GIVEN a failing primary AND a healthy mirror", func(t *testing.T) {
+		primaryCalls := 0
+		primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			primaryCalls++
+			if r.URL.Path == "/missing" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer primary.Close()
+		var mirrorRequests []string
+		mirror := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mirrorRequests = append(mirrorRequests, r.URL.String())
+			w.Write([]byte("mirror"))
+		}))
+		defer mirror.Close()
+
+		primaryURL, err := url.Parse(primary.URL + "/things?page=2")
+		require.NoError(t, err)
+		mirrorURL, err := url.Parse(mirror.URL)
+		require.NoError(t, err)
+
+		newAPI := func(u *url.URL) httpRequest {
+			return NewHttpRequest(HttpRequestOptions{
+				URL:                 u,
+				RetriesMax:          2,
+				RetriesWait:         time.Millisecond,
+				FallbackURLs:        []*url.URL{mirrorURL},
+				FallbackStatusCodes: []int{http.StatusNotFound},
+				IsRetryCondition: func(resp *http.Response, retryCount int) bool {
+					return resp.StatusCode == http.StatusServiceUnavailable
+				},
+			})
+		}
+
+		t.Run("WHEN HttpGetFull is sent", func(t *testing.T) {
+			resp, err := newAPI(primaryURL).HttpGetFull(context.Background())
+			require.NoError(t, err)
+
+			t.Run("THEN the mirror answers once the primary attempts are exhausted", func(t *testing.T) {
+				assert.Equal(t, 2, primaryCalls)
+				assert.Equal(t, []string{"/things?page=2"}, mirrorRequests)
+				assert.Equal(t, "mirror", string(resp.Body))
+				assert.Equal(t, 3, resp.Attempts)
+			})
+		})
+
+		t.Run("WHEN the primary answers a fallback status", func(t *testing.T) {
+			primaryCalls, mirrorRequests = 0, nil
+			missing := *primaryURL
+			missing.Path, missing.RawQuery = "/missing", ""
+			_, status, err := newAPI(&missing).HttpGet(context.Background())
+			require.NoError(t, err)
+
+			t.Run("THEN the mirror is tried without retrying the primary", func(t *testing.T) {
+				assert.Equal(t, 1, primaryCalls)
+				assert.Equal(t, []string{"/missing"}, mirrorRequests)
+				assert.Equal(t, http.StatusOK, status)
+			})
+		})
+	})
+}
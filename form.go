@@ -0,0 +1,16 @@
+package httpretry
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+)
+
+// HttpPostForm sends values as an application/x-www-form-urlencoded body,
+// for example to OAuth token endpoints.
+func (r httpRequest) HttpPostForm(ctx context.Context, values url.Values, opts ...CallOption) ([]byte, int, error) {
+	r.Header = r.Header.Clone()
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	return r.Do(ctx, http.MethodPost, []byte(values.Encode()), opts...)
+}
@@ -0,0 +1,48 @@
+package httpretry
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntegration_HttpPostForm(t *testing.T) {
+
+	t.Run("GIVEN a token endpoint", func(t *testing.T) {
+		var contentType string
+		var form url.Values
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			contentType = r.Header.Get("Content-Type")
+			require.NoError(t, r.ParseForm())
+			form = r.PostForm
+			w.Write([]byte(`{"access_token":"abc"}`))
+		}))
+		defer ts.Close()
+
+		url, err := url.Parse(ts.URL)
+		require.NoError(t, err)
+
+		t.Run("WHEN HttpPostForm is sent", func(t *testing.T) {
+			api := NewHttpRequest(HttpRequestOptions{URL: url, JSONAPIHeaders: true})
+			values := map[string][]string{"grant_type": {"client_credentials"}, "scope": {"read write"}}
+			_, status, err := api.HttpPostForm(context.Background(), values)
+			require.NoError(t, err)
+
+			t.Run("THEN the values are form encoded", func(t *testing.T) {
+				assert.Equal(t, http.StatusOK, status)
+				assert.Equal(t, "application/x-www-form-urlencoded", contentType)
+				assert.Equal(t, "client_credentials", form.Get("grant_type"))
+				assert.Equal(t, "read write", form.Get("scope"))
+			})
+
+			t.Run("AND the configured header is unchanged", func(t *testing.T) {
+				assert.Equal(t, "application/vnd.api+json", api.Header.Get("Content-Type"))
+			})
+		})
+	})
+}
@@ -0,0 +1,110 @@
+package httpretry
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// GraphQLError is an entry of the errors of a GraphQL response.
+type GraphQLError struct {
+	Message    string         `json:"message"`
+	Path       []any          `json:"path,omitempty"`
+	Extensions map[string]any `json:"extensions,omitempty"`
+}
+
+// Code returns extensions.code, the error code set by most servers, for
+// example RATE_LIMITED.
+func (e GraphQLError) Code() string {
+	code, _ := e.Extensions["code"].(string)
+	return code
+}
+
+// GraphQLErrors is returned by GraphQL when the response has errors, data is
+// still decoded when the server returned partial data.
+type GraphQLErrors []GraphQLError
+
+func (e GraphQLErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Message
+	}
+	return "graphql: " + strings.Join(messages, "; ")
+}
+
+type graphQLRequest struct {
+	Query     string         `json:"query"`
+	Variables map[string]any `json:"variables,omitempty"`
+}
+
+type graphQLResponse struct {
+	Data   json.RawMessage `json:"data"`
+	Errors GraphQLErrors   `json:"errors"`
+}
+
+// GraphQL posts a query or mutation with its variables and decodes the data
+// of the response into out, unless out is nil:
+//
+//	var out struct{ Viewer struct{ Login string } }
+//	err := api.GraphQL(ctx, `query { viewer { login } }`, nil, &out)
+//
+// Transport failures and retryable status codes are retried like every
+// request.  GraphQL servers report most failures with a 200 response, when
+// every error of the response has one of GraphQLRetryCodes the query is
+// sent again after waiting the backoff, RetriesMax times at most.
+func (r httpRequest) GraphQL(ctx context.Context, query string, variables map[string]any, out any, opts ...CallOption) error {
+	r.Header = r.Header.Clone()
+	r.Header.Set("Accept", "application/json")
+
+	for retryCount := 1; ; retryCount++ {
+		var resp graphQLResponse
+		body, status, err := r.DoJSON(ctx, http.MethodPost, graphQLRequest{Query: query, Variables: variables}, nil, opts...)
+		if err != nil {
+			return err
+		}
+		if err := json.Unmarshal(body, &resp); err != nil {
+			if status < 200 || status >= 300 {
+				return unexpectedStatusError([]int{http.StatusOK}, status, nil, r.URL, body)
+			}
+			return &DecodeError{StatusCode: status, Body: body, Err: err}
+		}
+		if len(resp.Errors) > 0 && r.isGraphQLRetry(resp.Errors) && retryCount < maxAttempts(r.RetriesMax) && !isShutdown() {
+			r.logRetry(LogLevelInfo, "Request GraphQL errors are retryable", Fields{"attempt": retryCount, "error": resp.Errors})
+			if err := sleepOrShutdown(ctx, r.clock(), r.Backoff.Backoff(retryCount)); err != nil {
+				return err
+			}
+			continue
+		}
+		if out != nil && len(resp.Data) > 0 && string(resp.Data) != "null" {
+			if err := json.Unmarshal(resp.Data, out); err != nil {
+				return &DecodeError{StatusCode: status, Body: body, Err: err}
+			}
+		}
+		if len(resp.Errors) > 0 {
+			return resp.Errors
+		}
+		if status < 200 || status >= 300 {
+			return unexpectedStatusError([]int{http.StatusOK}, status, nil, r.URL, body)
+		}
+		return nil
+	}
+}
+
+// isGraphQLRetry reports whether every error has a code of
+// GraphQLRetryCodes.
+func (r httpRequest) isGraphQLRetry(errs GraphQLErrors) bool {
+	for _, err := range errs {
+		retryable := false
+		for _, code := range r.GraphQLRetryCodes {
+			if err.Code() == code {
+				retryable = true
+				break
+			}
+		}
+		if !retryable {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,101 @@
+package httpretry
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntegration_GraphQL(t *testing.T) {
+
+	t.Run("GIVEN a GraphQL server rate limiting the first query", func(t *testing.T) {
+		var requests []graphQLRequest
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var req graphQLRequest
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+			requests = append(requests, req)
+			w.Header().Set("Content-Type", "application/json")
+			switch {
+			case req.Variables["login"] == "missing":
+				w.Write([]byte(`{"data":{"user":null},"errors":[{"message":"not found","path":["user"],"extensions":{"code":"NOT_FOUND"}}]}`))
+			case len(requests) == 1:
+				w.Write([]byte(`{"errors":[{"message":"slow down","extensions":{"code":"RATE_LIMITED"}}]}`))
+			default:
+				w.Write([]byte(`{"data":{"user":{"name":"Ada"}}}`))
+			}
+		}))
+		defer ts.Close()
+
+		url, err := url.Parse(ts.URL)
+		require.NoError(t, err)
+		api := NewHttpRequest(HttpRequestOptions{
+			URL:               url,
+			RetriesWait:       time.Millisecond,
+			GraphQLRetryCodes: []string{"RATE_LIMITED"},
+		})
+		query := `query($login: String!) { user(login: $login) { name } }`
+
+		t.Run("WHEN the query is sent", func(t *testing.T) {
+			var out struct {
+				User struct{ Name string }
+			}
+			err := api.GraphQL(context.Background(), query, map[string]any{"login": "ada"}, &out)
+			require.NoError(t, err)
+
+			t.Run("THEN the rate limited query is retried", func(t *testing.T) {
+				assert.Len(t, requests, 2)
+				assert.Equal(t, query, requests[1].Query)
+				assert.Equal(t, "ada", requests[1].Variables["login"])
+			})
+
+			t.Run("AND the data is decoded", func(t *testing.T) {
+				assert.Equal(t, "Ada", out.User.Name)
+			})
+		})
+
+		t.Run("WHEN the query fails with another error", func(t *testing.T) {
+			requests = nil
+			var out map[string]any
+			err := api.GraphQL(context.Background(), query, map[string]any{"login": "missing"}, &out)
+
+			t.Run("THEN the errors are returned without retrying", func(t *testing.T) {
+				var errs GraphQLErrors
+				require.ErrorAs(t, err, &errs)
+				assert.Equal(t, "NOT_FOUND", errs[0].Code())
+				assert.Equal(t, []any{"user"}, errs[0].Path)
+				assert.Equal(t, "graphql: not found", err.Error())
+				assert.Len(t, requests, 1)
+			})
+
+			t.Run("AND the partial data is decoded", func(t *testing.T) {
+				assert.Contains(t, out, "user")
+			})
+		})
+	})
+
+	t.Run("GIVEN a server failing without a GraphQL body", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadGateway)
+		}))
+		defer ts.Close()
+
+		url, err := url.Parse(ts.URL)
+		require.NoError(t, err)
+
+		t.Run("WHEN the query is sent", func(t *testing.T) {
+			err := NewHttpRequest(HttpRequestOptions{URL: url, RetriesMax: NoRetries}).GraphQL(context.Background(), `{ a }`, nil, nil)
+
+			t.Run("THEN the status is returned as an error", func(t *testing.T) {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), "actual: 502")
+			})
+		})
+	})
+}
@@ -0,0 +1,275 @@
+package httpretry
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// HARRecorder captures every attempt of the calls of requests sharing it,
+// retries included, as the entries of a HAR 1.2 file for import into browser
+// devtools or APM tools, see HttpRequestOptions.HARRecorder:
+//
+//	har := httpretry.NewHARRecorder()
+//	api := httpretry.NewHttpRequest(httpretry.HttpRequestOptions{URL: url, HARRecorder: har})
+//	...
+//	err := har.WriteFile("flaky-vendor.har")
+//
+// Headers and bodies are redacted like debug dumps.  Failed attempts have a
+// status of 0 and their error in the _error field.  Bodies are kept in
+// memory, record a limited number of calls.  It is safe for concurrent use.
+//
+// See http://www.softwareishard.com/blog/har-12-spec/
+type HARRecorder struct {
+	mu      sync.Mutex
+	entries []harEntry
+}
+
+// NewHARRecorder returns a recorder without entries.
+func NewHARRecorder() *HARRecorder {
+	return &HARRecorder{}
+}
+
+// Len returns the number of attempts recorded.
+func (h *HARRecorder) Len() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.entries)
+}
+
+// WriteTo writes the recorded attempts as a HAR file.
+func (h *HARRecorder) WriteTo(w io.Writer) (int64, error) {
+	h.mu.Lock()
+	har := harFile{Log: harLog{
+		Version: "1.2",
+		Creator: harCreator{Name: "httpretry", Version: Version},
+		Entries: append([]harEntry{}, h.entries...),
+	}}
+	h.mu.Unlock()
+
+	content, err := json.MarshalIndent(har, "", "  ")
+	if err != nil {
+		return 0, err
+	}
+	n, err := w.Write(content)
+	return int64(n), err
+}
+
+// WriteFile writes the recorded attempts as a HAR file at path.
+func (h *HARRecorder) WriteFile(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if _, err := h.WriteTo(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// record adds an attempt, resp is nil when it failed with err and body is
+// nil for a streamed response.
+func (h *HARRecorder) record(d dumper, req *http.Request, resp *http.Response, body []byte, err error, attempt AttemptSummary) {
+	entry := harEntry{
+		StartedDateTime: attempt.Start.Format(time.RFC3339Nano),
+		Time:            milliseconds(attempt.Duration),
+		Request:         harRequestOf(d, req),
+		Response:        harResponse{Headers: []harNameValue{}, Cookies: []harNameValue{}, HeadersSize: -1, BodySize: -1},
+		Cache:           struct{}{},
+		Timings:         harTimingsOf(attempt),
+	}
+	if resp != nil {
+		entry.Response = harResponseOf(d, resp, body)
+	}
+	if err != nil {
+		entry.Error = string(d.redactor.Redact([]byte(err.Error()), d.token))
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.entries = append(h.entries, entry)
+}
+
+type harFile struct {
+	Log harLog `json:"log"`
+}
+
+type harLog struct {
+	Version string     `json:"version"`
+	Creator harCreator `json:"creator"`
+	Entries []harEntry `json:"entries"`
+}
+
+type harCreator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type harEntry struct {
+	StartedDateTime string      `json:"startedDateTime"`
+	Time            float64     `json:"time"`
+	Request         harRequest  `json:"request"`
+	Response        harResponse `json:"response"`
+	Cache           struct{}    `json:"cache"`
+	Timings         harTimings  `json:"timings"`
+	Error           string      `json:"_error,omitempty"`
+}
+
+type harNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type harRequest struct {
+	Method      string         `json:"method"`
+	URL         string         `json:"url"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []harNameValue `json:"cookies"`
+	Headers     []harNameValue `json:"headers"`
+	QueryString []harNameValue `json:"queryString"`
+	PostData    *harPostData   `json:"postData,omitempty"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int64          `json:"bodySize"`
+}
+
+type harPostData struct {
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+}
+
+type harResponse struct {
+	Status      int            `json:"status"`
+	StatusText  string         `json:"statusText"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []harNameValue `json:"cookies"`
+	Headers     []harNameValue `json:"headers"`
+	Content     harContent     `json:"content"`
+	RedirectURL string         `json:"redirectURL"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int64          `json:"bodySize"`
+}
+
+type harContent struct {
+	Size     int64  `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text,omitempty"`
+	Encoding string `json:"encoding,omitempty"`
+}
+
+type harTimings struct {
+	Blocked float64 `json:"blocked"`
+	DNS     float64 `json:"dns"`
+	Connect float64 `json:"connect"`
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+	SSL     float64 `json:"ssl"`
+}
+
+func harRequestOf(d dumper, req *http.Request) harRequest {
+	har := harRequest{
+		Method:      req.Method,
+		URL:         string(d.redactor.Redact([]byte(req.URL.Redacted()), d.token)),
+		HTTPVersion: req.Proto,
+		Cookies:     []harNameValue{},
+		Headers:     harHeaders(d, req.Header),
+		QueryString: []harNameValue{},
+		HeadersSize: -1,
+		BodySize:    req.ContentLength,
+	}
+	for name, values := range req.URL.Query() {
+		for _, value := range values {
+			redactedValue := strings.TrimPrefix(string(d.redactor.Redact([]byte("?"+name+"="+value), d.token)), "?"+name+"=")
+			har.QueryString = append(har.QueryString, harNameValue{Name: name, Value: redactedValue})
+		}
+	}
+	// reading a streamed body again would buffer it in memory
+	if req.GetBody != nil && req.ContentLength != 0 && !isStreamedBody(req) {
+		if body, err := req.GetBody(); err == nil {
+			content, err := io.ReadAll(body)
+			body.Close()
+			if err == nil && d.dumpsBody(req.Header) {
+				har.PostData = &harPostData{
+					MimeType: req.Header.Get("Content-Type"),
+					Text:     string(d.redactor.Redact(content, d.token)),
+				}
+			}
+		}
+	}
+	return har
+}
+
+func harResponseOf(d dumper, resp *http.Response, body []byte) harResponse {
+	har := harResponse{
+		Status:      resp.StatusCode,
+		StatusText:  http.StatusText(resp.StatusCode),
+		HTTPVersion: resp.Proto,
+		Cookies:     []harNameValue{},
+		Headers:     harHeaders(d, resp.Header),
+		Content:     harContent{Size: resp.ContentLength, MimeType: resp.Header.Get("Content-Type")},
+		RedirectURL: resp.Header.Get("Location"),
+		HeadersSize: -1,
+		BodySize:    resp.ContentLength,
+	}
+	if body == nil || !d.dumpsBody(resp.Header) {
+		return har
+	}
+	har.Content.Size = int64(len(body))
+	har.BodySize = int64(len(body))
+	if utf8.Valid(body) {
+		har.Content.Text = string(d.redactor.Redact(body, d.token))
+	} else {
+		har.Content.Text = base64.StdEncoding.EncodeToString(body)
+		har.Content.Encoding = "base64"
+	}
+	return har
+}
+
+// harHeaders redacts the headers like a dump.
+func harHeaders(d dumper, header http.Header) []harNameValue {
+	headers := []harNameValue{}
+	for name, values := range header {
+		for _, value := range values {
+			headers = append(headers, harNameValue{Name: name, Value: d.headerValue(name, value)})
+		}
+	}
+	return headers
+}
+
+// harTimingsOf splits the duration of an attempt into the HAR phases, phases
+// which didn't happen are -1.
+func harTimingsOf(attempt AttemptSummary) harTimings {
+	conn := attempt.Conn
+	timings := harTimings{Blocked: -1, DNS: -1, Connect: -1, SSL: -1}
+	if conn.DNS > 0 {
+		timings.DNS = milliseconds(conn.DNS)
+	}
+	if conn.Connect > 0 {
+		// the connect phase of HAR includes ssl
+		timings.Connect = milliseconds(conn.Connect + conn.TLS)
+	}
+	if conn.TLS > 0 {
+		timings.SSL = milliseconds(conn.TLS)
+	}
+	wait, receive := attempt.Duration, time.Duration(0)
+	if conn.TTFB > 0 && conn.TTFB <= attempt.Duration {
+		wait = conn.TTFB - conn.DNS - conn.Connect - conn.TLS
+		receive = attempt.Duration - conn.TTFB
+	}
+	if wait < 0 {
+		wait = 0
+	}
+	timings.Wait = milliseconds(wait)
+	timings.Receive = milliseconds(receive)
+	return timings
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
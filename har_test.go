@@ -0,0 +1,95 @@
+package httpretry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mandric/httpretry/httpretrytest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntegration_HARRecorder(t *testing.T) {
+
+	t.Run("GIVEN a server failing once AND a HAR recorder", func(t *testing.T) {
+		ts := httpretrytest.NewFlakyServer(1, http.StatusServiceUnavailable)
+		defer ts.Close()
+
+		url, err := url.Parse(ts.URL + "/orders?page=2")
+		require.NoError(t, err)
+		har := NewHARRecorder()
+		api := NewHttpRequest(HttpRequestOptions{
+			URL:         url,
+			Token:       "secret-token",
+			Clock:       httpretrytest.NewFakeClock(time.Now()),
+			HARRecorder: har,
+		})
+
+		t.Run("WHEN a POST is sent", func(t *testing.T) {
+			_, err := api.HttpPostFull(context.Background(), []byte(`{"password":"hunter2"}`))
+			require.NoError(t, err)
+
+			var buf bytes.Buffer
+			_, err = har.WriteTo(&buf)
+			require.NoError(t, err)
+			var file harFile
+			require.NoError(t, json.Unmarshal(buf.Bytes(), &file))
+
+			t.Run("THEN every attempt is an entry", func(t *testing.T) {
+				assert.Equal(t, 2, har.Len())
+				assert.Equal(t, "1.2", file.Log.Version)
+				require.Len(t, file.Log.Entries, 2)
+				assert.Equal(t, http.StatusServiceUnavailable, file.Log.Entries[0].Response.Status)
+				assert.Equal(t, http.StatusOK, file.Log.Entries[1].Response.Status)
+			})
+
+			t.Run("AND the request is described", func(t *testing.T) {
+				request := file.Log.Entries[0].Request
+				assert.Equal(t, http.MethodPost, request.Method)
+				assert.Equal(t, []harNameValue{{Name: "page", Value: "2"}}, request.QueryString)
+				require.NotNil(t, request.PostData)
+				assert.Contains(t, request.PostData.Text, `"password":"[REDACTED]"`)
+			})
+
+			t.Run("AND the credentials are redacted", func(t *testing.T) {
+				assert.NotContains(t, buf.String(), "secret-token")
+				assert.NotContains(t, buf.String(), "hunter2")
+			})
+		})
+
+		t.Run("WHEN the recording is written to a file", func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "calls.har")
+			require.NoError(t, har.WriteFile(path))
+
+			t.Run("THEN it is valid JSON", func(t *testing.T) {
+				content, err := os.ReadFile(path)
+				require.NoError(t, err)
+				assert.True(t, json.Valid(content))
+			})
+		})
+	})
+
+	t.Run("GIVEN a HAR recorder AND an unreachable server", func(t *testing.T) {
+		url, err := url.Parse("http://127.0.0.1:1")
+		require.NoError(t, err)
+		har := NewHARRecorder()
+
+		t.Run("WHEN a GET is sent", func(t *testing.T) {
+			_, _, err := NewHttpRequest(HttpRequestOptions{URL: url, RetriesMax: NoRetries, HARRecorder: har}).HttpGet(context.Background())
+			require.Error(t, err)
+
+			t.Run("THEN the failed attempt has its error", func(t *testing.T) {
+				require.Equal(t, 1, har.Len())
+				assert.Equal(t, 0, har.entries[0].Response.Status)
+				assert.Contains(t, har.entries[0].Error, "connection refused")
+			})
+		})
+	})
+}
@@ -0,0 +1,52 @@
+package httpretry
+
+import (
+	"net/http"
+	"sync"
+)
+
+// Version of the package, sent in DefaultUserAgent.
+const Version = "0.1.0"
+
+// DefaultUserAgent is sent unless the default headers or a request set
+// another User-Agent.
+const DefaultUserAgent = "httpretry/" + Version
+
+var (
+	defaultHeadersMu sync.RWMutex
+	defaultHeaders   = http.Header{"User-Agent": {DefaultUserAgent}}
+)
+
+// SetDefaultHeaders replaces the headers added to every request created
+// afterwards without its own DefaultHeaders option, for example to send a
+// service specific User-Agent from main.  Headers set by a request win.
+func SetDefaultHeaders(header http.Header) {
+	defaultHeadersMu.Lock()
+	defer defaultHeadersMu.Unlock()
+	defaultHeaders = header.Clone()
+}
+
+func getDefaultHeaders() http.Header {
+	defaultHeadersMu.RLock()
+	defer defaultHeadersMu.RUnlock()
+	return defaultHeaders.Clone()
+}
+
+// JSONAPIHeaders returns the Accept and Content-Type headers of JSON:API
+// requests, see HttpRequestOptions.JSONAPIHeaders.
+func JSONAPIHeaders() http.Header {
+	return http.Header{
+		"Accept":       {"application/vnd.api+json", "application/json", "*/*"},
+		"Content-Type": {"application/vnd.api+json"},
+	}
+}
+
+// addMissingHeaders copies the headers of src that dst doesn't set.
+func addMissingHeaders(dst http.Header, src http.Header) {
+	for name, values := range src {
+		name = http.CanonicalHeaderKey(name)
+		if len(dst.Values(name)) == 0 {
+			dst[name] = append([]string(nil), values...)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package httpretry
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefaultHeaders(t *testing.T) {
+
+	t.Run("GIVEN no header options", func(t *testing.T) {
+		api := NewHttpRequest(HttpRequestOptions{})
+
+		t.Run("THEN only the default User-Agent is added", func(t *testing.T) {
+			assert.Equal(t, DefaultUserAgent, api.Header.Get("User-Agent"))
+			assert.Empty(t, api.Header.Get("Content-Type"))
+			assert.Empty(t, api.Header.Get("Accept"))
+		})
+	})
+
+	t.Run("GIVEN JSONAPIHeaders", func(t *testing.T) {
+		api := NewHttpRequest(HttpRequestOptions{JSONAPIHeaders: true, Header: http.Header{"Accept": {"text/plain"}}})
+
+		t.Run("THEN the JSON:API headers the caller didn't set are added", func(t *testing.T) {
+			assert.Equal(t, []string{"text/plain"}, api.Header.Values("Accept"))
+			assert.Equal(t, "application/vnd.api+json", api.Header.Get("Content-Type"))
+		})
+	})
+
+	t.Run("GIVEN a UserAgent AND DefaultHeaders", func(t *testing.T) {
+		api := NewHttpRequest(HttpRequestOptions{
+			UserAgent:      "billing/2.1",
+			DefaultHeaders: http.Header{"User-Agent": {"ignored"}, "X-Tenant": {"acme"}},
+		})
+
+		t.Run("THEN the UserAgent wins AND the defaults are added", func(t *testing.T) {
+			assert.Equal(t, "billing/2.1", api.Header.Get("User-Agent"))
+			assert.Equal(t, "acme", api.Header.Get("X-Tenant"))
+		})
+	})
+
+	t.Run("GIVEN package default headers", func(t *testing.T) {
+		SetDefaultHeaders(http.Header{"User-Agent": {"service/1.0"}})
+		defer SetDefaultHeaders(http.Header{"User-Agent": {DefaultUserAgent}})
+		api := NewHttpRequest(HttpRequestOptions{})
+
+		t.Run("THEN they are added to new requests", func(t *testing.T) {
+			assert.Equal(t, "service/1.0", api.Header.Get("User-Agent"))
+		})
+	})
+}
+
+func TestIntegration_UserAgent(t *testing.T) {
+
+	t.Run("GIVEN a server", func(t *testing.T) {
+		var userAgent string
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			userAgent = r.UserAgent()
+		}))
+		defer ts.Close()
+
+		u, err := url.Parse(ts.URL)
+		require.NoError(t, err)
+
+		t.Run("WHEN HttpGet is sent", func(t *testing.T) {
+			_, _, err := NewHttpRequest(HttpRequestOptions{URL: u}).HttpGet(context.Background())
+			require.NoError(t, err)
+
+			t.Run("THEN the default User-Agent is sent", func(t *testing.T) {
+				assert.Equal(t, DefaultUserAgent, userAgent)
+			})
+		})
+	})
+}
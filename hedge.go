@@ -0,0 +1,77 @@
+package httpretry
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+type hedgeResult struct {
+	resp     *http.Response
+	respBody []byte
+	err      error
+}
+
+// hedgedRequest sends req and, every HedgeDelay without a response, a
+// duplicate of it up to HedgeMax duplicates.  The first successful response
+// wins and the other requests are cancelled.  An error only wins once every
+// request has failed.
+//
+// Hedging trades extra upstream load for lower tail latency, it is only used
+// for GET and HEAD requests which are safe to send more than once.
+func (r httpRequest) hedgedRequest(ctx context.Context, client *http.Client, req *http.Request) (*http.Response, []byte, error) {
+	results := make(chan hedgeResult, r.HedgeMax+1)
+	var cancels []context.CancelFunc
+	defer func() {
+		for _, cancel := range cancels {
+			cancel()
+		}
+	}()
+
+	launch := func() {
+		hedgeCtx, cancel := context.WithCancel(req.Context())
+		cancels = append(cancels, cancel)
+		hedge := req.Clone(hedgeCtx)
+		go func() {
+			resp, respBody, err := r.doRequest(ctx, client, hedge, false)
+			results <- hedgeResult{resp: resp, respBody: respBody, err: err}
+		}()
+	}
+
+	launch()
+	timer := time.NewTimer(r.HedgeDelay)
+	defer timer.Stop()
+
+	var last hedgeResult
+	pending := 1
+	for {
+		select {
+		case result := <-results:
+			pending--
+			if result.err == nil {
+				return result.resp, result.respBody, nil
+			}
+			last = result
+			if len(cancels) <= r.HedgeMax {
+				// don't wait for the delay when a request already failed
+				launch()
+				pending++
+				timer.Reset(r.HedgeDelay)
+			} else if pending == 0 {
+				return last.resp, last.respBody, last.err
+			}
+		case <-timer.C:
+			if len(cancels) <= r.HedgeMax {
+				launch()
+				pending++
+				timer.Reset(r.HedgeDelay)
+			}
+		case <-ctx.Done():
+			return nil, nil, ctx.Err()
+		}
+	}
+}
+
+func (r httpRequest) isHedged(req *http.Request, stream bool) bool {
+	return r.HedgeDelay > 0 && !stream && (req.Method == http.MethodGet || req.Method == http.MethodHead)
+}
@@ -0,0 +1,68 @@
+package httpretry
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntegration_Hedging(t *testing.T) {
+
+	t.Run("GIVEN a server whose first request is slow", func(t *testing.T) {
+		var requests int32
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if atomic.AddInt32(&requests, 1) == 1 {
+				select {
+				case <-time.After(2 * time.Second):
+				case <-r.Context().Done():
+					return
+				}
+				w.Write([]byte("slow"))
+				return
+			}
+			w.Write([]byte("fast"))
+		}))
+		defer ts.Close()
+
+		url, err := url.Parse(ts.URL)
+		require.NoError(t, err)
+
+		t.Run("AND http request with hedging after 20ms", func(t *testing.T) {
+			api := NewHttpRequest(HttpRequestOptions{
+				URL:        url,
+				HedgeDelay: 20 * time.Millisecond,
+			})
+
+			t.Run("WHEN HttpGet is sent", func(t *testing.T) {
+				start := time.Now()
+				body, code, err := api.HttpGet(context.Background())
+				require.NoError(t, err)
+
+				t.Run("THEN the hedged request wins", func(t *testing.T) {
+					assert.Equal(t, http.StatusOK, code)
+					assert.Equal(t, "fast", string(body))
+					assert.Less(t, time.Since(start), time.Second)
+					assert.Equal(t, int32(2), atomic.LoadInt32(&requests))
+				})
+			})
+
+			t.Run("WHEN HttpPost is sent", func(t *testing.T) {
+				atomic.StoreInt32(&requests, 1)
+				body, _, err := api.HttpPost(context.Background(), []byte("{}"))
+				require.NoError(t, err)
+
+				t.Run("THEN it is not hedged", func(t *testing.T) {
+					assert.Equal(t, "fast", string(body))
+					assert.Equal(t, int32(2), atomic.LoadInt32(&requests))
+				})
+			})
+		})
+	})
+}
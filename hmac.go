@@ -0,0 +1,116 @@
+package httpretry
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"hash"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrStreamedBodySigning is returned by HMACSigner for requests with a
+// streamed body, signing would need to read it twice.
+var ErrStreamedBodySigning = errors.New("streamed request bodies can't be signed")
+
+// HMACCanonicalizer builds the string to sign of a request.
+type HMACCanonicalizer func(req *http.Request, bodyHash string, timestamp string) string
+
+type HMACOptions struct {
+	Secret []byte
+
+	// Header receives the signature
+	// defaults to X-Signature
+	Header string
+
+	// Prefix is prepended to the signature, for example "sha256="
+	Prefix string
+
+	// TimestampHeader receives the unix time the request was signed at
+	// defaults to X-Timestamp
+	TimestampHeader string
+
+	// Hash is the algorithm of the HMAC and of the body hash, for example
+	// sha512.New
+	// defaults to sha256.New
+	Hash func() hash.Hash
+
+	// Base64 encodes the signature with standard base64 instead of hex
+	Base64 bool
+
+	// Canonicalize builds the signed string
+	// defaults to CanonicalHMACRequest
+	Canonicalize HMACCanonicalizer
+}
+
+// CanonicalHMACRequest joins the method, the path with its query, the hex
+// body hash and the timestamp with new lines.
+func CanonicalHMACRequest(req *http.Request, bodyHash string, timestamp string) string {
+	return strings.Join([]string{req.Method, req.URL.RequestURI(), bodyHash, timestamp}, "\n")
+}
+
+// HMACSigner signs requests with a shared secret for webhook style APIs.
+// The signature covers a timestamp so it's computed on every attempt, use
+// Middleware as the last middleware of a request:
+//
+//	signer := httpretry.NewHMACSigner(httpretry.HMACOptions{Secret: secret, Prefix: "sha256="})
+//	api := httpretry.NewHttpRequest(httpretry.HttpRequestOptions{URL: url, Middleware: []httpretry.Middleware{signer.Middleware}})
+type HMACSigner struct {
+	options HMACOptions
+	now     func() time.Time
+}
+
+func NewHMACSigner(options HMACOptions) *HMACSigner {
+	if options.Header == "" {
+		options.Header = "X-Signature"
+	}
+	if options.TimestampHeader == "" {
+		options.TimestampHeader = "X-Timestamp"
+	}
+	if options.Hash == nil {
+		options.Hash = sha256.New
+	}
+	if options.Canonicalize == nil {
+		options.Canonicalize = CanonicalHMACRequest
+	}
+	return &HMACSigner{options: options, now: time.Now}
+}
+
+// Middleware signs the request before passing it to next.
+func (s *HMACSigner) Middleware(next Doer) Doer {
+	return DoerFunc(func(req *http.Request) (*http.Response, error) {
+		if err := s.Sign(req); err != nil {
+			return nil, err
+		}
+		return next.Do(req)
+	})
+}
+
+// Sign sets the timestamp and signature headers of req.
+func (s *HMACSigner) Sign(req *http.Request) error {
+	if isStreamedBody(req) {
+		return ErrStreamedBodySigning
+	}
+	body, err := rereadBody(req)
+	if err != nil {
+		return err
+	}
+	bodyHash := s.options.Hash()
+	bodyHash.Write(body)
+
+	timestamp := strconv.FormatInt(s.now().Unix(), 10)
+	mac := hmac.New(s.options.Hash, s.options.Secret)
+	mac.Write([]byte(s.options.Canonicalize(req, hex.EncodeToString(bodyHash.Sum(nil)), timestamp)))
+
+	signature := hex.EncodeToString(mac.Sum(nil))
+	if s.options.Base64 {
+		signature = base64.StdEncoding.EncodeToString(mac.Sum(nil))
+	}
+	req.Header.Set(s.options.TimestampHeader, timestamp)
+	req.Header.Set(s.options.Header, s.options.Prefix+signature)
+	return nil
+}
@@ -0,0 +1,81 @@
+package httpretry
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHMACSigner(t *testing.T) {
+	now := func() time.Time { return time.Unix(1700000000, 0) }
+
+	t.Run("GIVEN a signer with the default options", func(t *testing.T) {
+		signer := NewHMACSigner(HMACOptions{Secret: []byte("secret"), Prefix: "sha256="})
+		signer.now = now
+
+		t.Run("WHEN a POST is signed", func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodPost, "https://example.com/hooks?id=1", strings.NewReader(`{"a":1}`))
+			require.NoError(t, err)
+			require.NoError(t, signer.Sign(req))
+
+			t.Run("THEN method, path, body hash and timestamp are signed", func(t *testing.T) {
+				bodyHash := sha256.Sum256([]byte(`{"a":1}`))
+				mac := hmac.New(sha256.New, []byte("secret"))
+				mac.Write([]byte("POST\n/hooks?id=1\n" + hex.EncodeToString(bodyHash[:]) + "\n1700000000"))
+				assert.Equal(t, "1700000000", req.Header.Get("X-Timestamp"))
+				assert.Equal(t, "sha256="+hex.EncodeToString(mac.Sum(nil)), req.Header.Get("X-Signature"))
+			})
+
+			t.Run("AND the body can still be sent", func(t *testing.T) {
+				body, err := io.ReadAll(req.Body)
+				require.NoError(t, err)
+				assert.Equal(t, `{"a":1}`, string(body))
+			})
+		})
+	})
+
+	t.Run("GIVEN a signer with custom header, algorithm and canonicalization", func(t *testing.T) {
+		signer := NewHMACSigner(HMACOptions{
+			Secret: []byte("secret"),
+			Header: "X-Hub-Signature",
+			Hash:   sha512.New,
+			Base64: true,
+			Canonicalize: func(req *http.Request, bodyHash string, timestamp string) string {
+				return timestamp + "." + bodyHash
+			},
+		})
+		signer.now = now
+
+		t.Run("WHEN a GET is signed", func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, "https://example.com/", nil)
+			require.NoError(t, err)
+			require.NoError(t, signer.Sign(req))
+
+			t.Run("THEN the options are applied", func(t *testing.T) {
+				bodyHash := sha512.Sum512(nil)
+				mac := hmac.New(sha512.New, []byte("secret"))
+				mac.Write([]byte("1700000000." + hex.EncodeToString(bodyHash[:])))
+				assert.Equal(t, base64.StdEncoding.EncodeToString(mac.Sum(nil)), req.Header.Get("X-Hub-Signature"))
+			})
+		})
+	})
+
+	t.Run("GIVEN a streamed body", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodPost, "https://example.com/", streamedBody{ReadCloser: io.NopCloser(strings.NewReader("x"))})
+		require.NoError(t, err)
+
+		t.Run("THEN signing fails", func(t *testing.T) {
+			assert.ErrorIs(t, NewHMACSigner(HMACOptions{Secret: []byte("secret")}).Sign(req), ErrStreamedBodySigning)
+		})
+	})
+}
@@ -0,0 +1,35 @@
+package httpretry
+
+import (
+	"net/http"
+	"time"
+)
+
+// Hooks are callbacks invoked by the retry loop, for example to emit custom
+// metrics or alerts.  Each hook is optional and runs synchronously so it
+// should return quickly.
+type Hooks struct {
+	// OnRequest is called before every attempt is sent.
+	OnRequest func(event RetryEvent)
+
+	// OnRetry is called after an attempt failed and is going to be retried,
+	// Wait holds the delay before the next attempt.
+	OnRetry func(event RetryEvent)
+
+	// OnGiveUp is called when the last attempt failed and no retries are
+	// left, either RetriesMax was reached or the RetryBudget ran dry.
+	OnGiveUp func(event RetryEvent)
+}
+
+type RetryEvent struct {
+	Request *http.Request
+
+	// Response of the attempt, nil in OnRequest and when the attempt failed
+	// with Err.  Its body has already been read.
+	Response *http.Response
+	Err      error
+
+	// RetryCount number of the attempt, starting at 1
+	RetryCount int
+	Wait       time.Duration
+}
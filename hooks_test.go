@@ -0,0 +1,67 @@
+package httpretry
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntegration_Hooks(t *testing.T) {
+
+	t.Run("GIVEN a server that always returns 503", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer ts.Close()
+
+		url, err := url.Parse(ts.URL)
+		require.NoError(t, err)
+
+		t.Run("AND http request with hooks", func(t *testing.T) {
+			var requests, retries, giveUps []RetryEvent
+			api := NewHttpRequest(HttpRequestOptions{
+				URL:         url,
+				RetriesMax:  3,
+				RetriesWait: 2 * time.Millisecond,
+				IsRetryCondition: func(resp *http.Response, retryCount int) bool {
+					return resp.StatusCode == http.StatusServiceUnavailable
+				},
+				Hooks: Hooks{
+					OnRequest: func(event RetryEvent) { requests = append(requests, event) },
+					OnRetry:   func(event RetryEvent) { retries = append(retries, event) },
+					OnGiveUp:  func(event RetryEvent) { giveUps = append(giveUps, event) },
+				},
+			})
+
+			t.Run("WHEN HttpGet is sent", func(t *testing.T) {
+				_, _, err := api.HttpGet(context.Background())
+				require.NoError(t, err)
+
+				t.Run("THEN OnRequest is called before every attempt", func(t *testing.T) {
+					require.Len(t, requests, 3)
+					for i, event := range requests {
+						assert.Equal(t, i+1, event.RetryCount)
+						assert.Nil(t, event.Response)
+					}
+				})
+
+				t.Run("THEN OnRetry is called with the status and next wait", func(t *testing.T) {
+					require.Len(t, retries, 2)
+					assert.Equal(t, http.StatusServiceUnavailable, retries[0].Response.StatusCode)
+					assert.Equal(t, 2*time.Millisecond, retries[0].Wait)
+				})
+
+				t.Run("THEN OnGiveUp is called once", func(t *testing.T) {
+					require.Len(t, giveUps, 1)
+					assert.Equal(t, 3, giveUps[0].RetryCount)
+				})
+			})
+		})
+	})
+}
@@ -0,0 +1,94 @@
+package httpretry
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"time"
+)
+
+// ErrHostLimitTimeout is returned when an attempt waited longer than the
+// queue timeout of a HostLimiter for a free slot.
+var ErrHostLimitTimeout = errors.New("timed out waiting for host concurrency slot")
+
+// HostLimiter caps the number of in-flight attempts per host so goroutines
+// sharing the singleton client don't exhaust connections or overload a single
+// flaky upstream.  Attempts over the cap queue for up to the queue timeout.
+// Waits between retries don't hold a slot.
+type HostLimiter struct {
+	mu           sync.Mutex
+	maxPerHost   int
+	queueTimeout time.Duration
+	slots        map[string]chan struct{}
+}
+
+// NewHostLimiter allows maxPerHost concurrent attempts per host, a
+// queueTimeout of zero waits until the context is done.  A maxPerHost of
+// zero or less doesn't limit attempts.
+func NewHostLimiter(maxPerHost int, queueTimeout time.Duration) *HostLimiter {
+	return &HostLimiter{
+		maxPerHost:   maxPerHost,
+		queueTimeout: queueTimeout,
+		slots:        map[string]chan struct{}{},
+	}
+}
+
+// InFlight returns the number of attempts currently sent to host.
+func (l *HostLimiter) InFlight(host string) int {
+	return len(l.hostSlots(host))
+}
+
+func (l *HostLimiter) hostSlots(host string) chan struct{} {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	slots, ok := l.slots[host]
+	if !ok {
+		slots = make(chan struct{}, l.maxPerHost)
+		l.slots[host] = slots
+	}
+	return slots
+}
+
+// acquire waits for a slot and returns the function releasing it.
+func (l *HostLimiter) acquire(ctx context.Context, host string) (func(), error) {
+	if l.maxPerHost <= 0 {
+		return func() {}, nil
+	}
+	slots := l.hostSlots(host)
+	release := func() { <-slots }
+
+	select {
+	case slots <- struct{}{}:
+		return release, nil
+	default:
+	}
+
+	var timeout <-chan time.Time
+	if l.queueTimeout > 0 {
+		timer := time.NewTimer(l.queueTimeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+	select {
+	case slots <- struct{}{}:
+		return release, nil
+	case <-timeout:
+		return nil, ErrHostLimitTimeout
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// releaseOnClose holds a host slot until a streamed body is closed.
+type releaseOnClose struct {
+	io.ReadCloser
+	once    sync.Once
+	release func()
+}
+
+func (b *releaseOnClose) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.release)
+	return err
+}
@@ -0,0 +1,103 @@
+package httpretry
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntegration_HostLimiter(t *testing.T) {
+
+	t.Run("GIVEN a slow server that tracks concurrent requests", func(t *testing.T) {
+		var inFlight, maxInFlight int32
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			current := atomic.AddInt32(&inFlight, 1)
+			defer atomic.AddInt32(&inFlight, -1)
+			for {
+				max := atomic.LoadInt32(&maxInFlight)
+				if current <= max || atomic.CompareAndSwapInt32(&maxInFlight, max, current) {
+					break
+				}
+			}
+			time.Sleep(20 * time.Millisecond)
+		}))
+		defer ts.Close()
+
+		url, err := url.Parse(ts.URL)
+		require.NoError(t, err)
+
+		t.Run("AND http request limited to 2 concurrent attempts per host", func(t *testing.T) {
+			api := NewHttpRequest(HttpRequestOptions{
+				URL:         url,
+				HostLimiter: NewHostLimiter(2, 0),
+			})
+
+			t.Run("WHEN 6 calls are sent concurrently", func(t *testing.T) {
+				var wg sync.WaitGroup
+				for i := 0; i < 6; i++ {
+					wg.Add(1)
+					go func() {
+						defer wg.Done()
+						_, _, err := api.HttpGet(context.Background())
+						assert.NoError(t, err)
+					}()
+				}
+				wg.Wait()
+
+				t.Run("THEN no more than 2 were in flight", func(t *testing.T) {
+					assert.Equal(t, int32(2), atomic.LoadInt32(&maxInFlight))
+					assert.Equal(t, 0, api.HostLimiter.InFlight(url.Host))
+				})
+			})
+		})
+
+		t.Run("AND http request limited to 1 attempt with a short queue timeout", func(t *testing.T) {
+			limiter := NewHostLimiter(1, 5*time.Millisecond)
+			api := NewHttpRequest(HttpRequestOptions{
+				URL:         url,
+				HostLimiter: limiter,
+			})
+
+			t.Run("WHEN a streamed response holds the slot", func(t *testing.T) {
+				resp, err := api.HttpGetStream(context.Background())
+				require.NoError(t, err)
+				_, _, err = api.HttpGet(context.Background())
+
+				t.Run("THEN the next call times out in the queue", func(t *testing.T) {
+					assert.ErrorIs(t, err, ErrHostLimitTimeout)
+				})
+
+				t.Run("THEN closing the body frees the slot", func(t *testing.T) {
+					resp.Body.Close()
+					assert.Equal(t, 0, limiter.InFlight(url.Host))
+				})
+			})
+		})
+
+		t.Run("AND http request with a limiter of 0 attempts per host", func(t *testing.T) {
+			api := NewHttpRequest(HttpRequestOptions{
+				URL:         url,
+				HostLimiter: NewHostLimiter(0, 0),
+			})
+
+			t.Run("WHEN HttpGet is sent", func(t *testing.T) {
+				ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+				defer cancel()
+				_, status, err := api.HttpGet(ctx)
+
+				t.Run("THEN it isn't limited", func(t *testing.T) {
+					require.NoError(t, err)
+					assert.Equal(t, http.StatusOK, status)
+				})
+			})
+		})
+	})
+}
@@ -0,0 +1,110 @@
+package httpretry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"strings"
+	"time"
+)
+
+// ErrHostNotAllowed is returned, wrapped, when a URL or a dialed address is
+// rejected by a HostPolicy.  It is never retried.
+var ErrHostNotAllowed = errors.New("host not allowed")
+
+// HostPolicy restricts where requests can go, so URLs built from user input
+// can't reach internal services (SSRF).  Host names are checked before every
+// attempt and redirect, addresses are checked at dial time after resolving,
+// which also catches DNS names pointing at internal addresses.
+//
+// The dial check needs an *http.Transport and is skipped when Transport is
+// set.  Behind a proxy the dialed address is the proxy's.
+type HostPolicy struct {
+	// AllowHosts are the host names or IPs requests can be sent to,
+	// "*.example.com" matches the subdomains of example.com.
+	// defaults to any host
+	AllowHosts []string
+
+	// AllowCIDRs are the networks connections can be made to.
+	// defaults to any address
+	AllowCIDRs []netip.Prefix
+
+	// DenyPrivateIPs refuses connections to loopback, private, link-local,
+	// which includes cloud metadata endpoints, and unspecified addresses.
+	DenyPrivateIPs bool
+}
+
+// checkHost returns an error unless host matches AllowHosts.
+func (p *HostPolicy) checkHost(host string) error {
+	if len(p.AllowHosts) == 0 {
+		return nil
+	}
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	for _, allowed := range p.AllowHosts {
+		allowed = strings.ToLower(allowed)
+		if host == allowed {
+			return nil
+		}
+		if suffix, ok := strings.CutPrefix(allowed, "*"); ok && strings.HasSuffix(host, suffix) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s", ErrHostNotAllowed, host)
+}
+
+// checkAddr returns an error unless addr passes AllowCIDRs and
+// DenyPrivateIPs.
+func (p *HostPolicy) checkAddr(addr netip.Addr) error {
+	addr = addr.Unmap()
+	if p.DenyPrivateIPs && (addr.IsLoopback() || addr.IsPrivate() || addr.IsLinkLocalUnicast() ||
+		addr.IsLinkLocalMulticast() || addr.IsInterfaceLocalMulticast() || addr.IsUnspecified()) {
+		return fmt.Errorf("%w: %s is a private address", ErrHostNotAllowed, addr)
+	}
+	if len(p.AllowCIDRs) == 0 {
+		return nil
+	}
+	for _, prefix := range p.AllowCIDRs {
+		if prefix.Contains(addr) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s", ErrHostNotAllowed, addr)
+}
+
+// dialContext wraps dial to resolve the host itself and connect only to
+// addresses passing the policy.  The checked address is dialed, so the name
+// can't resolve to another address in between.
+func (p *HostPolicy) dialContext(dial func(ctx context.Context, network, address string) (net.Conn, error)) func(ctx context.Context, network, address string) (net.Conn, error) {
+	if dial == nil {
+		dial = (&net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext
+	}
+	return func(ctx context.Context, network, address string) (net.Conn, error) {
+		host, port, err := net.SplitHostPort(address)
+		if err != nil {
+			return nil, err
+		}
+		var addrs []netip.Addr
+		if addr, err := netip.ParseAddr(host); err == nil {
+			addrs = []netip.Addr{addr}
+		} else if addrs, err = net.DefaultResolver.LookupNetIP(ctx, "ip", host); err != nil {
+			return nil, err
+		}
+
+		err = fmt.Errorf("%w: %s has no addresses", ErrHostNotAllowed, host)
+		for _, addr := range addrs {
+			if err = p.checkAddr(addr); err != nil {
+				continue
+			}
+			var conn net.Conn
+			if conn, err = dial(ctx, network, net.JoinHostPort(addr.Unmap().String(), port)); err == nil {
+				return conn, nil
+			}
+		}
+		return nil, err
+	}
+}
@@ -0,0 +1,128 @@
+package httpretry
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHostPolicy(t *testing.T) {
+
+	t.Run("GIVEN allowed hosts", func(t *testing.T) {
+		policy := &HostPolicy{AllowHosts: []string{"api.example.com", "*.cdn.example.com"}}
+
+		t.Run("THEN exact and wildcard matches are allowed", func(t *testing.T) {
+			assert.NoError(t, policy.checkHost("api.example.com"))
+			assert.NoError(t, policy.checkHost("API.example.com."))
+			assert.NoError(t, policy.checkHost("eu.cdn.example.com"))
+		})
+
+		t.Run("AND other hosts are not", func(t *testing.T) {
+			assert.ErrorIs(t, policy.checkHost("example.com"), ErrHostNotAllowed)
+			assert.ErrorIs(t, policy.checkHost("cdn.example.com"), ErrHostNotAllowed)
+			assert.ErrorIs(t, policy.checkHost("api.example.com.evil.com"), ErrHostNotAllowed)
+		})
+	})
+
+	t.Run("GIVEN DenyPrivateIPs", func(t *testing.T) {
+		policy := &HostPolicy{DenyPrivateIPs: true}
+
+		t.Run("THEN internal addresses are denied", func(t *testing.T) {
+			for _, addr := range []string{"127.0.0.1", "10.1.2.3", "192.168.0.1", "169.254.169.254", "::1", "fd00::1", "0.0.0.0", "::ffff:127.0.0.1"} {
+				assert.ErrorIs(t, policy.checkAddr(netip.MustParseAddr(addr)), ErrHostNotAllowed, addr)
+			}
+		})
+
+		t.Run("AND public addresses are allowed", func(t *testing.T) {
+			assert.NoError(t, policy.checkAddr(netip.MustParseAddr("93.184.216.34")))
+			assert.NoError(t, policy.checkAddr(netip.MustParseAddr("2606:2800:220:1::")))
+		})
+	})
+
+	t.Run("GIVEN allowed CIDRs", func(t *testing.T) {
+		policy := &HostPolicy{AllowCIDRs: []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")}}
+
+		t.Run("THEN only addresses in them are allowed", func(t *testing.T) {
+			assert.NoError(t, policy.checkAddr(netip.MustParseAddr("10.1.2.3")))
+			assert.ErrorIs(t, policy.checkAddr(netip.MustParseAddr("11.1.2.3")), ErrHostNotAllowed)
+		})
+	})
+}
+
+func TestIntegration_HostPolicy(t *testing.T) {
+
+	t.Run("GIVEN a local server", func(t *testing.T) {
+		calls := 0
+		var ts *httptest.Server
+		ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			if r.URL.Path == "/redirect" {
+				http.Redirect(w, r, strings.Replace(ts.URL, "127.0.0.1", "localhost", 1), http.StatusFound)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer ts.Close()
+
+		u, err := url.Parse(ts.URL)
+		require.NoError(t, err)
+
+		t.Run("WHEN it is called with DenyPrivateIPs", func(t *testing.T) {
+			calls = 0
+			api := NewRequest(u, WithRetriesWait(1), func(o *HttpRequestOptions) {
+				o.HostPolicy = &HostPolicy{DenyPrivateIPs: true}
+			})
+			_, _, err := api.HttpGet(context.Background())
+
+			t.Run("THEN the connection is refused without retries", func(t *testing.T) {
+				assert.ErrorIs(t, err, ErrHostNotAllowed)
+				assert.Equal(t, 0, calls)
+			})
+		})
+
+		t.Run("WHEN it is called with its network allowed", func(t *testing.T) {
+			api := NewRequest(u, func(o *HttpRequestOptions) {
+				o.HostPolicy = &HostPolicy{AllowCIDRs: []netip.Prefix{netip.MustParsePrefix("127.0.0.0/8")}}
+			})
+			_, status, err := api.HttpGet(context.Background())
+
+			t.Run("THEN the request is sent", func(t *testing.T) {
+				require.NoError(t, err)
+				assert.Equal(t, http.StatusOK, status)
+			})
+		})
+
+		t.Run("WHEN it redirects to a host outside AllowHosts", func(t *testing.T) {
+			calls = 0
+			api := NewRequest(u, WithPath("redirect"), func(o *HttpRequestOptions) {
+				o.HostPolicy = &HostPolicy{AllowHosts: []string{"127.0.0.1"}}
+			})
+			_, _, err := api.HttpGet(context.Background())
+
+			t.Run("THEN the redirect is not followed", func(t *testing.T) {
+				assert.ErrorIs(t, err, ErrHostNotAllowed)
+				assert.Equal(t, 1, calls)
+			})
+		})
+
+		t.Run("WHEN its host is not in AllowHosts", func(t *testing.T) {
+			calls = 0
+			api := NewRequest(u, func(o *HttpRequestOptions) {
+				o.HostPolicy = &HostPolicy{AllowHosts: []string{"api.example.com"}}
+			})
+			_, _, err := api.HttpGet(context.Background())
+
+			t.Run("THEN nothing is sent", func(t *testing.T) {
+				assert.ErrorIs(t, err, ErrHostNotAllowed)
+				assert.Equal(t, 0, calls)
+			})
+		})
+	})
+}
@@ -0,0 +1,60 @@
+package httpretrytest
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// FakeClock is a clock for the Clock option of httpretry whose Sleep returns
+// right away after moving the time forward, so retry schedules of hours run
+// instantly and deterministically:
+//
+//	clock := httpretrytest.NewFakeClock(time.Now())
+//	api := httpretry.NewHttpRequest(httpretry.HttpRequestOptions{URL: url, Clock: clock})
+//	...
+//	assert.Equal(t, []time.Duration{time.Minute, 2 * time.Minute}, clock.Sleeps())
+//
+// It is safe for concurrent use.
+type FakeClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	sleeps []time.Duration
+}
+
+func NewFakeClock(now time.Time) *FakeClock {
+	return &FakeClock{now: now}
+}
+
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Sleep records d and advances the clock by d, unless ctx is done.
+func (c *FakeClock) Sleep(ctx context.Context, d time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.sleeps = append(c.sleeps, d)
+	c.now = c.now.Add(d)
+	return nil
+}
+
+// Advance moves the clock forward by d without recording a sleep, for
+// example to simulate the time spent by a request.
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// Sleeps returns the durations passed to Sleep in order.
+func (c *FakeClock) Sleeps() []time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]time.Duration(nil), c.sleeps...)
+}
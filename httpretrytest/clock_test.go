@@ -0,0 +1,39 @@
+package httpretrytest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFakeClock(t *testing.T) {
+
+	t.Run("GIVEN a fake clock", func(t *testing.T) {
+		start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		clock := NewFakeClock(start)
+
+		t.Run("WHEN it sleeps and advances", func(t *testing.T) {
+			assert.NoError(t, clock.Sleep(context.Background(), time.Hour))
+			clock.Advance(time.Second)
+			assert.NoError(t, clock.Sleep(context.Background(), time.Minute))
+
+			t.Run("THEN the time moves forward instantly", func(t *testing.T) {
+				assert.Equal(t, start.Add(time.Hour+time.Minute+time.Second), clock.Now())
+				assert.Equal(t, []time.Duration{time.Hour, time.Minute}, clock.Sleeps())
+			})
+		})
+
+		t.Run("WHEN the context is cancelled", func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+
+			t.Run("THEN Sleep fails without moving the time", func(t *testing.T) {
+				before := clock.Now()
+				assert.ErrorIs(t, clock.Sleep(ctx, time.Hour), context.Canceled)
+				assert.Equal(t, before, clock.Now())
+			})
+		})
+	})
+}
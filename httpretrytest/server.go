@@ -0,0 +1,105 @@
+// Package httpretrytest provides servers with scripted failures for testing
+// retry predicates and policies without hand-rolling httptest handlers.
+package httpretrytest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+)
+
+// Step is one scripted response.
+type Step struct {
+	// StatusCode defaults to 200
+	StatusCode int
+
+	Header http.Header
+	Body   string
+
+	// Delay is waited before responding, use it to trigger client timeouts
+	Delay time.Duration
+
+	// CloseConnection hijacks and closes the connection without a
+	// response, which clients see as a connection reset or unexpected EOF
+	CloseConnection bool
+}
+
+// Server is an httptest.Server answering with a scripted sequence of steps.
+// Once the script is exhausted the last step is repeated.
+type Server struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	steps    []Step
+	requests []*http.Request
+}
+
+// NewScriptedServer starts a server answering request n with steps[n].
+func NewScriptedServer(steps ...Step) *Server {
+	if len(steps) == 0 {
+		steps = []Step{{}}
+	}
+	s := &Server{steps: steps}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// NewFlakyServer starts a server failing the first failures requests with
+// failStatus and answering 200 afterwards.
+func NewFlakyServer(failures int, failStatus int) *Server {
+	steps := make([]Step, 0, failures+1)
+	for i := 0; i < failures; i++ {
+		steps = append(steps, Step{StatusCode: failStatus})
+	}
+	return NewScriptedServer(append(steps, Step{StatusCode: http.StatusOK})...)
+}
+
+// Requests returns the number of requests received.
+func (s *Server) Requests() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.requests)
+}
+
+// Received returns the requests received, in order.  Bodies are already
+// consumed.
+func (s *Server) Received() []*http.Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]*http.Request(nil), s.requests...)
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	step := s.steps[len(s.steps)-1]
+	if len(s.requests) < len(s.steps) {
+		step = s.steps[len(s.requests)]
+	}
+	s.requests = append(s.requests, r.Clone(r.Context()))
+	s.mu.Unlock()
+
+	if step.Delay > 0 {
+		select {
+		case <-time.After(step.Delay):
+		case <-r.Context().Done():
+			return
+		}
+	}
+	if step.CloseConnection {
+		if hijacker, ok := w.(http.Hijacker); ok {
+			if conn, _, err := hijacker.Hijack(); err == nil {
+				conn.Close()
+				return
+			}
+		}
+	}
+	for name, values := range step.Header {
+		w.Header()[name] = values
+	}
+	if step.StatusCode == 0 {
+		step.StatusCode = http.StatusOK
+	}
+	w.WriteHeader(step.StatusCode)
+	w.Write([]byte(step.Body))
+}
@@ -0,0 +1,65 @@
+package httpretrytest
+
+import (
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFlakyServer(t *testing.T) {
+
+	t.Run("GIVEN a server failing twice with 503", func(t *testing.T) {
+		ts := NewFlakyServer(2, http.StatusServiceUnavailable)
+		defer ts.Close()
+
+		t.Run("WHEN three requests are sent", func(t *testing.T) {
+			var codes []int
+			for i := 0; i < 3; i++ {
+				resp, err := http.Get(ts.URL)
+				require.NoError(t, err)
+				resp.Body.Close()
+				codes = append(codes, resp.StatusCode)
+			}
+
+			t.Run("THEN the third succeeds", func(t *testing.T) {
+				assert.Equal(t, []int{503, 503, 200}, codes)
+				assert.Equal(t, 3, ts.Requests())
+			})
+		})
+	})
+}
+
+func TestScriptedServer(t *testing.T) {
+
+	t.Run("GIVEN a scripted server", func(t *testing.T) {
+		ts := NewScriptedServer(
+			Step{CloseConnection: true},
+			Step{StatusCode: http.StatusTooManyRequests, Header: http.Header{"Retry-After": {"1"}}},
+			Step{Body: "done"},
+		)
+		defer ts.Close()
+
+		t.Run("THEN the steps are answered in order AND the last step repeats", func(t *testing.T) {
+			_, err := http.Get(ts.URL)
+			assert.Error(t, err)
+
+			resp, err := http.Get(ts.URL)
+			require.NoError(t, err)
+			resp.Body.Close()
+			assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+			assert.Equal(t, "1", resp.Header.Get("Retry-After"))
+
+			for i := 0; i < 2; i++ {
+				resp, err = http.Get(ts.URL)
+				require.NoError(t, err)
+				body, _ := io.ReadAll(resp.Body)
+				resp.Body.Close()
+				assert.Equal(t, "done", string(body))
+			}
+			assert.Equal(t, 4, ts.Requests())
+		})
+	})
+}
@@ -0,0 +1,63 @@
+package httpretry
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+// IdempotencyKeyHeader carries the key upstreams use to deduplicate retried
+// writes.
+//
+// See https://datatracker.ietf.org/doc/draft-ietf-httpapi-idempotency-key-header/
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// idempotencyKey returns the key for a call, the caller's header if set or a
+// new UUID.  Idempotent methods don't need a key.
+func (r httpRequest) idempotencyKey(req *http.Request) string {
+	if !r.AddIdempotencyKey || idempotentMethods[req.Method] {
+		return ""
+	}
+	if key := req.Header.Get(IdempotencyKeyHeader); key != "" {
+		return key
+	}
+	return uuid.New().String()
+}
+
+// unprocessedStatusCodes are answered before the request is processed, so
+// retrying them can't duplicate a write.
+var unprocessedStatusCodes = map[int]bool{
+	http.StatusRequestTimeout:     true,
+	http.StatusTooEarly:           true,
+	http.StatusTooManyRequests:    true,
+	http.StatusServiceUnavailable: true,
+}
+
+// AllowUnsafeRetries retries a single call like any other even though
+// SafeRetries or StatusClassifier.IdempotentOnly is set, for writes the
+// caller knows are safe to repeat.
+func AllowUnsafeRetries() CallOption {
+	return func(r *httpRequest) {
+		r.SafeRetries = false
+		if r.StatusClassifier != nil && r.StatusClassifier.IdempotentOnly {
+			classifier := *r.StatusClassifier
+			classifier.IdempotentOnly = false
+			r.StatusClassifier = &classifier
+		}
+	}
+}
+
+// mayHaveProcessed reports whether retrying the attempt could duplicate a
+// write, see HttpRequestOptions.SafeRetries.  Errors before the request was
+// written and statuses refusing it are safe, as is a request with an
+// Idempotency-Key the upstream deduplicates.
+func mayHaveProcessed(req *http.Request, resp *http.Response, err error, trace *connTrace) bool {
+	if idempotentMethods[req.Method] || req.Header.Get(IdempotencyKeyHeader) != "" {
+		return false
+	}
+	if err != nil {
+		// hedged attempts aren't traced
+		return trace == nil || trace.wroteRequest()
+	}
+	return !unprocessedStatusCodes[resp.StatusCode]
+}
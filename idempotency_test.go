@@ -0,0 +1,237 @@
+package httpretry
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntegration_IdempotencyKey(t *testing.T) {
+
+	t.Run("GIVEN a server that returns 503 once AND records idempotency keys", func(t *testing.T) {
+		attempts := 1
+		var keys []string
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			keys = append(keys, r.Header.Get(IdempotencyKeyHeader))
+			if attempts > 0 {
+				attempts--
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.WriteHeader(http.StatusCreated)
+		}))
+		defer ts.Close()
+
+		url, err := url.Parse(ts.URL)
+		require.NoError(t, err)
+
+		api := NewHttpRequest(HttpRequestOptions{
+			URL:               url,
+			RetriesWait:       time.Millisecond,
+			AddIdempotencyKey: true,
+			IsRetryCondition: func(resp *http.Response, retryCount int) bool {
+				return resp.StatusCode == http.StatusServiceUnavailable
+			},
+		})
+
+		t.Run("WHEN HttpPostFull is sent", func(t *testing.T) {
+			resp, err := api.HttpPostFull(context.Background(), []byte("{}"))
+			require.NoError(t, err)
+
+			t.Run("THEN every attempt sends the same key AND it is returned", func(t *testing.T) {
+				require.Len(t, keys, 2)
+				assert.NotEmpty(t, keys[0])
+				assert.Equal(t, keys[0], keys[1])
+				assert.Equal(t, keys[0], resp.IdempotencyKey)
+			})
+		})
+
+		t.Run("WHEN a second HttpPostFull is sent", func(t *testing.T) {
+			first := keys[0]
+			keys = nil
+			resp, err := api.HttpPostFull(context.Background(), []byte("{}"))
+			require.NoError(t, err)
+
+			t.Run("THEN it gets a new key", func(t *testing.T) {
+				assert.NotEqual(t, first, resp.IdempotencyKey)
+			})
+		})
+
+		t.Run("WHEN HttpGetFull is sent", func(t *testing.T) {
+			keys = nil
+			resp, err := api.HttpGetFull(context.Background())
+			require.NoError(t, err)
+
+			t.Run("THEN no key is sent", func(t *testing.T) {
+				assert.Equal(t, []string{""}, keys)
+				assert.Empty(t, resp.IdempotencyKey)
+			})
+		})
+	})
+}
+
+func TestIntegration_SafeRetries(t *testing.T) {
+
+	t.Run("GIVEN a server answering with the status of the status query parameter", func(t *testing.T) {
+		var calls atomic.Int32
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls.Add(1)
+			switch r.URL.Query().Get("status") {
+			case "drop":
+				conn, _, err := w.(http.Hijacker).Hijack()
+				if err == nil {
+					conn.Close()
+				}
+			case "503":
+				w.WriteHeader(http.StatusServiceUnavailable)
+			default:
+				w.WriteHeader(http.StatusInternalServerError)
+			}
+		}))
+		defer ts.Close()
+
+		url, err := url.Parse(ts.URL)
+		require.NoError(t, err)
+		options := HttpRequestOptions{URL: url, RetriesMax: 3, RetriesWait: time.Millisecond, SafeRetries: true}
+		api := NewHttpRequest(options)
+
+		t.Run("WHEN a POST is answered 500", func(t *testing.T) {
+			calls.Store(0)
+			_, status, err := api.WithQuery("status", "500").HttpPost(context.Background(), []byte(`{}`))
+			require.NoError(t, err)
+
+			t.Run("THEN it isn't retried", func(t *testing.T) {
+				assert.Equal(t, http.StatusInternalServerError, status)
+				assert.EqualValues(t, 1, calls.Load())
+			})
+		})
+
+		t.Run("WHEN the connection drops after a POST was sent", func(t *testing.T) {
+			calls.Store(0)
+			_, _, err := api.WithQuery("status", "drop").HttpPost(context.Background(), []byte(`{}`))
+
+			t.Run("THEN it isn't retried", func(t *testing.T) {
+				require.Error(t, err)
+				assert.EqualValues(t, 1, calls.Load())
+			})
+		})
+
+		t.Run("WHEN a POST is answered 503", func(t *testing.T) {
+			calls.Store(0)
+			_, _, err := api.WithQuery("status", "503").HttpPost(context.Background(), []byte(`{}`))
+			require.NoError(t, err)
+
+			t.Run("THEN it is retried since it wasn't processed", func(t *testing.T) {
+				assert.EqualValues(t, 3, calls.Load())
+			})
+		})
+
+		t.Run("WHEN a GET is answered 500", func(t *testing.T) {
+			calls.Store(0)
+			_, _, err := api.WithQuery("status", "500").HttpGet(context.Background())
+			require.NoError(t, err)
+
+			t.Run("THEN it is retried", func(t *testing.T) {
+				assert.EqualValues(t, 3, calls.Load())
+			})
+		})
+
+		t.Run("WHEN a POST with an idempotency key is answered 500", func(t *testing.T) {
+			calls.Store(0)
+			withKey := options
+			withKey.AddIdempotencyKey = true
+			_, _, err := NewHttpRequest(withKey).WithQuery("status", "500").HttpPost(context.Background(), []byte(`{}`))
+			require.NoError(t, err)
+
+			t.Run("THEN it is retried", func(t *testing.T) {
+				assert.EqualValues(t, 3, calls.Load())
+			})
+		})
+
+		t.Run("WHEN a POST allows unsafe retries", func(t *testing.T) {
+			calls.Store(0)
+			_, _, err := api.WithQuery("status", "500").HttpPost(context.Background(), []byte(`{}`), AllowUnsafeRetries())
+			require.NoError(t, err)
+
+			t.Run("THEN it is retried", func(t *testing.T) {
+				assert.EqualValues(t, 3, calls.Load())
+			})
+		})
+	})
+
+	t.Run("GIVEN a closed server", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		url, err := url.Parse(ts.URL)
+		require.NoError(t, err)
+		ts.Close()
+		api := NewHttpRequest(HttpRequestOptions{URL: url, RetriesMax: 3, RetriesWait: time.Millisecond, SafeRetries: true})
+
+		t.Run("WHEN a POST is refused", func(t *testing.T) {
+			resp, err := api.HttpPostFull(context.Background(), []byte(`{}`))
+
+			t.Run("THEN it is retried since it was never sent", func(t *testing.T) {
+				require.Error(t, err)
+				assert.Equal(t, 3, resp.Attempts)
+			})
+		})
+	})
+}
+
+func TestIntegration_SafeRetriesFallback(t *testing.T) {
+
+	t.Run("GIVEN a primary that drops connections AND a healthy mirror", func(t *testing.T) {
+		primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			conn, _, err := w.(http.Hijacker).Hijack()
+			if err == nil {
+				conn.Close()
+			}
+		}))
+		defer primary.Close()
+		var mirrorCalls atomic.Int32
+		mirror := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mirrorCalls.Add(1)
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer mirror.Close()
+
+		primaryURL, err := url.Parse(primary.URL)
+		require.NoError(t, err)
+		mirrorURL, err := url.Parse(mirror.URL)
+		require.NoError(t, err)
+		api := NewHttpRequest(HttpRequestOptions{
+			URL:                 primaryURL,
+			FallbackURLs:        []*url.URL{mirrorURL},
+			FallbackStatusCodes: []int{http.StatusInternalServerError},
+			RetriesMax:          3,
+			RetriesWait:         time.Millisecond,
+			SafeRetries:         true,
+		})
+
+		t.Run("WHEN the connection drops after a POST was sent", func(t *testing.T) {
+			resp, err := api.HttpPostFull(context.Background(), []byte(`{}`))
+
+			t.Run("THEN it isn't sent to the mirror", func(t *testing.T) {
+				require.Error(t, err)
+				assert.Equal(t, 1, resp.Attempts)
+				assert.EqualValues(t, 0, mirrorCalls.Load())
+			})
+		})
+
+		t.Run("WHEN the connection drops after a GET was sent", func(t *testing.T) {
+			_, status, err := api.HttpGet(context.Background())
+
+			t.Run("THEN it falls back to the mirror", func(t *testing.T) {
+				require.NoError(t, err)
+				assert.Equal(t, http.StatusOK, status)
+				assert.EqualValues(t, 1, mirrorCalls.Load())
+			})
+		})
+	})
+}
@@ -0,0 +1,74 @@
+package httpretry
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// Rand draws the random waits of ExponentialJitterBackoff and the faults of
+// NewFaultInjector.  A Rand created from a seed draws the same sequence every
+// time, so a test can assert the exact waits and an incident can be replayed
+// with the seed logged by the service.  It is safe for concurrent use.
+type Rand struct {
+	mu   sync.Mutex
+	seed int64
+	rand *rand.Rand
+}
+
+// NewRand returns a Rand drawing the sequence of seed.
+func NewRand(seed int64) *Rand {
+	return &Rand{seed: seed, rand: rand.New(rand.NewSource(seed))}
+}
+
+// Seed returns the seed the Rand was created from.
+func (r *Rand) Seed() int64 {
+	return r.seed
+}
+
+// Int63n returns a number in [0, n), n must be positive.
+func (r *Rand) Int63n(n int64) int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.rand.Int63n(n)
+}
+
+// Float64 returns a number in [0.0, 1.0).
+func (r *Rand) Float64() float64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.rand.Float64()
+}
+
+var (
+	defaultRandMu sync.RWMutex
+	defaultRand   = NewRand(time.Now().UnixNano())
+)
+
+// JitterSeed returns the seed of the Rand used when none is set, log it at
+// startup to replay the jitter of an incident with SetJitterSeed.
+func JitterSeed() int64 {
+	return getDefaultRand().Seed()
+}
+
+// SetJitterSeed replaces the Rand used when none is set by one drawing the
+// sequence of seed.
+func SetJitterSeed(seed int64) {
+	defaultRandMu.Lock()
+	defer defaultRandMu.Unlock()
+	defaultRand = NewRand(seed)
+}
+
+func getDefaultRand() *Rand {
+	defaultRandMu.RLock()
+	defer defaultRandMu.RUnlock()
+	return defaultRand
+}
+
+// orDefaultRand returns r unless it is nil.
+func orDefaultRand(r *Rand) *Rand {
+	if r != nil {
+		return r
+	}
+	return getDefaultRand()
+}
@@ -0,0 +1,41 @@
+package httpretry
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJitterRand(t *testing.T) {
+
+	t.Run("GIVEN two jittered backoffs seeded alike", func(t *testing.T) {
+		a := ExponentialJitterBackoff{Base: 100 * time.Millisecond, Max: 10 * time.Second, Rand: NewRand(42)}
+		b := ExponentialJitterBackoff{Base: 100 * time.Millisecond, Max: 10 * time.Second, Rand: NewRand(42)}
+
+		t.Run("THEN they wait the same sequence", func(t *testing.T) {
+			for retryCount := 1; retryCount <= 5; retryCount++ {
+				assert.Equal(t, a.Backoff(retryCount), b.Backoff(retryCount))
+			}
+		})
+	})
+
+	t.Run("GIVEN the package seed is set", func(t *testing.T) {
+		seed := JitterSeed()
+		defer SetJitterSeed(seed)
+		b := ExponentialJitterBackoff{Base: 100 * time.Millisecond, Max: 10 * time.Second}
+		draw := func() []time.Duration {
+			SetJitterSeed(7)
+			waits := make([]time.Duration, 5)
+			for i := range waits {
+				waits[i] = b.Backoff(i + 1)
+			}
+			return waits
+		}
+
+		t.Run("THEN the waits are replayed", func(t *testing.T) {
+			assert.Equal(t, draw(), draw())
+			assert.Equal(t, int64(7), JitterSeed())
+		})
+	})
+}
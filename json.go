@@ -0,0 +1,69 @@
+package httpretry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// DecodeError is returned by the JSON helpers when a successful response body
+// can't be unmarshalled into the response object.
+type DecodeError struct {
+	StatusCode int
+	Body       []byte
+	Err        error
+}
+
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("decoding %d response: %v", e.StatusCode, e.Err)
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// DoJSON marshals requestObj as the body, unless it is nil, and unmarshals a
+// 2xx response body into responseObj, unless it is nil or the body is empty.
+// The raw body and status are returned like Do so non-2xx responses can be
+// inspected, only 2xx bodies are decoded.
+func (r httpRequest) DoJSON(ctx context.Context, method string, requestObj any, responseObj any, opts ...CallOption) ([]byte, int, error) {
+	var object []byte
+	if requestObj != nil {
+		var err error
+		object, err = json.Marshal(requestObj)
+		if err != nil {
+			return []byte(""), 0, err
+		}
+	}
+
+	r.Header = r.Header.Clone()
+	r.Header.Set("Content-Type", "application/json")
+
+	respBody, code, err := r.Do(ctx, method, object, opts...)
+	if err != nil {
+		return respBody, code, err
+	}
+	if responseObj != nil && code >= 200 && code < 300 && len(respBody) > 0 {
+		if err := json.Unmarshal(respBody, responseObj); err != nil {
+			return respBody, code, &DecodeError{StatusCode: code, Body: respBody, Err: err}
+		}
+	}
+	return respBody, code, nil
+}
+
+func (r httpRequest) GetJSON(ctx context.Context, responseObj any, opts ...CallOption) ([]byte, int, error) {
+	return r.DoJSON(ctx, http.MethodGet, nil, responseObj, opts...)
+}
+
+func (r httpRequest) PostJSON(ctx context.Context, requestObj any, responseObj any, opts ...CallOption) ([]byte, int, error) {
+	return r.DoJSON(ctx, http.MethodPost, requestObj, responseObj, opts...)
+}
+
+func (r httpRequest) PutJSON(ctx context.Context, requestObj any, responseObj any, opts ...CallOption) ([]byte, int, error) {
+	return r.DoJSON(ctx, http.MethodPut, requestObj, responseObj, opts...)
+}
+
+func (r httpRequest) PatchJSON(ctx context.Context, requestObj any, responseObj any, opts ...CallOption) ([]byte, int, error) {
+	return r.DoJSON(ctx, http.MethodPatch, requestObj, responseObj, opts...)
+}
@@ -0,0 +1,74 @@
+package httpretry
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testThing struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+func TestIntegration_JSON(t *testing.T) {
+
+	t.Run("GIVEN a server that assigns an ID to posted things", func(t *testing.T) {
+		var contentType string
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			contentType = r.Header.Get("Content-Type")
+			switch r.URL.Path {
+			case "/broken":
+				w.Write([]byte("not json"))
+			default:
+				var thing testThing
+				require.NoError(t, json.NewDecoder(r.Body).Decode(&thing))
+				thing.ID = "42"
+				w.WriteHeader(http.StatusCreated)
+				json.NewEncoder(w).Encode(thing)
+			}
+		}))
+		defer ts.Close()
+
+		url, err := url.Parse(ts.URL)
+		require.NoError(t, err)
+		api := NewHttpRequest(HttpRequestOptions{URL: url, JSONAPIHeaders: true})
+
+		t.Run("WHEN PostJSON is sent", func(t *testing.T) {
+			var created testThing
+			_, code, err := api.PostJSON(context.Background(), testThing{Name: "widget"}, &created)
+			require.NoError(t, err)
+
+			t.Run("THEN the response is unmarshalled", func(t *testing.T) {
+				assert.Equal(t, http.StatusCreated, code)
+				assert.Equal(t, testThing{ID: "42", Name: "widget"}, created)
+				assert.Equal(t, "application/json", contentType)
+			})
+
+			t.Run("THEN the configured headers are untouched", func(t *testing.T) {
+				assert.Equal(t, "application/vnd.api+json", api.Header.Get("Content-Type"))
+			})
+		})
+
+		t.Run("WHEN GetJSON receives an invalid body", func(t *testing.T) {
+			broken := NewHttpRequest(HttpRequestOptions{URL: url.JoinPath("broken")})
+			var thing testThing
+			body, code, err := broken.GetJSON(context.Background(), &thing)
+
+			t.Run("THEN a DecodeError is returned", func(t *testing.T) {
+				var decodeErr *DecodeError
+				require.ErrorAs(t, err, &decodeErr)
+				assert.Equal(t, http.StatusOK, decodeErr.StatusCode)
+				assert.Equal(t, "not json", string(decodeErr.Body))
+				assert.Equal(t, http.StatusOK, code)
+				assert.Equal(t, "not json", string(body))
+			})
+		})
+	})
+}
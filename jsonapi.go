@@ -0,0 +1,84 @@
+package httpretry
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// APIError is an error object of a JSON:API document.
+//
+// See https://jsonapi.org/format/#error-objects
+type APIError struct {
+	ID     string         `json:"id,omitempty"`
+	Status string         `json:"status,omitempty"`
+	Code   string         `json:"code,omitempty"`
+	Title  string         `json:"title,omitempty"`
+	Detail string         `json:"detail,omitempty"`
+	Source APIErrorSource `json:"source"`
+	Meta   map[string]any `json:"meta,omitempty"`
+}
+
+// APIErrorSource points at the part of the request causing an APIError.
+type APIErrorSource struct {
+	// Pointer is a JSON Pointer into the request document, for example
+	// /data/attributes/name
+	Pointer   string `json:"pointer,omitempty"`
+	Parameter string `json:"parameter,omitempty"`
+	Header    string `json:"header,omitempty"`
+}
+
+func (e APIError) Error() string {
+	var b strings.Builder
+	for _, part := range []string{e.Status, e.Code} {
+		if part != "" {
+			b.WriteString(part)
+			b.WriteString(" ")
+		}
+	}
+	message := e.Detail
+	if message == "" {
+		message = e.Title
+	}
+	b.WriteString(message)
+	if e.Source.Pointer != "" {
+		b.WriteString(" (" + e.Source.Pointer + ")")
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// APIErrors are the errors of a JSON:API error document, they are wrapped
+// in the errors helpers return for a non 2xx response:
+//
+//	var apiErrors httpretry.APIErrors
+//	if errors.As(err, &apiErrors) {
+//		for _, e := range apiErrors {
+//			log.Printf("%s: %s", e.Source.Pointer, e.Detail)
+//		}
+//	}
+type APIErrors []APIError
+
+func (e APIErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+	return "jsonapi: " + strings.Join(messages, "; ")
+}
+
+// ParseAPIErrors returns the errors of a JSON:API error document, nil when
+// body isn't one.
+func ParseAPIErrors(body []byte) APIErrors {
+	var document struct {
+		Errors APIErrors `json:"errors"`
+	}
+	if len(body) == 0 || json.Unmarshal(body, &document) != nil || len(document.Errors) == 0 {
+		return nil
+	}
+	return document.Errors
+}
+
+// APIErrors returns the JSON:API errors of the response body, nil when it
+// has none.
+func (r *Response) APIErrors() APIErrors {
+	return ParseAPIErrors(r.Body)
+}
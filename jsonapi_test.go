@@ -0,0 +1,77 @@
+package httpretry
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAPIErrors(t *testing.T) {
+
+	t.Run("GIVEN a JSON:API error document", func(t *testing.T) {
+		body := []byte(`{"errors":[
+			{"status":"422","code":"invalid","title":"Invalid attribute","detail":"Name is too long","source":{"pointer":"/data/attributes/name"}},
+			{"status":"422","title":"Missing attribute"}
+		]}`)
+
+		t.Run("WHEN it is parsed", func(t *testing.T) {
+			apiErrors := ParseAPIErrors(body)
+
+			t.Run("THEN every error object is returned", func(t *testing.T) {
+				require.Len(t, apiErrors, 2)
+				assert.Equal(t, "invalid", apiErrors[0].Code)
+				assert.Equal(t, "/data/attributes/name", apiErrors[0].Source.Pointer)
+				assert.Equal(t, "Missing attribute", apiErrors[1].Title)
+			})
+
+			t.Run("AND the message lists them", func(t *testing.T) {
+				assert.Equal(t, "jsonapi: 422 invalid Name is too long (/data/attributes/name); 422 Missing attribute", apiErrors.Error())
+			})
+		})
+	})
+
+	t.Run("GIVEN bodies without errors", func(t *testing.T) {
+		t.Run("THEN nothing is returned", func(t *testing.T) {
+			assert.Nil(t, ParseAPIErrors(nil))
+			assert.Nil(t, ParseAPIErrors([]byte(`{"data":{"id":"1"}}`)))
+			assert.Nil(t, ParseAPIErrors([]byte(`<html>oops</html>`)))
+		})
+	})
+}
+
+func TestIntegration_APIErrors(t *testing.T) {
+
+	t.Run("GIVEN a JSON:API server rejecting a document", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/vnd.api+json")
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			w.Write([]byte(`{"errors":[{"status":"422","detail":"Name is too long"}]}`))
+		}))
+		defer ts.Close()
+
+		url, err := url.Parse(ts.URL)
+		require.NoError(t, err)
+		api := NewHttpRequest(HttpRequestOptions{URL: url, JSONAPIHeaders: true})
+
+		t.Run("WHEN a helper fails on the status", func(t *testing.T) {
+			_, resp, err := api.CreatedLocation(context.Background(), []byte(`{"data":{}}`))
+
+			t.Run("THEN the error wraps the APIErrors", func(t *testing.T) {
+				var apiErrors APIErrors
+				require.True(t, errors.As(err, &apiErrors))
+				assert.Equal(t, "Name is too long", apiErrors[0].Detail)
+				assert.Contains(t, err.Error(), "actual: 422")
+			})
+
+			t.Run("AND they are available from the Response", func(t *testing.T) {
+				assert.Equal(t, "422", resp.APIErrors()[0].Status)
+			})
+		})
+	})
+}
@@ -0,0 +1,30 @@
+package httpretry
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// BodyTooLargeError is returned when a response body exceeds
+// HttpRequestOptions.MaxResponseBytes.
+type BodyTooLargeError struct {
+	Limit      int64
+	StatusCode int
+}
+
+func (e *BodyTooLargeError) Error() string {
+	return fmt.Sprintf("%d response body larger than %d bytes", e.StatusCode, e.Limit)
+}
+
+func isBodyTooLarge(err error) bool {
+	var tooLarge *BodyTooLargeError
+	return errors.As(err, &tooLarge)
+}
+
+// limitedBody reads at most the limit of its reader and closes the original
+// body.
+type limitedBody struct {
+	io.Reader
+	io.Closer
+}
@@ -0,0 +1,63 @@
+package httpretry
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntegration_MaxResponseBytes(t *testing.T) {
+
+	t.Run("GIVEN a server returning a 100 byte body", func(t *testing.T) {
+		calls := 0
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			w.Write([]byte(strings.Repeat("a", 100)))
+		}))
+		defer ts.Close()
+
+		u, err := url.Parse(ts.URL)
+		require.NoError(t, err)
+
+		t.Run("WHEN HttpGet is sent with a limit of 10 bytes", func(t *testing.T) {
+			api := NewHttpRequest(HttpRequestOptions{URL: u, MaxResponseBytes: 10})
+			_, status, err := api.HttpGet(context.Background())
+
+			t.Run("THEN it fails with BodyTooLargeError without retrying", func(t *testing.T) {
+				var tooLarge *BodyTooLargeError
+				require.True(t, errors.As(err, &tooLarge))
+				assert.Equal(t, int64(10), tooLarge.Limit)
+				assert.Equal(t, http.StatusOK, tooLarge.StatusCode)
+				assert.Equal(t, http.StatusOK, status)
+				assert.Equal(t, 1, calls)
+			})
+		})
+
+		t.Run("WHEN HttpGet is sent with a limit of 10 bytes AND TruncateResponse", func(t *testing.T) {
+			api := NewHttpRequest(HttpRequestOptions{URL: u, MaxResponseBytes: 10, TruncateResponse: true})
+			body, _, err := api.HttpGet(context.Background())
+			require.NoError(t, err)
+
+			t.Run("THEN the body is truncated", func(t *testing.T) {
+				assert.Equal(t, strings.Repeat("a", 10), string(body))
+			})
+		})
+
+		t.Run("WHEN HttpGet is sent with a limit of exactly the body size", func(t *testing.T) {
+			api := NewHttpRequest(HttpRequestOptions{URL: u, MaxResponseBytes: 100})
+			body, _, err := api.HttpGet(context.Background())
+			require.NoError(t, err)
+
+			t.Run("THEN the whole body is returned", func(t *testing.T) {
+				assert.Len(t, body, 100)
+			})
+		})
+	})
+}
@@ -0,0 +1,209 @@
+package httpretry
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Logger receives the log lines of this package.  *logrus.Logger,
+// *logrus.Entry and zap's *zap.SugaredLogger implement it as is, see
+// NewSlogLogger for log/slog.
+type Logger interface {
+	Debugf(format string, args ...interface{})
+	Infof(format string, args ...interface{})
+	Warnf(format string, args ...interface{})
+	Errorf(format string, args ...interface{})
+}
+
+// Fields are the structured context of a log line, for example the method,
+// host, status and attempt of a retry.
+type Fields map[string]interface{}
+
+// LogLevel overrides the level of the retry log lines.
+type LogLevel int
+
+const (
+	// LogLevelDefault keeps the level of each line: info for retried
+	// responses, warn for failed attempts and give ups.
+	LogLevelDefault LogLevel = iota
+	LogLevelDebug
+	LogLevelInfo
+	LogLevelWarn
+	LogLevelError
+	// LogLevelOff silences the retry log lines.
+	LogLevelOff
+)
+
+// ParseLogLevel parses the names of the levels: default, debug, info, warn,
+// error and off, case-insensitively.
+func ParseLogLevel(name string) (LogLevel, error) {
+	switch strings.ToLower(name) {
+	case "", "default":
+		return LogLevelDefault, nil
+	case "debug":
+		return LogLevelDebug, nil
+	case "info":
+		return LogLevelInfo, nil
+	case "warn", "warning":
+		return LogLevelWarn, nil
+	case "error":
+		return LogLevelError, nil
+	case "off":
+		return LogLevelOff, nil
+	}
+	return LogLevelDefault, fmt.Errorf("unknown log level %q", name)
+}
+
+var (
+	loggerMu      sync.RWMutex
+	defaultLogger Logger = logrus.StandardLogger()
+	retryLogLevel LogLevel
+)
+
+// SetLogger replaces the package logger used by requests without a Logger
+// option.  It defaults to the standard logrus logger, pass NopLogger{} to
+// silence the package.
+func SetLogger(logger Logger) {
+	loggerMu.Lock()
+	defer loggerMu.Unlock()
+	if logger == nil {
+		logger = NopLogger{}
+	}
+	defaultLogger = logger
+}
+
+func getLogger() Logger {
+	loggerMu.RLock()
+	defer loggerMu.RUnlock()
+	return defaultLogger
+}
+
+// SetRetryLogLevel sets the level of the retry log lines of requests without
+// a RetryLogLevel option, for example LogLevelDebug to keep expected retries
+// out of production logs or LogLevelOff to silence them.
+func SetRetryLogLevel(level LogLevel) {
+	loggerMu.Lock()
+	defer loggerMu.Unlock()
+	retryLogLevel = level
+}
+
+func getRetryLogLevel() LogLevel {
+	loggerMu.RLock()
+	defer loggerMu.RUnlock()
+	return retryLogLevel
+}
+
+// NopLogger discards everything.
+type NopLogger struct{}
+
+func (NopLogger) Debugf(format string, args ...interface{}) {}
+func (NopLogger) Infof(format string, args ...interface{})  {}
+func (NopLogger) Warnf(format string, args ...interface{})  {}
+func (NopLogger) Errorf(format string, args ...interface{}) {}
+
+// NewLogrusLogger logs through a logrus logger or entry other than the
+// standard one, for example an entry carrying tenant fields.
+func NewLogrusLogger(logger logrus.FieldLogger) Logger {
+	return logger
+}
+
+func (r httpRequest) logger() Logger {
+	if r.Logger != nil {
+		return r.Logger
+	}
+	return getLogger()
+}
+
+// logRetry logs a line of the retry loop at level unless RetryLogLevel or
+// SetRetryLogLevel override it.
+func (r httpRequest) logRetry(level LogLevel, msg string, fields Fields) {
+	override := r.RetryLogLevel
+	if override == LogLevelDefault {
+		override = getRetryLogLevel()
+	}
+	if override != LogLevelDefault {
+		level = override
+	}
+	logWithFields(r.logger(), level, msg, fields)
+}
+
+// fieldsLogger is implemented by loggers with native structured fields.
+type fieldsLogger interface {
+	logFields(level LogLevel, msg string, fields Fields)
+}
+
+// logWithFields passes fields to logrus loggers and entries with WithFields,
+// other loggers get them appended to msg as key=value pairs.
+func logWithFields(logger Logger, level LogLevel, msg string, fields Fields) {
+	switch l := logger.(type) {
+	case fieldsLogger:
+		l.logFields(level, msg, fields)
+		return
+	case logrus.FieldLogger:
+		entry := l.WithFields(logrus.Fields(fields))
+		switch level {
+		case LogLevelDebug:
+			entry.Debug(msg)
+		case LogLevelInfo:
+			entry.Info(msg)
+		case LogLevelWarn:
+			entry.Warn(msg)
+		case LogLevelError:
+			entry.Error(msg)
+		}
+		return
+	}
+
+	line := formatFields(msg, fields)
+	switch level {
+	case LogLevelDebug:
+		logger.Debugf("%s", line)
+	case LogLevelInfo:
+		logger.Infof("%s", line)
+	case LogLevelWarn:
+		logger.Warnf("%s", line)
+	case LogLevelError:
+		logger.Errorf("%s", line)
+	}
+}
+
+func formatFields(msg string, fields Fields) string {
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	b.WriteString(msg)
+	for _, key := range keys {
+		fmt.Fprintf(&b, " %s=%v", key, fields[key])
+	}
+	return b.String()
+}
+
+// attemptFields are the fields of the retry log lines of an attempt.
+func attemptFields(ctx context.Context, req *http.Request, resp *http.Response, err error, retryCount int) Fields {
+	fields := Fields{
+		"method":  req.Method,
+		"host":    req.URL.Host,
+		"path":    req.URL.Path,
+		"attempt": retryCount,
+	}
+	if resp != nil {
+		fields["status"] = resp.StatusCode
+	}
+	if err != nil {
+		fields["error"] = err
+	}
+	if id := RequestIDFromContext(ctx); id != "" {
+		fields["request_id"] = id
+	}
+	return fields
+}
@@ -0,0 +1,67 @@
+//go:build go1.21
+
+package httpretry
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+)
+
+type slogLogger struct {
+	logger *slog.Logger
+}
+
+// NewSlogLogger logs through a log/slog logger.
+func NewSlogLogger(logger *slog.Logger) Logger {
+	return slogLogger{logger: logger}
+}
+
+func (l slogLogger) Debugf(format string, args ...interface{}) {
+	l.log(slog.LevelDebug, format, args...)
+}
+
+func (l slogLogger) Infof(format string, args ...interface{}) {
+	l.log(slog.LevelInfo, format, args...)
+}
+
+func (l slogLogger) Warnf(format string, args ...interface{}) {
+	l.log(slog.LevelWarn, format, args...)
+}
+
+func (l slogLogger) Errorf(format string, args ...interface{}) {
+	l.log(slog.LevelError, format, args...)
+}
+
+func (l slogLogger) log(level slog.Level, format string, args ...interface{}) {
+	ctx := context.Background()
+	if !l.logger.Enabled(ctx, level) {
+		return
+	}
+	l.logger.Log(ctx, level, fmt.Sprintf(format, args...))
+}
+
+func (l slogLogger) logFields(level LogLevel, msg string, fields Fields) {
+	var slogLevel slog.Level
+	switch level {
+	case LogLevelDebug:
+		slogLevel = slog.LevelDebug
+	case LogLevelInfo:
+		slogLevel = slog.LevelInfo
+	case LogLevelWarn:
+		slogLevel = slog.LevelWarn
+	case LogLevelError:
+		slogLevel = slog.LevelError
+	default:
+		return
+	}
+	ctx := context.Background()
+	if !l.logger.Enabled(ctx, slogLevel) {
+		return
+	}
+	attrs := make([]any, 0, 2*len(fields))
+	for key, value := range fields {
+		attrs = append(attrs, key, value)
+	}
+	l.logger.Log(ctx, slogLevel, msg, attrs...)
+}
@@ -0,0 +1,30 @@
+//go:build go1.21
+
+package httpretry
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSlogLogger(t *testing.T) {
+
+	t.Run("GIVEN a slog logger at info level", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger := NewSlogLogger(slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelInfo})))
+
+		t.Run("WHEN messages are logged", func(t *testing.T) {
+			logger.Debugf("hidden %d", 1)
+			logger.Warnf("retry %d", 2)
+
+			t.Run("THEN they are formatted and filtered by level", func(t *testing.T) {
+				assert.NotContains(t, buf.String(), "hidden")
+				assert.Contains(t, buf.String(), "level=WARN")
+				assert.Contains(t, buf.String(), `msg="retry 2"`)
+			})
+		})
+	})
+}
@@ -0,0 +1,194 @@
+package httpretry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testLogger struct {
+	mu    sync.Mutex
+	lines []string
+}
+
+func (l *testLogger) log(level string, format string, args ...interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.lines = append(l.lines, level+": "+fmt.Sprintf(format, args...))
+}
+
+func (l *testLogger) Debugf(format string, args ...interface{}) { l.log("debug", format, args...) }
+func (l *testLogger) Infof(format string, args ...interface{})  { l.log("info", format, args...) }
+func (l *testLogger) Warnf(format string, args ...interface{})  { l.log("warn", format, args...) }
+func (l *testLogger) Errorf(format string, args ...interface{}) { l.log("error", format, args...) }
+
+func (l *testLogger) contains(prefix string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, line := range l.lines {
+		if len(line) >= len(prefix) && line[:len(prefix)] == prefix {
+			return true
+		}
+	}
+	return false
+}
+
+func TestIntegration_Logger(t *testing.T) {
+
+	t.Run("GIVEN a server that returns 503 once", func(t *testing.T) {
+		attempts := 1
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if attempts > 0 {
+				attempts--
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer ts.Close()
+
+		url, err := url.Parse(ts.URL)
+		require.NoError(t, err)
+
+		t.Run("AND http request with its own logger", func(t *testing.T) {
+			logger := &testLogger{}
+			api := NewHttpRequest(HttpRequestOptions{
+				URL:         url,
+				RetriesWait: time.Millisecond,
+				Logger:      logger,
+				IsRetryCondition: func(resp *http.Response, retryCount int) bool {
+					return resp.StatusCode == http.StatusServiceUnavailable
+				},
+			})
+
+			t.Run("WHEN HttpGet is sent", func(t *testing.T) {
+				_, _, err := api.HttpGet(context.Background())
+				require.NoError(t, err)
+
+				t.Run("THEN retries and dumps are logged to it", func(t *testing.T) {
+					assert.True(t, logger.contains("info: Request"))
+					assert.True(t, logger.contains("debug: Request"))
+					assert.True(t, logger.contains("debug: Response"))
+				})
+			})
+		})
+	})
+}
+
+func TestIntegration_RetryLogFields(t *testing.T) {
+
+	t.Run("GIVEN a server that returns 503 once", func(t *testing.T) {
+		var mu sync.Mutex
+		attempts := 1
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			defer mu.Unlock()
+			if attempts > 0 {
+				attempts--
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}
+		}))
+		defer ts.Close()
+
+		url, err := url.Parse(ts.URL + "/things")
+		require.NoError(t, err)
+		reset := func() {
+			mu.Lock()
+			defer mu.Unlock()
+			attempts = 1
+		}
+
+		t.Run("WHEN the logger is a logrus logger", func(t *testing.T) {
+			var buf bytes.Buffer
+			logger := logrus.New()
+			logger.SetOutput(&buf)
+			logger.SetFormatter(&logrus.JSONFormatter{})
+			api := NewHttpRequest(HttpRequestOptions{
+				URL:              url,
+				RetriesWait:      time.Millisecond,
+				Logger:           logger,
+				IsRetryCondition: RetryOnStatus(http.StatusServiceUnavailable),
+			})
+			_, _, err := api.HttpGet(context.WithValue(context.Background(), RequestIDKey, "req-1"))
+			require.NoError(t, err)
+
+			t.Run("THEN the retry is logged with structured fields", func(t *testing.T) {
+				var line map[string]interface{}
+				require.NoError(t, json.Unmarshal(bytes.SplitN(buf.Bytes(), []byte("\n"), 2)[0], &line))
+				assert.Equal(t, "info", line["level"])
+				assert.Equal(t, "Request IsRetryCondition returned true", line["msg"])
+				assert.Equal(t, "GET", line["method"])
+				assert.Equal(t, url.Host, line["host"])
+				assert.Equal(t, "/things", line["path"])
+				assert.Equal(t, float64(http.StatusServiceUnavailable), line["status"])
+				assert.Equal(t, float64(1), line["attempt"])
+				assert.Equal(t, "req-1", line["request_id"])
+			})
+		})
+
+		t.Run("WHEN the logger has no structured fields", func(t *testing.T) {
+			reset()
+			logger := &testLogger{}
+			api := NewHttpRequest(HttpRequestOptions{
+				URL:              url,
+				RetriesWait:      time.Millisecond,
+				Logger:           logger,
+				IsRetryCondition: RetryOnStatus(http.StatusServiceUnavailable),
+			})
+			_, _, err := api.HttpGet(context.Background())
+			require.NoError(t, err)
+
+			t.Run("THEN the fields are appended as key=value pairs", func(t *testing.T) {
+				assert.True(t, logger.contains(fmt.Sprintf("info: Request IsRetryCondition returned true attempt=1 host=%s method=GET path=/things request_id=", url.Host)))
+			})
+		})
+
+		t.Run("WHEN RetryLogLevel is LogLevelOff", func(t *testing.T) {
+			reset()
+			logger := &testLogger{}
+			api := NewHttpRequest(HttpRequestOptions{
+				URL:              url,
+				RetriesWait:      time.Millisecond,
+				Logger:           logger,
+				RetryLogLevel:    LogLevelOff,
+				IsRetryCondition: RetryOnStatus(http.StatusServiceUnavailable),
+			})
+			_, _, err := api.HttpGet(context.Background())
+			require.NoError(t, err)
+
+			t.Run("THEN the retry is not logged", func(t *testing.T) {
+				assert.False(t, logger.contains("info: Request IsRetryCondition"))
+			})
+		})
+
+		t.Run("WHEN SetRetryLogLevel elevates retries to warn", func(t *testing.T) {
+			reset()
+			SetRetryLogLevel(LogLevelWarn)
+			defer SetRetryLogLevel(LogLevelDefault)
+			logger := &testLogger{}
+			api := NewHttpRequest(HttpRequestOptions{
+				URL:              url,
+				RetriesWait:      time.Millisecond,
+				Logger:           logger,
+				IsRetryCondition: RetryOnStatus(http.StatusServiceUnavailable),
+			})
+			_, _, err := api.HttpGet(context.Background())
+			require.NoError(t, err)
+
+			t.Run("THEN the retry is logged as a warning", func(t *testing.T) {
+				assert.True(t, logger.contains("warn: Request IsRetryCondition"))
+			})
+		})
+	})
+}
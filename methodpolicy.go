@@ -0,0 +1,39 @@
+package httpretry
+
+import (
+	"strings"
+)
+
+// MethodPolicy overrides the retry settings of the calls with a method, see
+// HttpRequestOptions.MethodPolicies.  Zero fields keep the settings of the
+// request.
+type MethodPolicy struct {
+	// RetriesMax max number of attempts, NoRetries for a single attempt
+	RetriesMax int
+
+	Backoff          BackoffStrategy
+	IsRetryCondition RetryPredicate
+	IsRetryError     RetryErrorPredicate
+}
+
+// withMethodPolicy returns a copy of the request with the MethodPolicies of
+// method applied.
+func (r httpRequest) withMethodPolicy(method string) httpRequest {
+	policy, ok := r.MethodPolicies[strings.ToUpper(method)]
+	if !ok {
+		return r
+	}
+	if policy.RetriesMax != 0 {
+		r.RetriesMax = policy.RetriesMax
+	}
+	if policy.Backoff != nil {
+		r.Backoff = policy.Backoff
+	}
+	if policy.IsRetryCondition != nil {
+		r.IsRetryCondition = policy.IsRetryCondition
+	}
+	if policy.IsRetryError != nil {
+		r.IsRetryError = policy.IsRetryError
+	}
+	return r
+}
@@ -0,0 +1,82 @@
+package httpretry
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/mandric/httpretry/httpretrytest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntegration_MethodPolicies(t *testing.T) {
+
+	t.Run("GIVEN a server always failing AND a single retry for POST", func(t *testing.T) {
+		attempts := map[string]int{}
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			attempts[r.Method]++
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer ts.Close()
+
+		url, err := url.Parse(ts.URL)
+		require.NoError(t, err)
+		api := NewHttpRequest(HttpRequestOptions{
+			URL:        url,
+			RetriesMax: 5,
+			Clock:      httpretrytest.NewFakeClock(time.Now()),
+			MethodPolicies: map[string]MethodPolicy{
+				http.MethodPost: {RetriesMax: 2},
+			},
+		})
+
+		t.Run("WHEN a GET is sent", func(t *testing.T) {
+			resp, err := api.DoFull(context.Background(), http.MethodGet, nil)
+			require.NoError(t, err)
+
+			t.Run("THEN it is retried RetriesMax times", func(t *testing.T) {
+				assert.Equal(t, 5, resp.Attempts)
+				assert.Equal(t, 5, attempts[http.MethodGet])
+			})
+		})
+
+		t.Run("WHEN a POST is sent", func(t *testing.T) {
+			resp, err := api.DoFull(context.Background(), http.MethodPost, []byte(`{}`))
+			require.NoError(t, err)
+
+			t.Run("THEN the POST policy limits the attempts", func(t *testing.T) {
+				assert.Equal(t, 2, resp.Attempts)
+				assert.Equal(t, 2, attempts[http.MethodPost])
+			})
+		})
+	})
+
+	t.Run("GIVEN a policy retrying only 429 for DELETE", func(t *testing.T) {
+		ts := httpretrytest.NewFlakyServer(3, http.StatusServiceUnavailable)
+		defer ts.Close()
+
+		url, err := url.Parse(ts.URL)
+		require.NoError(t, err)
+		api := NewHttpRequest(HttpRequestOptions{
+			URL:   url,
+			Clock: httpretrytest.NewFakeClock(time.Now()),
+			MethodPolicies: map[string]MethodPolicy{
+				http.MethodDelete: {IsRetryCondition: RetryOnStatus(http.StatusTooManyRequests)},
+			},
+		})
+
+		t.Run("WHEN a DELETE fails with 503", func(t *testing.T) {
+			resp, err := api.DoFull(context.Background(), http.MethodDelete, nil)
+			require.NoError(t, err)
+
+			t.Run("THEN it isn't retried", func(t *testing.T) {
+				assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+				assert.Equal(t, 1, resp.Attempts)
+			})
+		})
+	})
+}
@@ -0,0 +1,31 @@
+package httpretry
+
+import (
+	"strconv"
+	"time"
+)
+
+// MetricsCollector receives request statistics from the retry loop.  method
+// and host label every observation so dashboards can be split per upstream.
+// Implementations must be safe for concurrent use.
+type MetricsCollector interface {
+	// ObserveAttempt is called after every attempt, statusCode is 0 when the
+	// attempt failed with an error.
+	ObserveAttempt(method string, host string, statusCode int, err error, duration time.Duration)
+
+	// ObserveRetry is called every time an attempt is going to be retried.
+	ObserveRetry(method string, host string)
+
+	// ObserveCall is called once per call with the final outcome and the
+	// total duration including waits between retries.
+	ObserveCall(method string, host string, statusCode int, err error, duration time.Duration)
+}
+
+// StatusClass groups status codes into "2xx", "4xx", etc.  Failed attempts
+// without a response are reported as "error".
+func StatusClass(statusCode int, err error) string {
+	if err != nil || statusCode < 100 || statusCode > 599 {
+		return "error"
+	}
+	return strconv.Itoa(statusCode/100) + "xx"
+}
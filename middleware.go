@@ -0,0 +1,42 @@
+package httpretry
+
+import (
+	"net/http"
+)
+
+// Doer sends a single attempt, *http.Client implements it.
+type Doer interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// DoerFunc adapts a function to Doer.
+type DoerFunc func(req *http.Request) (*http.Response, error)
+
+func (f DoerFunc) Do(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// Middleware wraps every attempt, for example to sign, trace or audit
+// requests without changing the retry loop:
+//
+//	func signing(next httpretry.Doer) httpretry.Doer {
+//		return httpretry.DoerFunc(func(req *http.Request) (*http.Response, error) {
+//			req.Header.Set("X-Signature", sign(req))
+//			return next.Do(req)
+//		})
+//	}
+//
+// A middleware is called again for every retry so timestamps and signatures
+// are fresh.  An error returned by a middleware is handled like a transport
+// error, see IsRetryError.
+type Middleware func(next Doer) Doer
+
+// chain wraps client with the middleware, the first middleware is the
+// outermost and sees the request first.
+func (r httpRequest) chain(client *http.Client) Doer {
+	var doer Doer = client
+	for i := len(r.Middleware) - 1; i >= 0; i-- {
+		doer = r.Middleware[i](doer)
+	}
+	return doer
+}
@@ -0,0 +1,86 @@
+package httpretry
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntegration_Middleware(t *testing.T) {
+
+	t.Run("GIVEN a server that returns 503 once", func(t *testing.T) {
+		var headers []string
+		attempts := 1
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			headers = append(headers, r.Header.Get("X-Chain"))
+			if attempts > 0 {
+				attempts--
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}
+		}))
+		defer ts.Close()
+
+		url, err := url.Parse(ts.URL)
+		require.NoError(t, err)
+
+		t.Run("WHEN two middleware are chained", func(t *testing.T) {
+			calls := 0
+			appending := func(name string) Middleware {
+				return func(next Doer) Doer {
+					return DoerFunc(func(req *http.Request) (*http.Response, error) {
+						calls++
+						req.Header.Set("X-Chain", req.Header.Get("X-Chain")+name+strconv.Itoa(calls))
+						return next.Do(req)
+					})
+				}
+			}
+			api := NewHttpRequest(HttpRequestOptions{
+				URL:              url,
+				RetriesWait:      time.Millisecond,
+				IsRetryCondition: RetryOnStatus(http.StatusServiceUnavailable),
+				Middleware:       []Middleware{appending("a"), appending("b")},
+			})
+			_, status, err := api.HttpGet(context.Background())
+			require.NoError(t, err)
+
+			t.Run("THEN they wrap every attempt in order", func(t *testing.T) {
+				assert.Equal(t, http.StatusOK, status)
+				assert.Equal(t, []string{"a1b2", "a3b4"}, headers)
+			})
+		})
+	})
+
+	t.Run("GIVEN a middleware failing the request", func(t *testing.T) {
+		errSigning := errors.New("signing failed")
+		calls := 0
+		failing := func(next Doer) Doer {
+			return DoerFunc(func(req *http.Request) (*http.Response, error) {
+				calls++
+				return nil, errSigning
+			})
+		}
+		api := NewHttpRequest(HttpRequestOptions{
+			URL:         &url.URL{Scheme: "http", Host: "example.invalid"},
+			RetriesMax:  2,
+			RetriesWait: time.Millisecond,
+			Middleware:  []Middleware{failing},
+		})
+
+		t.Run("WHEN HttpGet is sent", func(t *testing.T) {
+			_, _, err := api.HttpGet(context.Background())
+
+			t.Run("THEN the error is retried like a transport error", func(t *testing.T) {
+				assert.ErrorIs(t, err, errSigning)
+				assert.Equal(t, 2, calls)
+			})
+		})
+	})
+}
@@ -0,0 +1,91 @@
+package httpretry
+
+import (
+	"context"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"sort"
+	"strings"
+)
+
+// FilePart is a file field of a multipart/form-data body.
+type FilePart struct {
+	FieldName string
+	FileName  string
+
+	// ContentType defaults to application/octet-stream
+	ContentType string
+
+	// Body is reopened for every attempt, see FileBody and ReaderAtBody
+	Body BodyFunc
+}
+
+// HttpPostMultipart sends fields and files as a multipart/form-data body.
+// The body is streamed and rebuilt for every attempt so large uploads are
+// never buffered in memory.
+func (r httpRequest) HttpPostMultipart(ctx context.Context, fields map[string]string, files []FilePart, opts ...CallOption) ([]byte, int, error) {
+	boundary := multipart.NewWriter(io.Discard).Boundary()
+
+	r.Header = r.Header.Clone()
+	r.Header.Set("Content-Type", "multipart/form-data; boundary="+boundary)
+
+	return r.DoBody(ctx, http.MethodPost, multipartBody(boundary, fields, files), opts...)
+}
+
+// multipartBody encodes the parts through a pipe, fields first and in key
+// order so every attempt sends the same bytes.
+func multipartBody(boundary string, fields map[string]string, files []FilePart) BodyFunc {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return func() (io.ReadCloser, error) {
+		pr, pw := io.Pipe()
+		go func() {
+			w := multipart.NewWriter(pw)
+			w.SetBoundary(boundary)
+			for _, name := range names {
+				if err := w.WriteField(name, fields[name]); err != nil {
+					pw.CloseWithError(err)
+					return
+				}
+			}
+			for _, file := range files {
+				if err := writeFilePart(w, file); err != nil {
+					pw.CloseWithError(err)
+					return
+				}
+			}
+			pw.CloseWithError(w.Close())
+		}()
+		return pr, nil
+	}
+}
+
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+func writeFilePart(w *multipart.Writer, file FilePart) error {
+	contentType := file.ContentType
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", `form-data; name="`+quoteEscaper.Replace(file.FieldName)+`"; filename="`+quoteEscaper.Replace(file.FileName)+`"`)
+	header.Set("Content-Type", contentType)
+
+	part, err := w.CreatePart(header)
+	if err != nil {
+		return err
+	}
+	body, err := file.Body()
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+	_, err = io.Copy(part, body)
+	return err
+}
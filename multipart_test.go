@@ -0,0 +1,71 @@
+package httpretry
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntegration_HttpPostMultipart(t *testing.T) {
+
+	t.Run("GIVEN a server that fails the first upload", func(t *testing.T) {
+		type upload struct {
+			field   string
+			content string
+			name    string
+		}
+		var uploads []upload
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			require.NoError(t, r.ParseMultipartForm(1<<20))
+			file, header, err := r.FormFile("attachment")
+			require.NoError(t, err)
+			content, _ := io.ReadAll(file)
+			uploads = append(uploads, upload{field: r.FormValue("title"), content: string(content), name: header.Filename})
+			if len(uploads) == 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.WriteHeader(http.StatusCreated)
+		}))
+		defer ts.Close()
+
+		url, err := url.Parse(ts.URL)
+		require.NoError(t, err)
+
+		path := filepath.Join(t.TempDir(), "report.txt")
+		require.NoError(t, os.WriteFile(path, []byte("file content"), 0o644))
+
+		t.Run("AND http request that retries on 503", func(t *testing.T) {
+			api := NewHttpRequest(HttpRequestOptions{
+				URL:         url,
+				RetriesMax:  2,
+				RetriesWait: time.Millisecond,
+				IsRetryCondition: func(resp *http.Response, retryCount int) bool {
+					return resp.StatusCode == http.StatusServiceUnavailable
+				},
+			})
+
+			t.Run("WHEN HttpPostMultipart is sent", func(t *testing.T) {
+				_, status, err := api.HttpPostMultipart(context.Background(),
+					map[string]string{"title": "report"},
+					[]FilePart{{FieldName: "attachment", FileName: "report.txt", Body: FileBody(path)}})
+				require.NoError(t, err)
+
+				t.Run("THEN the complete body is sent on every attempt", func(t *testing.T) {
+					assert.Equal(t, http.StatusCreated, status)
+					want := upload{field: "report", content: "file content", name: "report.txt"}
+					assert.Equal(t, []upload{want, want}, uploads)
+				})
+			})
+		})
+	})
+}
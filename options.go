@@ -0,0 +1,209 @@
+package httpretry
+
+import (
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Option configures a request built by NewRequest.  Options are applied in
+// order to HttpRequestOptions, so any field can be set with an inline
+// function:
+//
+//	api := httpretry.NewRequest(u, httpretry.WithRetries(3), func(o *httpretry.HttpRequestOptions) {
+//		o.AddIdempotencyKey = true
+//	})
+type Option func(options *HttpRequestOptions)
+
+// NewRequest is NewHttpRequest with functional options, unset options keep
+// the defaults of HttpRequestOptions:
+//
+//	api := httpretry.NewRequest(u,
+//		httpretry.WithToken(token),
+//		httpretry.WithRetries(5),
+//		httpretry.WithBackoff(httpretry.ExponentialJitterBackoff{Base: 100 * time.Millisecond, Max: 10 * time.Second}),
+//		httpretry.WithRetryCondition(httpretry.RetryOnStatus(http.StatusServiceUnavailable)),
+//	)
+func NewRequest(u *url.URL, opts ...Option) httpRequest {
+	options := HttpRequestOptions{URL: u}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return NewHttpRequest(options)
+}
+
+// WithToken sends token as a bearer token.
+func WithToken(token string) Option {
+	return func(o *HttpRequestOptions) {
+		o.Token = token
+	}
+}
+
+// WithURL replaces the URL, for example with the Location of a created
+// resource.
+func WithURL(u *url.URL) Option {
+	return func(o *HttpRequestOptions) {
+		o.URL = u
+	}
+}
+
+// WithPath appends path to the URL path, "users" turns
+// https://api.example.com/v1 into https://api.example.com/v1/users.
+func WithPath(path string) Option {
+	return func(o *HttpRequestOptions) {
+		o.URL = o.URL.JoinPath(path)
+	}
+}
+
+// WithRequestHeader sets a header of every call, see WithHeader for a single
+// call.
+func WithRequestHeader(key string, value string) Option {
+	return func(o *HttpRequestOptions) {
+		if o.Header == nil {
+			o.Header = http.Header{}
+		}
+		o.Header.Set(key, value)
+	}
+}
+
+// WithRetries sets the maximum number of attempts, unlike RetriesMax zero
+// means NoRetries instead of the default.  Pass UnlimitedRetries to retry
+// until the context is done.
+func WithRetries(attempts int) Option {
+	return func(o *HttpRequestOptions) {
+		if attempts == 0 {
+			attempts = NoRetries
+		}
+		o.RetriesMax = attempts
+	}
+}
+
+// WithRetriesWait waits a constant wait between attempts.
+func WithRetriesWait(wait time.Duration) Option {
+	return func(o *HttpRequestOptions) {
+		o.RetriesWait = wait
+	}
+}
+
+// WithBackoff computes the wait between attempts with backoff.
+func WithBackoff(backoff BackoffStrategy) Option {
+	return func(o *HttpRequestOptions) {
+		o.Backoff = backoff
+	}
+}
+
+// WithRetryCondition retries responses matching predicate, for example
+// RetryOnStatus(http.StatusServiceUnavailable).
+func WithRetryCondition(predicate RetryPredicate) Option {
+	return func(o *HttpRequestOptions) {
+		o.IsRetryCondition = predicate
+	}
+}
+
+// WithRetryError decides which errors are retried, for example
+// RetryOnTimeout().
+func WithRetryError(predicate RetryErrorPredicate) Option {
+	return func(o *HttpRequestOptions) {
+		o.IsRetryError = predicate
+	}
+}
+
+// WithDefaultPolicy retries with DefaultRetryPolicy.
+func WithDefaultPolicy() Option {
+	return func(o *HttpRequestOptions) {
+		o.UseDefaultPolicy = true
+	}
+}
+
+// WithRespectRetryAfter waits for the Retry-After of 429 and 503 responses,
+// max at most.
+func WithRespectRetryAfter(max time.Duration) Option {
+	return func(o *HttpRequestOptions) {
+		o.RespectRetryAfter = true
+		o.RetryAfterMax = max
+	}
+}
+
+// WithMaxElapsedTime stops retrying once a retry would start after max.
+func WithMaxElapsedTime(max time.Duration) Option {
+	return func(o *HttpRequestOptions) {
+		o.MaxElapsedTime = max
+	}
+}
+
+// WithClient sends the requests with client.
+func WithClient(client *http.Client) Option {
+	return func(o *HttpRequestOptions) {
+		o.Client = client
+	}
+}
+
+// WithTransport sends the requests through transport.
+func WithTransport(transport http.RoundTripper) Option {
+	return func(o *HttpRequestOptions) {
+		o.Transport = transport
+	}
+}
+
+// WithLogger logs to logger instead of the package logger.
+func WithLogger(logger Logger) Option {
+	return func(o *HttpRequestOptions) {
+		o.Logger = logger
+	}
+}
+
+// WithMetrics reports statistics to metrics.
+func WithMetrics(metrics MetricsCollector) Option {
+	return func(o *HttpRequestOptions) {
+		o.Metrics = metrics
+	}
+}
+
+// WithTracer traces calls and attempts with tracer.
+func WithTracer(tracer Tracer) Option {
+	return func(o *HttpRequestOptions) {
+		o.Tracer = tracer
+	}
+}
+
+// WithRateLimiter waits on limiter before every attempt.
+func WithRateLimiter(limiter RateLimiter) Option {
+	return func(o *HttpRequestOptions) {
+		o.RateLimiter = limiter
+	}
+}
+
+// WithCircuitBreaker fast-fails calls while breaker is open.
+func WithCircuitBreaker(breaker *CircuitBreaker) Option {
+	return func(o *HttpRequestOptions) {
+		o.CircuitBreaker = breaker
+	}
+}
+
+// WithRetryBudget caps retries with budget.
+func WithRetryBudget(budget *RetryBudget) Option {
+	return func(o *HttpRequestOptions) {
+		o.RetryBudget = budget
+	}
+}
+
+// WithMiddleware appends middleware to the chain wrapping every attempt.
+func WithMiddleware(middleware ...Middleware) Option {
+	return func(o *HttpRequestOptions) {
+		o.Middleware = append(o.Middleware, middleware...)
+	}
+}
+
+// WithHooks calls hooks before attempts, before retries and when giving up.
+func WithHooks(hooks Hooks) Option {
+	return func(o *HttpRequestOptions) {
+		o.Hooks = hooks
+	}
+}
+
+// WithClock tells the time and waits with clock.
+func WithClock(clock Clock) Option {
+	return func(o *HttpRequestOptions) {
+		o.Clock = clock
+	}
+}
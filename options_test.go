@@ -0,0 +1,63 @@
+package httpretry
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewRequest(t *testing.T) {
+
+	t.Run("GIVEN functional options", func(t *testing.T) {
+		u := &url.URL{Scheme: "https", Host: "example.com"}
+		backoff := ExponentialBackoff{Base: time.Second, Max: time.Minute}
+		api := NewRequest(u,
+			WithToken("t0k3n"),
+			WithRetries(5),
+			WithBackoff(backoff),
+			WithRequestHeader("X-Tenant", "acme"),
+			WithRespectRetryAfter(time.Minute),
+			func(o *HttpRequestOptions) { o.AddIdempotencyKey = true },
+		)
+
+		t.Run("THEN they are applied on top of the defaults", func(t *testing.T) {
+			assert.Equal(t, u, api.URL)
+			assert.Equal(t, 5, api.RetriesMax)
+			assert.Equal(t, backoff, api.Backoff)
+			assert.Equal(t, "acme", api.Header.Get("X-Tenant"))
+			assert.Equal(t, "Bearer t0k3n", api.Header.Get("Authorization"))
+			assert.True(t, api.RespectRetryAfter)
+			assert.True(t, api.AddIdempotencyKey)
+			assert.Equal(t, DefaultUserAgent, api.Header.Get("User-Agent"))
+		})
+	})
+
+	t.Run("GIVEN WithRetries(0)", func(t *testing.T) {
+		calls := 0
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer ts.Close()
+
+		u, err := url.Parse(ts.URL)
+		require.NoError(t, err)
+		api := NewRequest(u, WithRetries(0), WithRetryCondition(RetryOnStatus(http.StatusServiceUnavailable)))
+
+		t.Run("WHEN HttpGet is sent", func(t *testing.T) {
+			_, status, err := api.HttpGet(context.Background())
+			require.NoError(t, err)
+
+			t.Run("THEN a single attempt is made", func(t *testing.T) {
+				assert.Equal(t, http.StatusServiceUnavailable, status)
+				assert.Equal(t, 1, calls)
+			})
+		})
+	})
+}
@@ -0,0 +1,141 @@
+package httpretry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// ErrStopPagination is returned by PaginateOptions.OnPage to stop paginating
+// without an error.
+var ErrStopPagination = errors.New("stop pagination")
+
+type PaginateOptions struct {
+	// OnPage is called with every page in order.  Returning an error stops
+	// pagination and Paginate returns it, unless it is ErrStopPagination.
+	// Required.
+	OnPage func(page *Response) error
+
+	// MaxPages stops after that many pages
+	// defaults to no limit
+	MaxPages int
+
+	// NextURL returns the URL of the page after page, relative URLs are
+	// resolved against the URL of page, "" ends pagination.
+	// defaults to NextPageURL
+	NextURL func(page *Response) (string, error)
+}
+
+// Paginate gets the configured URL and follows the next links of every page,
+// applying the retry policy to each page.  A page answered with a non 2xx
+// status, after retries, ends pagination with an error.  Pages on another
+// host than the configured URL are requested without credentials.
+func (r httpRequest) Paginate(ctx context.Context, options PaginateOptions) error {
+	if options.OnPage == nil {
+		return errors.New("paginate without OnPage")
+	}
+	if options.NextURL == nil {
+		options.NextURL = NextPageURL
+	}
+
+	current := r.URL
+	for pages := 1; ; pages++ {
+		page, err := r.sendingTo(current).HttpGetFull(ctx)
+		if err != nil {
+			return err
+		}
+		if page.StatusCode < 200 || page.StatusCode >= 300 {
+			return newUnexpectedStatusError([]int{http.StatusOK}, page, current)
+		}
+		if err := options.OnPage(page); err != nil {
+			if errors.Is(err, ErrStopPagination) {
+				return nil
+			}
+			return err
+		}
+		if options.MaxPages > 0 && pages >= options.MaxPages {
+			return nil
+		}
+
+		next, err := options.NextURL(page)
+		if err != nil || next == "" {
+			return err
+		}
+		nextURL, err := current.Parse(next)
+		if err != nil {
+			return err
+		}
+		// a page linking to itself would never end
+		if nextURL.String() == current.String() {
+			return nil
+		}
+		current = nextURL
+	}
+}
+
+// NextPageURL returns the rel="next" target of the RFC 8288 Link header of
+// page, or else the JSON:API links.next member of its body, or "" on the last
+// page.
+func NextPageURL(page *Response) (string, error) {
+	if next := linkHeaderTarget(page.Header, "next"); next != "" {
+		return next, nil
+	}
+	if len(page.Body) == 0 {
+		return "", nil
+	}
+
+	var document struct {
+		Links struct {
+			Next json.RawMessage `json:"next"`
+		} `json:"links"`
+	}
+	if err := json.Unmarshal(page.Body, &document); err != nil {
+		return "", &DecodeError{StatusCode: page.StatusCode, Body: page.Body, Err: err}
+	}
+	// links are a string or, since JSON:API 1.1, a link object
+	var next string
+	if err := json.Unmarshal(document.Links.Next, &next); err == nil {
+		return next, nil
+	}
+	var link struct {
+		Href string `json:"href"`
+	}
+	if err := json.Unmarshal(document.Links.Next, &link); err == nil {
+		return link.Href, nil
+	}
+	return "", nil
+}
+
+// linkHeaderTarget returns the target of the first link of header with the
+// relation type rel.
+func linkHeaderTarget(header http.Header, rel string) string {
+	for _, value := range header.Values("Link") {
+		for value != "" {
+			start := strings.IndexByte(value, '<')
+			end := strings.IndexByte(value, '>')
+			if start < 0 || end < start {
+				break
+			}
+			target := value[start+1 : end]
+			params := value[end+1:]
+			value = ""
+			if next := strings.IndexByte(params, ','); next >= 0 {
+				params, value = params[:next], params[next+1:]
+			}
+			for _, param := range strings.Split(params, ";") {
+				name, relations, ok := strings.Cut(strings.TrimSpace(param), "=")
+				if !ok || !strings.EqualFold(name, "rel") {
+					continue
+				}
+				for _, relation := range strings.Fields(strings.Trim(relations, `"`)) {
+					if strings.EqualFold(relation, rel) {
+						return target
+					}
+				}
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,192 @@
+package httpretry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNextPageURL(t *testing.T) {
+
+	t.Run("GIVEN a Link header with several relations", func(t *testing.T) {
+		page := &Response{Header: http.Header{"Link": {`</things?page=1>; rel="first", </things?page=3>; rel="next last"`}}}
+
+		t.Run("THEN the next target is returned", func(t *testing.T) {
+			next, err := NextPageURL(page)
+			require.NoError(t, err)
+			assert.Equal(t, "/things?page=3", next)
+		})
+	})
+
+	t.Run("GIVEN a JSON:API document with a next link", func(t *testing.T) {
+		page := &Response{Body: []byte(`{"data":[],"links":{"next":"/things?page[number]=2"}}`)}
+
+		t.Run("THEN the next link is returned", func(t *testing.T) {
+			next, err := NextPageURL(page)
+			require.NoError(t, err)
+			assert.Equal(t, "/things?page[number]=2", next)
+		})
+	})
+
+	t.Run("GIVEN a JSON:API document with a next link object", func(t *testing.T) {
+		page := &Response{Body: []byte(`{"data":[],"links":{"next":{"href":"/things?page=2"}}}`)}
+
+		t.Run("THEN its href is returned", func(t *testing.T) {
+			next, err := NextPageURL(page)
+			require.NoError(t, err)
+			assert.Equal(t, "/things?page=2", next)
+		})
+	})
+
+	t.Run("GIVEN a last page", func(t *testing.T) {
+		page := &Response{Body: []byte(`{"data":[],"links":{"next":null}}`)}
+
+		t.Run("THEN no next link is returned", func(t *testing.T) {
+			next, err := NextPageURL(page)
+			require.NoError(t, err)
+			assert.Empty(t, next)
+		})
+	})
+}
+
+func TestIntegration_Paginate(t *testing.T) {
+
+	t.Run("GIVEN a server with 3 pages AND a flaky second page", func(t *testing.T) {
+		secondPageCalls := 0
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			page := r.URL.Query().Get("page")
+			switch page {
+			case "":
+				fmt.Fprint(w, `{"data":[1],"links":{"next":"/things?page=2"}}`)
+			case "2":
+				secondPageCalls++
+				if secondPageCalls == 1 {
+					w.WriteHeader(http.StatusServiceUnavailable)
+					return
+				}
+				w.Header().Set("Link", `</things?page=3>; rel="next"`)
+				fmt.Fprint(w, `{"data":[2]}`)
+			case "3":
+				fmt.Fprint(w, `{"data":[3],"links":{}}`)
+			}
+		}))
+		defer ts.Close()
+
+		url, err := url.Parse(ts.URL + "/things")
+		require.NoError(t, err)
+
+		api := NewHttpRequest(HttpRequestOptions{
+			URL:         url,
+			RetriesMax:  2,
+			RetriesWait: time.Millisecond,
+			IsRetryCondition: func(resp *http.Response, retryCount int) bool {
+				return resp.StatusCode == http.StatusServiceUnavailable
+			},
+		})
+
+		t.Run("WHEN Paginate is called", func(t *testing.T) {
+			var bodies []string
+			err := api.Paginate(context.Background(), PaginateOptions{
+				OnPage: func(page *Response) error {
+					bodies = append(bodies, string(page.Body))
+					return nil
+				},
+			})
+			require.NoError(t, err)
+
+			t.Run("THEN every page is yielded in order", func(t *testing.T) {
+				assert.Equal(t, []string{
+					`{"data":[1],"links":{"next":"/things?page=2"}}`,
+					`{"data":[2]}`,
+					`{"data":[3],"links":{}}`,
+				}, bodies)
+				assert.Equal(t, 2, secondPageCalls)
+			})
+		})
+
+		t.Run("WHEN OnPage returns ErrStopPagination", func(t *testing.T) {
+			pages := 0
+			err := api.Paginate(context.Background(), PaginateOptions{
+				OnPage: func(page *Response) error {
+					pages++
+					return ErrStopPagination
+				},
+			})
+
+			t.Run("THEN pagination stops without error", func(t *testing.T) {
+				assert.NoError(t, err)
+				assert.Equal(t, 1, pages)
+			})
+		})
+
+		t.Run("WHEN OnPage returns an error", func(t *testing.T) {
+			failure := errors.New("failure")
+			err := api.Paginate(context.Background(), PaginateOptions{
+				OnPage: func(page *Response) error {
+					return failure
+				},
+			})
+
+			t.Run("THEN Paginate returns it", func(t *testing.T) {
+				assert.Equal(t, failure, err)
+			})
+		})
+
+		t.Run("WHEN Paginate is called without OnPage", func(t *testing.T) {
+			err := api.Paginate(context.Background(), PaginateOptions{})
+
+			t.Run("THEN it fails instead of panicking", func(t *testing.T) {
+				assert.Error(t, err)
+			})
+		})
+	})
+}
+
+func TestIntegration_PaginateCrossHost(t *testing.T) {
+
+	t.Run("GIVEN a server whose next page is on another host", func(t *testing.T) {
+		var otherAuthorization, otherCookie string
+		other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			otherAuthorization, otherCookie = r.Header.Get("Authorization"), r.Header.Get("Cookie")
+			fmt.Fprint(w, `{"data":[2]}`)
+		}))
+		defer other.Close()
+		var authorization string
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			authorization = r.Header.Get("Authorization")
+			w.Header().Set("Link", "<"+other.URL+`/things?page=2>; rel="next"`)
+			fmt.Fprint(w, `{"data":[1]}`)
+		}))
+		defer ts.Close()
+
+		url, err := url.Parse(ts.URL + "/things")
+		require.NoError(t, err)
+		api := NewHttpRequest(HttpRequestOptions{URL: url, Token: "s3cr3t", Header: http.Header{"Cookie": []string{"session=1"}}})
+
+		t.Run("WHEN Paginate is called", func(t *testing.T) {
+			pages := 0
+			err := api.Paginate(context.Background(), PaginateOptions{
+				OnPage: func(page *Response) error {
+					pages++
+					return nil
+				},
+			})
+			require.NoError(t, err)
+
+			t.Run("THEN the other host gets the page without the credentials", func(t *testing.T) {
+				assert.Equal(t, 2, pages)
+				assert.Equal(t, "Bearer s3cr3t", authorization)
+				assert.Empty(t, otherAuthorization)
+				assert.Empty(t, otherCookie)
+			})
+		})
+	})
+}
@@ -0,0 +1,57 @@
+package httpretry
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// PathParams are substituted for {name} placeholders in the URL path, see
+// WithPathParams.
+type PathParams map[string]string
+
+var pathPlaceholder = regexp.MustCompile(`\{([^{}/]+)\}`)
+
+// WithPathParams returns a copy of the request with the {name} placeholders
+// of its URL path replaced by the escaped values of params, so one request
+// can be reused across resources:
+//
+//	u, _ := url.Parse("https://api.example.com/things/{id}")
+//	api := httpretry.NewHttpRequest(httpretry.HttpRequestOptions{URL: u})
+//	body, status, err := api.WithPathParams(httpretry.PathParams{"id": id}).HttpGet(ctx)
+//
+// Values are escaped as a single path segment, a "/" in a value is sent as
+// %2F.  Placeholders without a value are left in the path.
+func (r httpRequest) WithPathParams(params PathParams) httpRequest {
+	template := r.URL.Path
+	var path, rawPath strings.Builder
+	last := 0
+	for _, match := range pathPlaceholder.FindAllStringSubmatchIndex(template, -1) {
+		literal := template[last:match[0]]
+		path.WriteString(literal)
+		rawPath.WriteString(escapePath(literal))
+
+		placeholder := template[match[0]:match[1]]
+		if value, ok := params[template[match[2]:match[3]]]; ok {
+			path.WriteString(value)
+			rawPath.WriteString(url.PathEscape(value))
+		} else {
+			path.WriteString(placeholder)
+			rawPath.WriteString(escapePath(placeholder))
+		}
+		last = match[1]
+	}
+	path.WriteString(template[last:])
+	rawPath.WriteString(escapePath(template[last:]))
+
+	u := *r.URL
+	u.Path = path.String()
+	u.RawPath = rawPath.String()
+	r.URL = &u
+	return r
+}
+
+// escapePath escapes a literal part of a path, keeping its "/" separators.
+func escapePath(path string) string {
+	return (&url.URL{Path: path}).EscapedPath()
+}
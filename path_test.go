@@ -0,0 +1,63 @@
+package httpretry
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithPathParams(t *testing.T) {
+
+	t.Run("GIVEN http request with a templated URL", func(t *testing.T) {
+		u, err := url.Parse("https://api.example.com/users/{user}/things/{id}?include=owner")
+		require.NoError(t, err)
+		api := NewHttpRequest(HttpRequestOptions{URL: u})
+
+		t.Run("WHEN WithPathParams is called", func(t *testing.T) {
+			thing := api.WithPathParams(PathParams{"user": "a b", "id": "1/2"})
+
+			t.Run("THEN the values are escaped into a copy of the URL", func(t *testing.T) {
+				assert.Equal(t, "https://api.example.com/users/a%20b/things/1%2F2?include=owner", thing.URL.String())
+				assert.Equal(t, "/users/a b/things/1/2", thing.URL.Path)
+				assert.Equal(t, "/users/{user}/things/{id}", api.URL.Path)
+			})
+		})
+
+		t.Run("WHEN a placeholder has no value", func(t *testing.T) {
+			thing := api.WithPathParams(PathParams{"user": "a"})
+
+			t.Run("THEN it is left in the path", func(t *testing.T) {
+				assert.Equal(t, "/users/a/things/{id}", thing.URL.Path)
+			})
+		})
+	})
+}
+
+func TestIntegration_WithPathParams(t *testing.T) {
+
+	t.Run("GIVEN a server", func(t *testing.T) {
+		var rawPath string
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rawPath = r.URL.EscapedPath()
+		}))
+		defer ts.Close()
+
+		url, err := url.Parse(ts.URL + "/things/{id}")
+		require.NoError(t, err)
+
+		t.Run("WHEN HttpGet is sent with path params", func(t *testing.T) {
+			api := NewHttpRequest(HttpRequestOptions{URL: url})
+			_, _, err := api.WithPathParams(PathParams{"id": "a/b"}).HttpGet(context.Background())
+			require.NoError(t, err)
+
+			t.Run("THEN the server receives the escaped path", func(t *testing.T) {
+				assert.Equal(t, "/things/a%2Fb", rawPath)
+			})
+		})
+	})
+}
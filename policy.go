@@ -0,0 +1,88 @@
+package httpretry
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// RetryPolicy pairs the predicates deciding when a call is retried.
+type RetryPolicy struct {
+	IsRetryCondition RetryPredicate
+	IsRetryError     RetryErrorPredicate
+}
+
+// idempotentMethods are safe to retry because sending them twice has the same
+// effect as sending them once.
+var idempotentMethods = map[string]bool{
+	http.MethodGet:     true,
+	http.MethodHead:    true,
+	http.MethodOptions: true,
+	http.MethodDelete:  true,
+	http.MethodPut:     true,
+}
+
+// defaultRetryStatusCodes are transient failures worth retrying.
+var defaultRetryStatusCodes = map[int]bool{
+	http.StatusTooManyRequests:    true,
+	http.StatusBadGateway:         true,
+	http.StatusServiceUnavailable: true,
+	http.StatusGatewayTimeout:     true,
+}
+
+// DefaultRetryPolicy retries idempotent methods (GET, HEAD, OPTIONS, DELETE
+// and PUT) on connection errors, except IsPermanentTLSError, and on 429,
+// 502, 503 and 504 responses.  POST
+// and PATCH are never retried since the upstream may have processed them.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		IsRetryCondition: func(resp *http.Response, retryCount int) bool {
+			return resp.Request != nil && idempotentMethods[resp.Request.Method] && defaultRetryStatusCodes[resp.StatusCode]
+		},
+		IsRetryError: func(err error, retryCount int) bool {
+			return idempotentMethods[errorMethod(err)] && !IsPermanentTLSError(err)
+		},
+	}
+}
+
+var (
+	defaultPolicyMu sync.RWMutex
+	defaultPolicy   RetryPolicy
+)
+
+// SetDefaultRetryPolicy sets the predicates of every request created
+// afterwards without its own IsRetryCondition or IsRetryError, for example to
+// configure the retry behavior of a service once from main.  A zero
+// RetryPolicy restores the StatusClassifier default.
+func SetDefaultRetryPolicy(policy RetryPolicy) {
+	defaultPolicyMu.Lock()
+	defer defaultPolicyMu.Unlock()
+	defaultPolicy = policy
+}
+
+func getDefaultRetryPolicy() RetryPolicy {
+	defaultPolicyMu.RLock()
+	defer defaultPolicyMu.RUnlock()
+	return defaultPolicy
+}
+
+// errorMethod recovers the method of a failed request from the *url.Error
+// returned by http.Client, which stores it as "Get", "Post", etc.
+func errorMethod(err error) string {
+	var urlErr *url.Error
+	if !errors.As(err, &urlErr) {
+		return ""
+	}
+	return strings.ToUpper(urlErr.Op)
+}
+
+// urlErrorOp formats method like http.Client does in *url.Error, for example
+// "Get", so errorMethod can recover it.
+func urlErrorOp(method string) string {
+	if method == "" {
+		return "Get"
+	}
+	return method[:1] + strings.ToLower(method[1:])
+}
@@ -0,0 +1,127 @@
+package httpretry
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/mandric/httpretry/httpretrytest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntegration_DefaultRetryPolicy(t *testing.T) {
+
+	t.Run("GIVEN a server that returns 503 once per method", func(t *testing.T) {
+		requests := map[string]int{}
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests[r.Method]++
+			if requests[r.Method] == 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer ts.Close()
+
+		url, err := url.Parse(ts.URL)
+		require.NoError(t, err)
+
+		t.Run("AND http request using the default policy", func(t *testing.T) {
+			api := NewHttpRequest(HttpRequestOptions{
+				URL:              url,
+				RetriesWait:      time.Millisecond,
+				UseDefaultPolicy: true,
+			})
+
+			t.Run("WHEN HttpGet is sent", func(t *testing.T) {
+				_, code, err := api.HttpGet(context.Background())
+				require.NoError(t, err)
+
+				t.Run("THEN the 503 is retried", func(t *testing.T) {
+					assert.Equal(t, http.StatusOK, code)
+					assert.Equal(t, 2, requests[http.MethodGet])
+				})
+			})
+
+			t.Run("WHEN HttpPost is sent", func(t *testing.T) {
+				_, code, err := api.HttpPost(context.Background(), []byte("{}"))
+				require.NoError(t, err)
+
+				t.Run("THEN the 503 is not retried", func(t *testing.T) {
+					assert.Equal(t, http.StatusServiceUnavailable, code)
+					assert.Equal(t, 1, requests[http.MethodPost])
+				})
+			})
+		})
+	})
+
+	t.Run("GIVEN a transport that always fails to connect", func(t *testing.T) {
+		attempts := map[string]int{}
+		url, err := url.Parse("http://upstream.test")
+		require.NoError(t, err)
+
+		api := NewHttpRequest(HttpRequestOptions{
+			URL:              url,
+			RetriesMax:       3,
+			RetriesWait:      time.Millisecond,
+			UseDefaultPolicy: true,
+			Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				attempts[req.Method]++
+				return nil, syscall.ECONNREFUSED
+			}),
+		})
+
+		t.Run("WHEN HttpPut AND HttpPost are sent", func(t *testing.T) {
+			_, _, putErr := api.HttpPut(context.Background(), []byte("{}"))
+			_, _, postErr := api.HttpPost(context.Background(), []byte("{}"))
+
+			t.Run("THEN only the PUT connection error is retried", func(t *testing.T) {
+				assert.True(t, IsConnectionRefused(putErr))
+				assert.True(t, IsConnectionRefused(postErr))
+				assert.Equal(t, 3, attempts[http.MethodPut])
+				assert.Equal(t, 1, attempts[http.MethodPost])
+			})
+		})
+	})
+}
+
+func TestIntegration_SetDefaultRetryPolicy(t *testing.T) {
+
+	t.Run("GIVEN a server failing with 503 AND a default policy retrying only 429", func(t *testing.T) {
+		ts := httpretrytest.NewFlakyServer(3, http.StatusServiceUnavailable)
+		defer ts.Close()
+
+		url, err := url.Parse(ts.URL)
+		require.NoError(t, err)
+		SetDefaultRetryPolicy(RetryPolicy{IsRetryCondition: RetryOnStatus(http.StatusTooManyRequests)})
+		defer SetDefaultRetryPolicy(RetryPolicy{})
+
+		t.Run("WHEN a request without predicates is sent", func(t *testing.T) {
+			resp, err := NewHttpRequest(HttpRequestOptions{URL: url, RetriesWait: time.Millisecond}).HttpGetFull(context.Background())
+			require.NoError(t, err)
+
+			t.Run("THEN it inherits the default policy", func(t *testing.T) {
+				assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+				assert.Equal(t, 1, resp.Attempts)
+			})
+		})
+
+		t.Run("WHEN a request with its own predicate is sent", func(t *testing.T) {
+			resp, err := NewHttpRequest(HttpRequestOptions{
+				URL:              url,
+				RetriesWait:      time.Millisecond,
+				IsRetryCondition: RetryOnStatus(http.StatusServiceUnavailable),
+			}).HttpGetFull(context.Background())
+			require.NoError(t, err)
+
+			t.Run("THEN its predicate wins", func(t *testing.T) {
+				assert.Equal(t, http.StatusOK, resp.StatusCode)
+			})
+		})
+	})
+}
@@ -0,0 +1,241 @@
+package httpretry
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// PolicyConfig is a declarative document of retry policies, in YAML or
+// JSON:
+//
+//	default:
+//	  max_retries: 3
+//	  wait: 200ms
+//	  wait_max: 5s
+//	  jitter: true
+//	endpoints:
+//	  - match: api.vendora.com
+//	    max_retries: 5
+//	    retry_statuses: [429, 502, 503]
+//	    circuit_breaker: {threshold: 10, cooldown: 1m}
+//	  - match: "*.vendorb.com/v2/"
+//	    rate_limit: {per_second: 20, burst: 5}
+//
+// Endpoints inherit the fields of default they don't set, match is a pattern
+// of PolicyRouter.Handle.
+type PolicyConfig struct {
+	Default   PolicySpec     `yaml:"default" json:"default"`
+	Endpoints []EndpointSpec `yaml:"endpoints" json:"endpoints"`
+}
+
+// PolicySpec declares the settings of an EndpointPolicy, zero fields keep the
+// settings of the request.  Durations are strings like "500ms".
+type PolicySpec struct {
+	// MaxRetries max number of attempts, -1 for a single attempt
+	MaxRetries int `yaml:"max_retries" json:"max_retries"`
+
+	// Wait, WaitMax and BackoffMultiplier build a ConstantBackoff,
+	// ExponentialBackoff or, with Jitter, ExponentialJitterBackoff
+	Wait              time.Duration `yaml:"wait" json:"wait"`
+	WaitMax           time.Duration `yaml:"wait_max" json:"wait_max"`
+	BackoffMultiplier float64       `yaml:"backoff_multiplier" json:"backoff_multiplier"`
+	Jitter            bool          `yaml:"jitter" json:"jitter"`
+
+	// RetryStatuses replaces the StatusClassifier, see RetryOnStatus
+	RetryStatuses []int `yaml:"retry_statuses" json:"retry_statuses"`
+
+	CircuitBreaker *CircuitBreakerSpec `yaml:"circuit_breaker" json:"circuit_breaker"`
+	RateLimit      *RateLimitSpec      `yaml:"rate_limit" json:"rate_limit"`
+}
+
+// EndpointSpec is a PolicySpec for the URLs matching Match.
+type EndpointSpec struct {
+	Match      string `yaml:"match" json:"match"`
+	PolicySpec `yaml:",inline"`
+}
+
+// CircuitBreakerSpec declares the CircuitBreakerOptions of an endpoint.
+type CircuitBreakerSpec struct {
+	Threshold int           `yaml:"threshold" json:"threshold"`
+	Cooldown  time.Duration `yaml:"cooldown" json:"cooldown"`
+}
+
+// RateLimitSpec declares the NewRateLimiter of an endpoint.
+type RateLimitSpec struct {
+	PerSecond float64 `yaml:"per_second" json:"per_second"`
+	Burst     int     `yaml:"burst" json:"burst"`
+}
+
+// ParsePolicyConfig parses a YAML or JSON document, unknown fields are
+// errors so typos don't go unnoticed.
+func ParsePolicyConfig(data []byte) (PolicyConfig, error) {
+	var config PolicyConfig
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&config); err != nil && !errors.Is(err, io.EOF) {
+		return PolicyConfig{}, err
+	}
+	if err := config.validate(); err != nil {
+		return PolicyConfig{}, err
+	}
+	return config, nil
+}
+
+func (c PolicyConfig) validate() error {
+	if err := c.Default.validate(); err != nil {
+		return fmt.Errorf("default: %w", err)
+	}
+	for i, endpoint := range c.Endpoints {
+		if endpoint.Match == "" {
+			return fmt.Errorf("endpoints[%d]: match is required", i)
+		}
+		if err := endpoint.validate(); err != nil {
+			return fmt.Errorf("endpoints[%d] %s: %w", i, endpoint.Match, err)
+		}
+	}
+	return nil
+}
+
+func (s PolicySpec) validate() error {
+	switch {
+	case s.MaxRetries < NoRetries:
+		return fmt.Errorf("invalid max_retries %d", s.MaxRetries)
+	case s.Wait < 0 || s.WaitMax < 0:
+		return errors.New("negative wait")
+	case s.BackoffMultiplier != 0 && s.BackoffMultiplier < 1:
+		return fmt.Errorf("invalid backoff_multiplier %v", s.BackoffMultiplier)
+	case s.RateLimit != nil && (!(s.RateLimit.PerSecond > 0) || math.IsInf(s.RateLimit.PerSecond, 1)):
+		return fmt.Errorf("invalid rate_limit per_second %v", s.RateLimit.PerSecond)
+	case s.CircuitBreaker != nil && (s.CircuitBreaker.Threshold < 0 || s.CircuitBreaker.Cooldown < 0):
+		return errors.New("negative circuit_breaker settings")
+	}
+	return nil
+}
+
+// inherit returns s with the fields it doesn't set taken from parent.
+func (s PolicySpec) inherit(parent PolicySpec) PolicySpec {
+	if s.MaxRetries == 0 {
+		s.MaxRetries = parent.MaxRetries
+	}
+	if s.Wait == 0 && s.WaitMax == 0 && s.BackoffMultiplier == 0 && !s.Jitter {
+		s.Wait, s.WaitMax, s.BackoffMultiplier, s.Jitter = parent.Wait, parent.WaitMax, parent.BackoffMultiplier, parent.Jitter
+	}
+	if s.RetryStatuses == nil {
+		s.RetryStatuses = parent.RetryStatuses
+	}
+	if s.CircuitBreaker == nil {
+		s.CircuitBreaker = parent.CircuitBreaker
+	}
+	if s.RateLimit == nil {
+		s.RateLimit = parent.RateLimit
+	}
+	return s
+}
+
+func (s PolicySpec) backoff() BackoffStrategy {
+	switch {
+	case s.Jitter:
+		return ExponentialJitterBackoff{Base: s.Wait, Max: s.WaitMax, Multiplier: s.BackoffMultiplier}
+	case s.WaitMax > 0 || s.BackoffMultiplier > 0:
+		return ExponentialBackoff{Base: s.Wait, Max: s.WaitMax, Multiplier: s.BackoffMultiplier}
+	case s.Wait > 0:
+		return ConstantBackoff{Wait: s.Wait}
+	}
+	return nil
+}
+
+// PolicyFile keeps a PolicyRouter in sync with a PolicyConfig file:
+//
+//	policies, err := httpretry.LoadPolicyFile("/etc/service/retry.yaml")
+//	api := httpretry.NewHttpRequest(httpretry.HttpRequestOptions{URL: url, PolicyRouter: policies.Router()})
+//	...
+//	err = policies.Reload() // on SIGHUP
+//
+// Circuit breakers and rate limiters keep their state across reloads while
+// their settings don't change.  It is safe for concurrent use.
+type PolicyFile struct {
+	path   string
+	router *PolicyRouter
+
+	mu       sync.Mutex
+	breakers map[string]*CircuitBreaker
+	limiters map[string]RateLimiter
+}
+
+// LoadPolicyFile reads the PolicyConfig at path.
+func LoadPolicyFile(path string) (*PolicyFile, error) {
+	f := &PolicyFile{path: path, router: NewPolicyRouter()}
+	if err := f.Reload(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Router returns the router of the policies, it stays the same across
+// reloads.
+func (f *PolicyFile) Router() *PolicyRouter {
+	return f.router
+}
+
+// Reload reads the file again and atomically replaces the policies of the
+// router.  On error the previous policies are kept.
+func (f *PolicyFile) Reload() error {
+	data, err := os.ReadFile(f.path)
+	if err != nil {
+		return err
+	}
+	config, err := ParsePolicyConfig(data)
+	if err != nil {
+		return fmt.Errorf("%s: %w", f.path, err)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	breakers, limiters := map[string]*CircuitBreaker{}, map[string]RateLimiter{}
+	router := NewPolicyRouter()
+	// the default matches every URL with the lowest specificity
+	router.Handle("", f.endpointPolicy("", config.Default, breakers, limiters))
+	for _, endpoint := range config.Endpoints {
+		router.Handle(endpoint.Match, f.endpointPolicy(endpoint.Match, endpoint.inherit(config.Default), breakers, limiters))
+	}
+	f.router.replaceRoutes(router)
+	f.breakers, f.limiters = breakers, limiters
+	return nil
+}
+
+// endpointPolicy builds the policy of spec, reusing the breaker and limiter
+// of pattern when their settings didn't change.  The ones in use are added
+// to breakers and limiters.
+func (f *PolicyFile) endpointPolicy(pattern string, spec PolicySpec, breakers map[string]*CircuitBreaker, limiters map[string]RateLimiter) EndpointPolicy {
+	policy := EndpointPolicy{
+		RetriesMax: spec.MaxRetries,
+		Backoff:    spec.backoff(),
+	}
+	if spec.RetryStatuses != nil {
+		policy.IsRetryCondition = RetryOnStatus(spec.RetryStatuses...)
+	}
+	if spec.CircuitBreaker != nil {
+		key := fmt.Sprintf("%s|%d|%s", pattern, spec.CircuitBreaker.Threshold, spec.CircuitBreaker.Cooldown)
+		if breakers[key] = f.breakers[key]; breakers[key] == nil {
+			breakers[key] = NewCircuitBreaker(CircuitBreakerOptions{Threshold: spec.CircuitBreaker.Threshold, Cooldown: spec.CircuitBreaker.Cooldown})
+		}
+		policy.CircuitBreaker = breakers[key]
+	}
+	if spec.RateLimit != nil {
+		key := fmt.Sprintf("%s|%v|%d", pattern, spec.RateLimit.PerSecond, spec.RateLimit.Burst)
+		if limiters[key] = f.limiters[key]; limiters[key] == nil {
+			// validate rejected the rates NewRateLimiter fails on
+			limiters[key], _ = NewRateLimiter(spec.RateLimit.PerSecond, spec.RateLimit.Burst)
+		}
+		policy.RateLimiter = limiters[key]
+	}
+	return policy
+}
@@ -0,0 +1,125 @@
+package httpretry
+
+import (
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePolicyConfig(t *testing.T) {
+
+	t.Run("GIVEN a JSON document", func(t *testing.T) {
+		data := []byte(`{"default": {"max_retries": 3, "wait": "200ms"}, "endpoints": [{"match": "api.example.com", "retry_statuses": [429]}]}`)
+
+		t.Run("WHEN it is parsed", func(t *testing.T) {
+			config, err := ParsePolicyConfig(data)
+			require.NoError(t, err)
+
+			t.Run("THEN the policies are read", func(t *testing.T) {
+				assert.Equal(t, 3, config.Default.MaxRetries)
+				assert.Equal(t, 200*time.Millisecond, config.Default.Wait)
+				require.Len(t, config.Endpoints, 1)
+				assert.Equal(t, []int{429}, config.Endpoints[0].RetryStatuses)
+			})
+		})
+	})
+
+	t.Run("GIVEN documents with mistakes", func(t *testing.T) {
+
+		t.Run("THEN unknown fields and invalid values are errors", func(t *testing.T) {
+			_, err := ParsePolicyConfig([]byte("default:\n  max_retry: 3\n"))
+			assert.ErrorContains(t, err, "max_retry")
+			_, err = ParsePolicyConfig([]byte("default:\n  wait: soon\n"))
+			assert.Error(t, err)
+			_, err = ParsePolicyConfig([]byte("endpoints:\n  - max_retries: 3\n"))
+			assert.ErrorContains(t, err, "match is required")
+			_, err = ParsePolicyConfig([]byte("endpoints:\n  - match: a.com\n    rate_limit: {per_second: 0}\n"))
+			assert.ErrorContains(t, err, "endpoints[0] a.com: invalid rate_limit")
+		})
+	})
+}
+
+func TestPolicyFile(t *testing.T) {
+
+	t.Run("GIVEN a YAML policy file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "retry.yaml")
+		require.NoError(t, os.WriteFile(path, []byte(`
+default:
+  max_retries: 3
+  wait: 200ms
+  wait_max: 5s
+endpoints:
+  - match: api.vendora.com
+    max_retries: 5
+    retry_statuses: [429, 503]
+    circuit_breaker: {threshold: 10, cooldown: 1m}
+  - match: "*.vendorb.com/v2/"
+    rate_limit: {per_second: 20, burst: 5}
+`), 0o600))
+		policies, err := LoadPolicyFile(path)
+		require.NoError(t, err)
+		match := func(rawURL string) EndpointPolicy {
+			u, err := url.Parse(rawURL)
+			require.NoError(t, err)
+			policy, ok := policies.Router().Match(u)
+			require.True(t, ok)
+			return policy
+		}
+
+		t.Run("THEN the default applies to other endpoints", func(t *testing.T) {
+			policy := match("https://other.com/")
+			assert.Equal(t, 3, policy.RetriesMax)
+			assert.Equal(t, ExponentialBackoff{Base: 200 * time.Millisecond, Max: 5 * time.Second}, policy.Backoff)
+			assert.Nil(t, policy.CircuitBreaker)
+		})
+
+		t.Run("AND the endpoints inherit the default", func(t *testing.T) {
+			vendorA := match("https://api.vendora.com/users")
+			assert.Equal(t, 5, vendorA.RetriesMax)
+			assert.Equal(t, ExponentialBackoff{Base: 200 * time.Millisecond, Max: 5 * time.Second}, vendorA.Backoff)
+			assert.True(t, vendorA.IsRetryCondition(&http.Response{StatusCode: http.StatusTooManyRequests}, 1))
+			assert.NotNil(t, vendorA.CircuitBreaker)
+
+			vendorB := match("https://eu.vendorb.com/v2/orders")
+			assert.Equal(t, 3, vendorB.RetriesMax)
+			assert.NotNil(t, vendorB.RateLimiter)
+		})
+
+		t.Run("WHEN the file changes AND is reloaded", func(t *testing.T) {
+			breaker := match("https://api.vendora.com/").CircuitBreaker
+			require.NoError(t, os.WriteFile(path, []byte(`
+default:
+  max_retries: 2
+endpoints:
+  - match: api.vendora.com
+    circuit_breaker: {threshold: 10, cooldown: 1m}
+`), 0o600))
+			require.NoError(t, policies.Reload())
+
+			t.Run("THEN the router has the new policies", func(t *testing.T) {
+				assert.Equal(t, 2, match("https://other.com/").RetriesMax)
+				assert.Equal(t, 2, match("https://eu.vendorb.com/v2/orders").RetriesMax)
+			})
+
+			t.Run("AND an unchanged breaker keeps its state", func(t *testing.T) {
+				assert.Same(t, breaker, match("https://api.vendora.com/").CircuitBreaker)
+			})
+		})
+
+		t.Run("WHEN the file becomes invalid AND is reloaded", func(t *testing.T) {
+			require.NoError(t, os.WriteFile(path, []byte("default: {max_retries: many}\n"), 0o600))
+			err := policies.Reload()
+
+			t.Run("THEN the previous policies are kept", func(t *testing.T) {
+				assert.ErrorContains(t, err, path)
+				assert.Equal(t, 2, match("https://other.com/").RetriesMax)
+			})
+		})
+	})
+}
@@ -0,0 +1,55 @@
+package httpretry
+
+import (
+	"context"
+	"net/http"
+)
+
+// Poll gets the configured URL over and over, for example to consume a
+// queue like API, until extractCursor returns false, a poll fails or ctx is
+// cancelled:
+//
+//	err := api.Poll(ctx, func(resp *httpretry.Response) (string, bool) {
+//		var batch Batch
+//		json.Unmarshal(resp.Body, &batch)
+//		handle(batch.Items)
+//		return batch.Cursor, true
+//	})
+//
+// extractCursor returns the cursor of the next poll, sent as the
+// PollCursorParam query parameter.  Every poll is retried like HttpGetFull.
+// A poll returning a new cursor is followed by the next one right away,
+// while polls returning the same cursor wait the backoff, growing with every
+// idle poll, before polling again.  A non 2xx status after retries ends
+// polling with an error, cancelling ctx ends it with ctx.Err().
+func (r httpRequest) Poll(ctx context.Context, extractCursor func(resp *Response) (string, bool), opts ...CallOption) error {
+	cursor := ""
+	for idle := 0; ; {
+		poll := r
+		if cursor != "" {
+			poll = r.WithQuery(r.PollCursorParam, cursor)
+		}
+		resp, err := poll.HttpGetFull(ctx, opts...)
+		if err != nil {
+			return err
+		}
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			return newUnexpectedStatusError([]int{http.StatusOK}, resp, poll.URL)
+		}
+		next, ok := extractCursor(resp)
+		if !ok {
+			return nil
+		}
+		if next != cursor {
+			cursor, idle = next, 0
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			continue
+		}
+		idle++
+		if err := r.clock().Sleep(ctx, r.Backoff.Backoff(idle)); err != nil {
+			return err
+		}
+	}
+}
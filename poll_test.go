@@ -0,0 +1,97 @@
+package httpretry
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntegration_Poll(t *testing.T) {
+
+	t.Run("GIVEN a queue like API idle once and failing once", func(t *testing.T) {
+		var cursors []string
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			cursor := r.URL.Query().Get("after")
+			cursors = append(cursors, cursor)
+			switch {
+			case cursor == "":
+				w.Write([]byte(`{"items":[1],"cursor":"a"}`))
+			case cursor == "a" && len(cursors) == 2:
+				w.Write([]byte(`{"items":[],"cursor":"a"}`))
+			case cursor == "a" && len(cursors) == 3:
+				w.WriteHeader(http.StatusServiceUnavailable)
+			case cursor == "a":
+				w.Write([]byte(`{"items":[2],"cursor":"b"}`))
+			default:
+				w.Write([]byte(`{"items":[],"done":true}`))
+			}
+		}))
+		defer ts.Close()
+
+		url, err := url.Parse(ts.URL)
+		require.NoError(t, err)
+
+		t.Run("WHEN Poll is called", func(t *testing.T) {
+			api := NewHttpRequest(HttpRequestOptions{
+				URL:              url,
+				RetriesWait:      time.Millisecond,
+				IsRetryCondition: RetryOnStatus(http.StatusServiceUnavailable),
+				PollCursorParam:  "after",
+			})
+			var items []int
+			err := api.Poll(context.Background(), func(resp *Response) (string, bool) {
+				var batch struct {
+					Items  []int
+					Cursor string
+					Done   bool
+				}
+				require.NoError(t, json.Unmarshal(resp.Body, &batch))
+				items = append(items, batch.Items...)
+				return batch.Cursor, !batch.Done
+			})
+			require.NoError(t, err)
+
+			t.Run("THEN the cursor is sent with every poll", func(t *testing.T) {
+				assert.Equal(t, []string{"", "a", "a", "a", "b"}, cursors)
+			})
+
+			t.Run("AND every item is received once", func(t *testing.T) {
+				assert.Equal(t, []int{1, 2}, items)
+			})
+		})
+	})
+
+	t.Run("GIVEN an API that is always idle", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{}`))
+		}))
+		defer ts.Close()
+
+		url, err := url.Parse(ts.URL)
+		require.NoError(t, err)
+
+		t.Run("WHEN the context times out", func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+			polls := 0
+			api := NewHttpRequest(HttpRequestOptions{URL: url, RetriesWait: 10 * time.Millisecond})
+			err := api.Poll(ctx, func(resp *Response) (string, bool) {
+				polls++
+				return "", true
+			})
+
+			t.Run("THEN polling waits between idle polls and stops", func(t *testing.T) {
+				assert.ErrorIs(t, err, context.DeadlineExceeded)
+				assert.Greater(t, polls, 1)
+				assert.Less(t, polls, 10)
+			})
+		})
+	})
+}
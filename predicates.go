@@ -0,0 +1,83 @@
+package httpretry
+
+import (
+	"net/http"
+)
+
+// RetryOnStatus retries responses with one of codes.
+func RetryOnStatus(codes ...int) RetryPredicate {
+	retry := make(map[int]bool, len(codes))
+	for _, code := range codes {
+		retry[code] = true
+	}
+	return func(resp *http.Response, retryCount int) bool {
+		return retry[resp.StatusCode]
+	}
+}
+
+// RetryOnStatusClass retries responses whose status is in the class, for
+// example 5 for 500 to 599.
+func RetryOnStatusClass(class int) RetryPredicate {
+	return func(resp *http.Response, retryCount int) bool {
+		return resp.StatusCode/100 == class
+	}
+}
+
+// RetryOnIdempotent retries requests whose method is idempotent, see
+// DefaultRetryPolicy.  Combine it with And to keep POST and PATCH from being
+// retried.
+func RetryOnIdempotent() RetryPredicate {
+	return func(resp *http.Response, retryCount int) bool {
+		return resp.Request != nil && idempotentMethods[resp.Request.Method]
+	}
+}
+
+// RetryOnError adapts an error classifier, for example IsConnectionRefused,
+// to a RetryErrorPredicate.
+func RetryOnError(is func(err error) bool) RetryErrorPredicate {
+	return func(err error, retryCount int) bool {
+		return is(err)
+	}
+}
+
+// RetryOnTimeout retries network and client timeouts, see IsTimeoutError.
+func RetryOnTimeout() RetryErrorPredicate {
+	return RetryOnError(IsTimeoutError)
+}
+
+// And retries when every predicate retries, it works for both RetryPredicate
+// and RetryErrorPredicate:
+//
+//	IsRetryCondition: httpretry.And(httpretry.RetryOnIdempotent(), httpretry.Or(
+//		httpretry.RetryOnStatusClass(5),
+//		httpretry.RetryOnStatus(http.StatusTooManyRequests),
+//	)),
+func And[T any, P ~func(T, int) bool](predicates ...P) P {
+	return func(v T, retryCount int) bool {
+		for _, predicate := range predicates {
+			if !predicate(v, retryCount) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// Or retries when any predicate retries.
+func Or[T any, P ~func(T, int) bool](predicates ...P) P {
+	return func(v T, retryCount int) bool {
+		for _, predicate := range predicates {
+			if predicate(v, retryCount) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// Not retries when predicate doesn't.
+func Not[T any, P ~func(T, int) bool](predicate P) P {
+	return func(v T, retryCount int) bool {
+		return !predicate(v, retryCount)
+	}
+}
@@ -0,0 +1,67 @@
+package httpretry
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryPredicates(t *testing.T) {
+	response := func(method string, code int) *http.Response {
+		return &http.Response{StatusCode: code, Request: &http.Request{Method: method}}
+	}
+
+	t.Run("GIVEN RetryOnStatus", func(t *testing.T) {
+		retry := RetryOnStatus(http.StatusTooManyRequests, http.StatusServiceUnavailable)
+
+		t.Run("THEN only the given codes are retried", func(t *testing.T) {
+			assert.True(t, retry(response(http.MethodGet, 429), 1))
+			assert.True(t, retry(response(http.MethodGet, 503), 1))
+			assert.False(t, retry(response(http.MethodGet, 201), 1))
+		})
+	})
+
+	t.Run("GIVEN RetryOnStatusClass 5", func(t *testing.T) {
+		retry := RetryOnStatusClass(5)
+
+		t.Run("THEN only 5xx are retried", func(t *testing.T) {
+			assert.True(t, retry(response(http.MethodGet, 500), 1))
+			assert.True(t, retry(response(http.MethodGet, 599), 1))
+			assert.False(t, retry(response(http.MethodGet, 499), 1))
+		})
+	})
+
+	t.Run("GIVEN composed predicates", func(t *testing.T) {
+		retry := And(RetryOnIdempotent(), Or(
+			RetryOnStatusClass(5),
+			RetryOnStatus(http.StatusTooManyRequests),
+		), Not(RetryOnStatus(http.StatusNotImplemented)))
+
+		t.Run("THEN they are evaluated as composed", func(t *testing.T) {
+			assert.True(t, retry(response(http.MethodGet, 503), 1))
+			assert.True(t, retry(response(http.MethodPut, 429), 1))
+			assert.False(t, retry(response(http.MethodPost, 503), 1))
+			assert.False(t, retry(response(http.MethodGet, 501), 1))
+			assert.False(t, retry(response(http.MethodGet, 404), 1))
+		})
+	})
+
+	t.Run("GIVEN composed error predicates", func(t *testing.T) {
+		retry := Or(RetryOnTimeout(), RetryOnError(IsConnectionRefused))
+		ctx, cancel := context.WithTimeout(context.Background(), 0)
+		defer cancel()
+		_, timeoutErr := (&net.Dialer{}).DialContext(ctx, "tcp", "127.0.0.1:1")
+
+		t.Run("THEN timeouts and refused connections are retried", func(t *testing.T) {
+			assert.True(t, retry(timeoutErr, 1))
+			assert.True(t, retry(syscall.ECONNREFUSED, 1))
+			assert.False(t, retry(errors.New("tls: bad certificate"), 1))
+			assert.True(t, Not(RetryOnTimeout())(errors.New("other"), 1))
+		})
+	})
+}
@@ -0,0 +1,109 @@
+package httpretry
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+)
+
+// ProblemContentType is the media type of RFC 7807 Problem Details.
+const ProblemContentType = "application/problem+json"
+
+// ProblemDetails is an RFC 7807 problem+json response body, it is wrapped in
+// the errors helpers return for a non 2xx response:
+//
+//	var problem *httpretry.ProblemDetails
+//	if errors.As(err, &problem) && problem.Type == "https://example.com/probs/out-of-credit" {
+//		...
+//	}
+//
+// See https://www.rfc-editor.org/rfc/rfc7807
+type ProblemDetails struct {
+	// Type is a URI identifying the problem, "" means about:blank
+	Type     string `json:"type,omitempty"`
+	Title    string `json:"title,omitempty"`
+	Status   int    `json:"status,omitempty"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+
+	// Extensions are the members of the problem not defined by the RFC
+	Extensions map[string]any `json:"-"`
+}
+
+func (p *ProblemDetails) UnmarshalJSON(data []byte) error {
+	type members ProblemDetails
+	if err := json.Unmarshal(data, (*members)(p)); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, &p.Extensions); err != nil {
+		return err
+	}
+	for _, member := range []string{"type", "title", "status", "detail", "instance"} {
+		delete(p.Extensions, member)
+	}
+	if len(p.Extensions) == 0 {
+		p.Extensions = nil
+	}
+	return nil
+}
+
+func (p *ProblemDetails) Error() string {
+	msg := "problem: " + p.Title
+	if p.Detail != "" {
+		msg += ": " + p.Detail
+	}
+	if p.Type != "" {
+		msg += " (" + p.Type + ")"
+	}
+	return msg
+}
+
+// ParseProblemDetails returns the problem of a response with a
+// problem+json Content-Type, nil for other responses.
+func ParseProblemDetails(header http.Header, body []byte) *ProblemDetails {
+	mediaType, _, _ := mime.ParseMediaType(header.Get("Content-Type"))
+	if mediaType != ProblemContentType || len(body) == 0 {
+		return nil
+	}
+	var problem ProblemDetails
+	if json.Unmarshal(body, &problem) != nil {
+		return nil
+	}
+	return &problem
+}
+
+// ProblemDetails returns the problem of the response, nil when it isn't
+// problem+json.
+func (r *Response) ProblemDetails() *ProblemDetails {
+	return ParseProblemDetails(r.Header, r.Body)
+}
+
+// ProblemDetailsFromResponse returns the problem of resp for retry
+// predicates, nil when it isn't problem+json.  The body is left readable.
+func ProblemDetailsFromResponse(resp *http.Response) *ProblemDetails {
+	if mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type")); mediaType != ProblemContentType || resp.Body == nil {
+		return nil
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return nil
+	}
+	return ParseProblemDetails(resp.Header, body)
+}
+
+// RetryOnProblemType retries problem+json responses with one of types, for
+// example a temporarily_unavailable problem answered with a 400.
+func RetryOnProblemType(types ...string) RetryPredicate {
+	retry := make(map[string]bool, len(types))
+	for _, t := range types {
+		retry[t] = true
+	}
+	return func(resp *http.Response, retryCount int) bool {
+		problem := ProblemDetailsFromResponse(resp)
+		return problem != nil && retry[problem.Type]
+	}
+}
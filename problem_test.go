@@ -0,0 +1,92 @@
+package httpretry
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseProblemDetails(t *testing.T) {
+
+	t.Run("GIVEN a problem+json body", func(t *testing.T) {
+		header := http.Header{"Content-Type": {"application/problem+json; charset=utf-8"}}
+		body := []byte(`{"type":"https://example.com/probs/out-of-credit","title":"You do not have enough credit.","status":403,"detail":"Your balance is 30, but that costs 50.","balance":30}`)
+
+		t.Run("WHEN it is parsed", func(t *testing.T) {
+			problem := ParseProblemDetails(header, body)
+
+			t.Run("THEN the members are decoded", func(t *testing.T) {
+				require.NotNil(t, problem)
+				assert.Equal(t, "https://example.com/probs/out-of-credit", problem.Type)
+				assert.Equal(t, http.StatusForbidden, problem.Status)
+				assert.Equal(t, "Your balance is 30, but that costs 50.", problem.Detail)
+			})
+
+			t.Run("AND extension members are kept", func(t *testing.T) {
+				assert.Equal(t, map[string]any{"balance": float64(30)}, problem.Extensions)
+			})
+		})
+	})
+
+	t.Run("GIVEN a JSON body with another content type", func(t *testing.T) {
+		header := http.Header{"Content-Type": {"application/json"}}
+
+		t.Run("THEN it isn't a problem", func(t *testing.T) {
+			assert.Nil(t, ParseProblemDetails(header, []byte(`{"title":"x"}`)))
+		})
+	})
+}
+
+func TestIntegration_ProblemDetails(t *testing.T) {
+
+	t.Run("GIVEN a server answering problems", func(t *testing.T) {
+		calls := 0
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			w.Header().Set("Content-Type", ProblemContentType)
+			if calls == 1 {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(`{"type":"temporarily_unavailable","title":"Try again"}`))
+				return
+			}
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"type":"out_of_credit","title":"Not enough credit","status":403}`))
+		}))
+		defer ts.Close()
+
+		url, err := url.Parse(ts.URL)
+		require.NoError(t, err)
+		api := NewHttpRequest(HttpRequestOptions{
+			URL:              url,
+			RetriesWait:      time.Millisecond,
+			IsRetryCondition: RetryOnProblemType("temporarily_unavailable"),
+		})
+
+		t.Run("WHEN a helper is called", func(t *testing.T) {
+			_, resp, err := api.CreatedLocation(context.Background(), nil)
+
+			t.Run("THEN the temporary problem is retried", func(t *testing.T) {
+				assert.Equal(t, 2, calls)
+				assert.Equal(t, 2, resp.Attempts)
+			})
+
+			t.Run("AND the final problem is wrapped in the error", func(t *testing.T) {
+				var problem *ProblemDetails
+				require.True(t, errors.As(err, &problem))
+				assert.Equal(t, "out_of_credit", problem.Type)
+				assert.Equal(t, "out_of_credit", resp.ProblemDetails().Type)
+			})
+
+			t.Run("AND the body is still returned", func(t *testing.T) {
+				assert.Contains(t, string(resp.Body), "Not enough credit")
+			})
+		})
+	})
+}
@@ -0,0 +1,175 @@
+package httpretry
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultLatencyBuckets are the histogram buckets in seconds used by
+// PrometheusMetrics, the same defaults as the Prometheus client libraries.
+var DefaultLatencyBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// PrometheusMetrics is a MetricsCollector that serves its metrics in the
+// Prometheus text exposition format.  It is an http.Handler so it can be
+// mounted next to other metrics:
+//
+//	metrics := httpretry.NewPrometheusMetrics("myapp")
+//	http.Handle("/metrics/httpretry", metrics)
+//
+// Exposed metrics, labeled by method and host:
+//
+//   - <namespace>_httpretry_requests_total calls made, retries excluded
+//   - <namespace>_httpretry_retries_total attempts that were retried
+//   - <namespace>_httpretry_responses_total attempts by status class
+//   - <namespace>_httpretry_attempt_duration_seconds histogram per attempt
+//   - <namespace>_httpretry_call_duration_seconds histogram per call
+//
+// It keeps this package free of the Prometheus client dependency, to register
+// with an existing prometheus.Registry implement MetricsCollector with
+// client_golang vectors instead.
+type PrometheusMetrics struct {
+	mu        sync.Mutex
+	prefix    string
+	buckets   []float64
+	requests  map[metricLabels]float64
+	retries   map[metricLabels]float64
+	responses map[metricLabels]float64
+	attempts  map[metricLabels]*histogram
+	calls     map[metricLabels]*histogram
+}
+
+type metricLabels struct {
+	method string
+	host   string
+	class  string
+}
+
+func (l metricLabels) String() string {
+	labels := fmt.Sprintf(`host="%s",method="%s"`, escapeLabel(l.host), escapeLabel(l.method))
+	if l.class != "" {
+		labels = fmt.Sprintf(`class="%s",%s`, escapeLabel(l.class), labels)
+	}
+	return labels
+}
+
+type histogram struct {
+	counts []uint64
+	sum    float64
+	count  uint64
+}
+
+// NewPrometheusMetrics returns a collector whose metric names start with
+// namespace, pass an empty namespace to name them httpretry_*.
+func NewPrometheusMetrics(namespace string) *PrometheusMetrics {
+	prefix := "httpretry"
+	if namespace != "" {
+		prefix = namespace + "_" + prefix
+	}
+	return &PrometheusMetrics{
+		prefix:    prefix,
+		buckets:   DefaultLatencyBuckets,
+		requests:  map[metricLabels]float64{},
+		retries:   map[metricLabels]float64{},
+		responses: map[metricLabels]float64{},
+		attempts:  map[metricLabels]*histogram{},
+		calls:     map[metricLabels]*histogram{},
+	}
+}
+
+func (m *PrometheusMetrics) ObserveAttempt(method string, host string, statusCode int, err error, duration time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.responses[metricLabels{method: method, host: host, class: StatusClass(statusCode, err)}]++
+	m.observe(m.attempts, metricLabels{method: method, host: host}, duration)
+}
+
+func (m *PrometheusMetrics) ObserveRetry(method string, host string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.retries[metricLabels{method: method, host: host}]++
+}
+
+func (m *PrometheusMetrics) ObserveCall(method string, host string, statusCode int, err error, duration time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.requests[metricLabels{method: method, host: host}]++
+	m.observe(m.calls, metricLabels{method: method, host: host}, duration)
+}
+
+func (m *PrometheusMetrics) observe(histograms map[metricLabels]*histogram, labels metricLabels, duration time.Duration) {
+	h, ok := histograms[labels]
+	if !ok {
+		h = &histogram{counts: make([]uint64, len(m.buckets))}
+		histograms[labels] = h
+	}
+	seconds := duration.Seconds()
+	for i, bound := range m.buckets {
+		if seconds <= bound {
+			h.counts[i]++
+		}
+	}
+	h.sum += seconds
+	h.count++
+}
+
+func (m *PrometheusMetrics) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	m.WriteTo(w)
+}
+
+// WriteTo writes all metrics in the Prometheus text exposition format.
+func (m *PrometheusMetrics) WriteTo(w io.Writer) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var b strings.Builder
+	m.writeCounter(&b, "requests_total", "Calls made, retries excluded.", m.requests)
+	m.writeCounter(&b, "retries_total", "Attempts that were retried.", m.retries)
+	m.writeCounter(&b, "responses_total", "Attempts by response status class.", m.responses)
+	m.writeHistogram(&b, "attempt_duration_seconds", "Duration of a single attempt.", m.attempts)
+	m.writeHistogram(&b, "call_duration_seconds", "Duration of a call including retries.", m.calls)
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
+
+func (m *PrometheusMetrics) writeCounter(b *strings.Builder, name string, help string, counters map[metricLabels]float64) {
+	name = m.prefix + "_" + name
+	fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s counter\n", name, help, name)
+	for _, labels := range sortedLabels(counters) {
+		fmt.Fprintf(b, "%s{%s} %v\n", name, labels, counters[labels])
+	}
+}
+
+func (m *PrometheusMetrics) writeHistogram(b *strings.Builder, name string, help string, histograms map[metricLabels]*histogram) {
+	name = m.prefix + "_" + name
+	fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s histogram\n", name, help, name)
+	for _, labels := range sortedLabels(histograms) {
+		h := histograms[labels]
+		for i, bound := range m.buckets {
+			fmt.Fprintf(b, "%s_bucket{%s,le=\"%v\"} %d\n", name, labels, bound, h.counts[i])
+		}
+		fmt.Fprintf(b, "%s_bucket{%s,le=\"+Inf\"} %d\n", name, labels, h.count)
+		fmt.Fprintf(b, "%s_sum{%s} %v\n", name, labels, h.sum)
+		fmt.Fprintf(b, "%s_count{%s} %d\n", name, labels, h.count)
+	}
+}
+
+func sortedLabels[V any](metrics map[metricLabels]V) []metricLabels {
+	labels := make([]metricLabels, 0, len(metrics))
+	for l := range metrics {
+		labels = append(labels, l)
+	}
+	sort.Slice(labels, func(i, j int) bool {
+		return labels[i].String() < labels[j].String()
+	})
+	return labels
+}
+
+func escapeLabel(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
@@ -0,0 +1,65 @@
+package httpretry
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntegration_PrometheusMetrics(t *testing.T) {
+
+	t.Run("GIVEN a server that returns 503 once", func(t *testing.T) {
+		attempts := 1
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if attempts > 0 {
+				attempts--
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer ts.Close()
+
+		url, err := url.Parse(ts.URL)
+		require.NoError(t, err)
+
+		t.Run("AND http request with prometheus metrics", func(t *testing.T) {
+			metrics := NewPrometheusMetrics("test")
+			api := NewHttpRequest(HttpRequestOptions{
+				URL:         url,
+				RetriesWait: time.Millisecond,
+				Metrics:     metrics,
+				IsRetryCondition: func(resp *http.Response, retryCount int) bool {
+					return resp.StatusCode == http.StatusServiceUnavailable
+				},
+			})
+
+			t.Run("WHEN HttpGet is sent AND metrics are scraped", func(t *testing.T) {
+				_, _, err := api.HttpGet(context.Background())
+				require.NoError(t, err)
+
+				rec := httptest.NewRecorder()
+				metrics.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+				body := rec.Body.String()
+
+				t.Run("THEN calls, retries and status classes are counted", func(t *testing.T) {
+					labels := `host="` + url.Host + `",method="GET"`
+					assert.Contains(t, body, "test_httpretry_requests_total{"+labels+"} 1\n")
+					assert.Contains(t, body, "test_httpretry_retries_total{"+labels+"} 1\n")
+					assert.Contains(t, body, `test_httpretry_responses_total{class="2xx",`+labels+"} 1\n")
+					assert.Contains(t, body, `test_httpretry_responses_total{class="5xx",`+labels+"} 1\n")
+					assert.Contains(t, body, "test_httpretry_attempt_duration_seconds_count{"+labels+"} 2\n")
+					assert.Contains(t, body, "test_httpretry_call_duration_seconds_bucket{"+labels+`,le="+Inf"} 1`+"\n")
+					assert.True(t, strings.HasPrefix(rec.Header().Get("Content-Type"), "text/plain"))
+				})
+			})
+		})
+	})
+}
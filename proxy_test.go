@@ -0,0 +1,69 @@
+package httpretry
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntegration_Proxy(t *testing.T) {
+
+	t.Run("GIVEN a server AND two egress proxies", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("direct"))
+		}))
+		defer ts.Close()
+		newProxy := func(name string) *url.URL {
+			proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(name + " " + r.URL.String()))
+			}))
+			t.Cleanup(proxy.Close)
+			u, err := url.Parse(proxy.URL)
+			require.NoError(t, err)
+			return u
+		}
+		eu, us := newProxy("eu"), newProxy("us")
+
+		u, err := url.Parse(ts.URL)
+		require.NoError(t, err)
+
+		t.Run("WHEN HttpGet is sent with a ProxyURL", func(t *testing.T) {
+			api := NewHttpRequest(HttpRequestOptions{URL: u, ProxyURL: eu})
+			body, _, err := api.HttpGet(context.Background())
+			require.NoError(t, err)
+
+			t.Run("THEN the proxy receives it", func(t *testing.T) {
+				assert.Equal(t, "eu "+ts.URL+"/", string(body))
+			})
+		})
+
+		t.Run("WHEN HttpGet is sent with a ProxyFunc AND a ProxyURL", func(t *testing.T) {
+			api := NewHttpRequest(HttpRequestOptions{
+				URL:       u,
+				ProxyURL:  eu,
+				ProxyFunc: func(*http.Request) (*url.URL, error) { return us, nil },
+			})
+			body, _, err := api.HttpGet(context.Background())
+			require.NoError(t, err)
+
+			t.Run("THEN the proxy chosen by ProxyFunc receives it", func(t *testing.T) {
+				assert.Equal(t, "us "+ts.URL+"/", string(body))
+			})
+		})
+
+		t.Run("WHEN HttpGet is sent without proxy", func(t *testing.T) {
+			api := NewHttpRequest(HttpRequestOptions{URL: u})
+			body, _, err := api.HttpGet(context.Background())
+			require.NoError(t, err)
+
+			t.Run("THEN the server receives it directly", func(t *testing.T) {
+				assert.Equal(t, "direct", string(body))
+			})
+		})
+	})
+}
@@ -0,0 +1,33 @@
+package httpretry
+
+import (
+	"net/url"
+)
+
+// WithQuery returns a copy of the request with value added to the query
+// parameter key of its URL, the configured URL is left untouched:
+//
+//	body, status, err := api.WithQuery("page", "2").HttpGet(ctx)
+func (r httpRequest) WithQuery(key string, value string) httpRequest {
+	query := r.URL.Query()
+	query.Add(key, value)
+	return r.withRawQuery(query)
+}
+
+// SetQueryParams returns a copy of the request with the query parameters of
+// its URL merged with params, the values of params replace existing values
+// of the same keys.
+func (r httpRequest) SetQueryParams(params url.Values) httpRequest {
+	query := r.URL.Query()
+	for key, values := range params {
+		query[key] = append([]string(nil), values...)
+	}
+	return r.withRawQuery(query)
+}
+
+func (r httpRequest) withRawQuery(query url.Values) httpRequest {
+	u := *r.URL
+	u.RawQuery = query.Encode()
+	r.URL = &u
+	return r
+}
@@ -0,0 +1,63 @@
+package httpretry
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryParams(t *testing.T) {
+
+	t.Run("GIVEN http request with a query in its URL", func(t *testing.T) {
+		u, err := url.Parse("https://api.example.com/things?filter=new")
+		require.NoError(t, err)
+		api := NewHttpRequest(HttpRequestOptions{URL: u})
+
+		t.Run("WHEN WithQuery is called twice for a key", func(t *testing.T) {
+			paged := api.WithQuery("page", "1").WithQuery("page", "2")
+
+			t.Run("THEN both values are added to a copy of the URL", func(t *testing.T) {
+				assert.Equal(t, "https://api.example.com/things?filter=new&page=1&page=2", paged.URL.String())
+				assert.Equal(t, "https://api.example.com/things?filter=new", api.URL.String())
+			})
+		})
+
+		t.Run("WHEN SetQueryParams is called", func(t *testing.T) {
+			filtered := api.SetQueryParams(url.Values{"filter": {"old"}, "sort": {"name"}})
+
+			t.Run("THEN existing keys are replaced AND new keys are added", func(t *testing.T) {
+				assert.Equal(t, "https://api.example.com/things?filter=old&sort=name", filtered.URL.String())
+				assert.Equal(t, "https://api.example.com/things?filter=new", api.URL.String())
+			})
+		})
+	})
+}
+
+func TestIntegration_WithQuery(t *testing.T) {
+
+	t.Run("GIVEN a server", func(t *testing.T) {
+		var query url.Values
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			query = r.URL.Query()
+		}))
+		defer ts.Close()
+
+		url, err := url.Parse(ts.URL)
+		require.NoError(t, err)
+
+		t.Run("WHEN HttpGet is sent with a query parameter", func(t *testing.T) {
+			api := NewHttpRequest(HttpRequestOptions{URL: url})
+			_, _, err := api.WithQuery("page", "2").HttpGet(context.Background())
+			require.NoError(t, err)
+
+			t.Run("THEN the server receives it", func(t *testing.T) {
+				assert.Equal(t, "2", query.Get("page"))
+			})
+		})
+	})
+}
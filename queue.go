@@ -0,0 +1,384 @@
+package httpretry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// QueuedRequest is a request waiting for delivery, or a dead letter.
+type QueuedRequest struct {
+	ID         string      `json:"id"`
+	Method     string      `json:"method"`
+	URL        string      `json:"url"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       []byte      `json:"body,omitempty"`
+	EnqueuedAt time.Time   `json:"enqueued_at"`
+
+	// Deliveries counts the delivery rounds, each one retried like DoFull
+	Deliveries   int       `json:"deliveries"`
+	NextDelivery time.Time `json:"next_delivery"`
+	LastStatus   int       `json:"last_status,omitempty"`
+	LastError    string    `json:"last_error,omitempty"`
+}
+
+// QueueStore persists the queue so requests survive restarts.
+type QueueStore interface {
+	Load() (pending []QueuedRequest, dead []QueuedRequest, err error)
+	Save(pending []QueuedRequest, dead []QueuedRequest) error
+}
+
+// FileQueueStore keeps the queue in a JSON file rewritten on every change,
+// parent directories are created on first write.
+type FileQueueStore struct {
+	Path string
+}
+
+type queueFile struct {
+	Pending []QueuedRequest `json:"pending"`
+	Dead    []QueuedRequest `json:"dead"`
+}
+
+func (s FileQueueStore) Load() ([]QueuedRequest, []QueuedRequest, error) {
+	data, err := os.ReadFile(s.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	var file queueFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, nil, err
+	}
+	return file.Pending, file.Dead, nil
+}
+
+func (s FileQueueStore) Save(pending []QueuedRequest, dead []QueuedRequest) error {
+	data, err := json.MarshalIndent(queueFile{Pending: pending, Dead: dead}, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.Path), 0o755); err != nil {
+		return err
+	}
+	// write then rename so a crash never leaves a truncated queue
+	tmp := s.Path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.Path)
+}
+
+type QueueOptions struct {
+	// Workers deliver requests concurrently
+	// defaults to 1, preserving the order of delivery
+	Workers int
+
+	// MaxDeliveries dead-letters a request after that many failed delivery
+	// rounds
+	// defaults to 5
+	MaxDeliveries int
+
+	// DeliveryBackoff is the wait before the next round of a failed request
+	// defaults to an exponential backoff from 1 second to 5 minutes
+	DeliveryBackoff BackoffStrategy
+
+	// Store persists the queue
+	// defaults to memory only
+	Store QueueStore
+}
+
+// Queue buffers outbound requests and delivers them in the background, for
+// example on edge devices with intermittent connectivity.  A delivery round
+// is retried like DoFull, a request failing MaxDeliveries rounds or
+// answered with a 4xx status, except 408 and 429, is moved to the dead
+// letters.  It is safe for concurrent use.
+type Queue struct {
+	r       httpRequest
+	options QueueOptions
+
+	mu       sync.Mutex
+	pending  []QueuedRequest
+	dead     []QueuedRequest
+	inFlight map[string]bool
+	wake     chan struct{}
+}
+
+// NewQueue returns a queue delivering with the retry policy, client and
+// headers of the request, loading the requests left in options.Store.
+func (r httpRequest) NewQueue(options QueueOptions) (*Queue, error) {
+	if options.Workers <= 0 {
+		options.Workers = 1
+	}
+	if options.MaxDeliveries <= 0 {
+		options.MaxDeliveries = 5
+	}
+	if options.DeliveryBackoff == nil {
+		options.DeliveryBackoff = ExponentialBackoff{Base: time.Second, Max: 5 * time.Minute}
+	}
+	q := &Queue{
+		r:        r,
+		options:  options,
+		inFlight: map[string]bool{},
+		wake:     make(chan struct{}, 1),
+	}
+	if options.Store != nil {
+		var err error
+		if q.pending, q.dead, err = options.Store.Load(); err != nil {
+			return nil, err
+		}
+	}
+	return q, nil
+}
+
+// Enqueue adds a request to the configured URL and returns its ID.  It
+// fails only when the store can't be written.
+func (q *Queue) Enqueue(method string, body []byte, header http.Header) (string, error) {
+	return q.EnqueueURL(method, q.r.URL, body, header)
+}
+
+// EnqueueURL is Enqueue to another URL.
+func (q *Queue) EnqueueURL(method string, u *url.URL, body []byte, header http.Header) (string, error) {
+	now := time.Now()
+	request := QueuedRequest{
+		ID:           uuid.New().String(),
+		Method:       method,
+		URL:          u.String(),
+		Header:       header.Clone(),
+		Body:         body,
+		EnqueuedAt:   now,
+		NextDelivery: now,
+	}
+	q.mu.Lock()
+	q.pending = append(q.pending, request)
+	err := q.save()
+	q.mu.Unlock()
+	q.notify()
+	return request.ID, err
+}
+
+// Run delivers requests as they become due until ctx is cancelled.
+func (q *Queue) Run(ctx context.Context) error {
+	var wg sync.WaitGroup
+	for w := 0; w < q.options.Workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			q.work(ctx)
+		}()
+	}
+	wg.Wait()
+	return ctx.Err()
+}
+
+func (q *Queue) work(ctx context.Context) {
+	for ctx.Err() == nil {
+		request, wait, ok := q.next(time.Now(), nil)
+		if ok {
+			q.deliver(ctx, request)
+			continue
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+		case <-q.wake:
+			// pass the wake up on in case other workers are idle too
+			q.notify()
+		case <-timer.C:
+		}
+		timer.Stop()
+	}
+}
+
+// Flush tries every pending request once right away, ignoring their next
+// delivery time, for example when connectivity is back.  It returns the
+// number of requests still pending.
+func (q *Queue) Flush(ctx context.Context) (int, error) {
+	flushed := map[string]bool{}
+	for ctx.Err() == nil {
+		request, _, ok := q.next(time.Now(), flushed)
+		if !ok {
+			break
+		}
+		flushed[request.ID] = true
+		q.deliver(ctx, request)
+	}
+	return q.Len(), ctx.Err()
+}
+
+// Len returns the number of pending requests.
+func (q *Queue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.pending)
+}
+
+// DeadLetters returns a copy of the requests that couldn't be delivered.
+func (q *Queue) DeadLetters() []QueuedRequest {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return append([]QueuedRequest(nil), q.dead...)
+}
+
+// Requeue moves a dead letter back to the pending requests with its
+// delivery count reset, it reports whether id was a dead letter.
+func (q *Queue) Requeue(id string) (bool, error) {
+	q.mu.Lock()
+	defer q.notify()
+	defer q.mu.Unlock()
+	for i, request := range q.dead {
+		if request.ID != id {
+			continue
+		}
+		q.dead = append(q.dead[:i:i], q.dead[i+1:]...)
+		request.Deliveries, request.NextDelivery = 0, time.Now()
+		q.pending = append(q.pending, request)
+		return true, q.save()
+	}
+	return false, nil
+}
+
+// DiscardDeadLetter drops a dead letter, it reports whether id was one.
+func (q *Queue) DiscardDeadLetter(id string) (bool, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for i, request := range q.dead {
+		if request.ID == id {
+			q.dead = append(q.dead[:i:i], q.dead[i+1:]...)
+			return true, q.save()
+		}
+	}
+	return false, nil
+}
+
+// next returns the first due pending request that isn't being delivered,
+// or how long to wait for one.  When flushing, every request not yet
+// flushed is due.
+func (q *Queue) next(now time.Time, flushed map[string]bool) (QueuedRequest, time.Duration, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	wait := time.Hour
+	for _, request := range q.pending {
+		if q.inFlight[request.ID] || flushed[request.ID] {
+			continue
+		}
+		if flushed == nil && request.NextDelivery.After(now) {
+			if until := request.NextDelivery.Sub(now); until < wait {
+				wait = until
+			}
+			continue
+		}
+		q.inFlight[request.ID] = true
+		return request, 0, true
+	}
+	return QueuedRequest{}, wait, false
+}
+
+func (q *Queue) deliver(ctx context.Context, request QueuedRequest) {
+	resp, err := q.send(ctx, request)
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	delete(q.inFlight, request.ID)
+	if ctx.Err() != nil {
+		// cancelled, not a failed delivery
+		return
+	}
+	index := -1
+	for i := range q.pending {
+		if q.pending[i].ID == request.ID {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		return
+	}
+	if err == nil && resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		q.pending = append(q.pending[:index:index], q.pending[index+1:]...)
+		q.save()
+		return
+	}
+
+	request.Deliveries++
+	request.LastError, request.LastStatus = "", 0
+	if err != nil {
+		request.LastError = err.Error()
+	} else {
+		request.LastStatus = resp.StatusCode
+	}
+	if (err == nil && isPermanentStatus(resp.StatusCode)) || request.Deliveries >= q.options.MaxDeliveries {
+		q.pending = append(q.pending[:index:index], q.pending[index+1:]...)
+		q.dead = append(q.dead, request)
+		q.r.logRetry(LogLevelWarn, "Request dead-lettered", Fields{
+			"method":  request.Method,
+			"url":     request.URL,
+			"attempt": request.Deliveries,
+			"status":  request.LastStatus,
+			"error":   request.LastError,
+		})
+	} else {
+		request.NextDelivery = time.Now().Add(q.options.DeliveryBackoff.Backoff(request.Deliveries))
+		q.pending[index] = request
+	}
+	q.save()
+}
+
+func (q *Queue) send(ctx context.Context, request QueuedRequest) (*Response, error) {
+	r := q.r
+	u, err := url.Parse(request.URL)
+	if err != nil {
+		return nil, err
+	}
+	r.URL = u
+	opts := []CallOption{func(r *httpRequest) {
+		r.Header = r.Header.Clone()
+		if r.Header == nil {
+			r.Header = http.Header{}
+		}
+		for name, values := range request.Header {
+			r.Header[name] = append([]string(nil), values...)
+		}
+	}}
+	if r.AddIdempotencyKey && request.Header.Get(IdempotencyKeyHeader) == "" {
+		// the same key for every round so the upstream can deduplicate
+		opts = append(opts, WithHeader(IdempotencyKeyHeader, request.ID))
+	}
+	return r.DoFull(ctx, request.Method, request.Body, opts...)
+}
+
+// save must be called with mu held.  A failing store keeps the queue in
+// memory, the error is logged.
+func (q *Queue) save() error {
+	if q.options.Store == nil {
+		return nil
+	}
+	err := q.options.Store.Save(q.pending, q.dead)
+	if err != nil {
+		q.r.logger().Errorf("Saving request queue failed: %v", err)
+	}
+	return err
+}
+
+func (q *Queue) notify() {
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+// isPermanentStatus reports whether delivering again can't succeed, for 4xx
+// statuses but timeouts and rate limiting.
+func isPermanentStatus(statusCode int) bool {
+	return statusCode >= 400 && statusCode < 500 &&
+		statusCode != http.StatusRequestTimeout && statusCode != http.StatusTooManyRequests
+}
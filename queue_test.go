@@ -0,0 +1,141 @@
+package httpretry
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntegration_Queue(t *testing.T) {
+
+	t.Run("GIVEN an upstream that is offline", func(t *testing.T) {
+		var mu sync.Mutex
+		online := false
+		var received []string
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			defer mu.Unlock()
+			if !online {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			body, _ := io.ReadAll(r.Body)
+			received = append(received, r.Header.Get("X-Event")+" "+string(body)+" "+r.Header.Get(IdempotencyKeyHeader))
+		}))
+		defer ts.Close()
+
+		url, err := url.Parse(ts.URL)
+		require.NoError(t, err)
+		api := NewHttpRequest(HttpRequestOptions{
+			URL:               url,
+			RetriesMax:        1,
+			IsRetryCondition:  RetryOnStatus(http.StatusServiceUnavailable),
+			AddIdempotencyKey: true,
+		})
+		store := FileQueueStore{Path: filepath.Join(t.TempDir(), "queue", "outbox.json")}
+
+		t.Run("WHEN requests are enqueued and flushed", func(t *testing.T) {
+			queue, err := api.NewQueue(QueueOptions{Store: store})
+			require.NoError(t, err)
+			first, err := queue.Enqueue(http.MethodPost, []byte(`1`), http.Header{"X-Event": {"created"}})
+			require.NoError(t, err)
+			second, err := queue.Enqueue(http.MethodPost, []byte(`2`), http.Header{"X-Event": {"updated"}})
+			require.NoError(t, err)
+			pending, err := queue.Flush(context.Background())
+			require.NoError(t, err)
+
+			t.Run("THEN the requests stay pending", func(t *testing.T) {
+				assert.Equal(t, 2, pending)
+			})
+
+			t.Run("AND they survive a restart", func(t *testing.T) {
+				restarted, err := api.NewQueue(QueueOptions{Store: store})
+				require.NoError(t, err)
+				assert.Equal(t, 2, restarted.Len())
+				assert.Equal(t, 1, restarted.pending[0].Deliveries)
+				assert.Equal(t, http.StatusServiceUnavailable, restarted.pending[0].LastStatus)
+
+				t.Run("WHEN the upstream is back AND the queue is flushed", func(t *testing.T) {
+					mu.Lock()
+					online = true
+					mu.Unlock()
+					pending, err := restarted.Flush(context.Background())
+					require.NoError(t, err)
+
+					t.Run("THEN every request is delivered in order", func(t *testing.T) {
+						assert.Equal(t, 0, pending)
+						assert.Equal(t, []string{"created 1 " + first, "updated 2 " + second}, received)
+					})
+				})
+			})
+		})
+	})
+
+	t.Run("GIVEN an upstream rejecting requests", func(t *testing.T) {
+		var mu sync.Mutex
+		status := http.StatusBadRequest
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			defer mu.Unlock()
+			w.WriteHeader(status)
+		}))
+		defer ts.Close()
+
+		url, err := url.Parse(ts.URL)
+		require.NoError(t, err)
+		api := NewHttpRequest(HttpRequestOptions{URL: url, RetriesMax: 1})
+		queue, err := api.NewQueue(QueueOptions{MaxDeliveries: 2, DeliveryBackoff: ConstantBackoff{}})
+		require.NoError(t, err)
+
+		t.Run("WHEN a request is answered with 400", func(t *testing.T) {
+			id, err := queue.Enqueue(http.MethodPut, []byte(`{}`), nil)
+			require.NoError(t, err)
+			_, err = queue.Flush(context.Background())
+			require.NoError(t, err)
+
+			t.Run("THEN it is dead-lettered right away", func(t *testing.T) {
+				dead := queue.DeadLetters()
+				require.Len(t, dead, 1)
+				assert.Equal(t, id, dead[0].ID)
+				assert.Equal(t, http.StatusBadRequest, dead[0].LastStatus)
+				assert.Equal(t, 0, queue.Len())
+			})
+
+			t.Run("WHEN it is requeued AND keeps failing with 503", func(t *testing.T) {
+				mu.Lock()
+				status = http.StatusServiceUnavailable
+				mu.Unlock()
+				requeued, err := queue.Requeue(id)
+				require.NoError(t, err)
+				assert.True(t, requeued)
+
+				ctx, cancel := context.WithCancel(context.Background())
+				done := make(chan error)
+				go func() { done <- queue.Run(ctx) }()
+
+				t.Run("THEN it is dead-lettered after MaxDeliveries", func(t *testing.T) {
+					assert.Eventually(t, func() bool { return len(queue.DeadLetters()) == 1 }, time.Second, time.Millisecond)
+					assert.Equal(t, 2, queue.DeadLetters()[0].Deliveries)
+					cancel()
+					assert.ErrorIs(t, <-done, context.Canceled)
+				})
+
+				t.Run("AND it can be discarded", func(t *testing.T) {
+					discarded, err := queue.DiscardDeadLetter(id)
+					require.NoError(t, err)
+					assert.True(t, discarded)
+					assert.Empty(t, queue.DeadLetters())
+				})
+			})
+		})
+	})
+}
@@ -0,0 +1,68 @@
+package httpretry
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+// RateLimiter throttles attempts client-side, Wait blocks until the next
+// attempt may be sent or ctx is done.  *rate.Limiter from
+// golang.org/x/time/rate implements it as is.
+type RateLimiter interface {
+	Wait(ctx context.Context) error
+}
+
+// tokenBucketLimiter is a dependency free RateLimiter.
+type tokenBucketLimiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	burst    float64
+	tokens   float64
+	last     time.Time
+}
+
+// NewRateLimiter allows perSecond attempts on average with bursts of up to
+// burst attempts.  Share one limiter between requests to the same upstream.
+// perSecond must be positive and finite.
+func NewRateLimiter(perSecond float64, burst int) (RateLimiter, error) {
+	if !(perSecond > 0) || math.IsInf(perSecond, 1) {
+		return nil, fmt.Errorf("invalid rate limit %v per second", perSecond)
+	}
+	if burst < 1 {
+		burst = 1
+	}
+	return &tokenBucketLimiter{
+		interval: time.Duration(float64(time.Second) / perSecond),
+		burst:    float64(burst),
+		tokens:   float64(burst),
+		last:     time.Now(),
+	}, nil
+}
+
+func (l *tokenBucketLimiter) Wait(ctx context.Context) error {
+	l.mu.Lock()
+	now := time.Now()
+	l.tokens += float64(now.Sub(l.last)) / float64(l.interval)
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+	l.last = now
+	// reserve a token, going negative queues callers behind each other
+	l.tokens--
+	wait := time.Duration(-l.tokens * float64(l.interval))
+	l.mu.Unlock()
+
+	if wait <= 0 {
+		return nil
+	}
+	if err := sleepContext(ctx, wait); err != nil {
+		l.mu.Lock()
+		l.tokens++
+		l.mu.Unlock()
+		return err
+	}
+	return nil
+}
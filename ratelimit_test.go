@@ -0,0 +1,91 @@
+package httpretry
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimiter(t *testing.T) {
+
+	t.Run("GIVEN a limiter of 100 per second with a burst of 2", func(t *testing.T) {
+		limiter, err := NewRateLimiter(100, 2)
+		require.NoError(t, err)
+
+		t.Run("WHEN 5 attempts wait on it", func(t *testing.T) {
+			start := time.Now()
+			for i := 0; i < 5; i++ {
+				require.NoError(t, limiter.Wait(context.Background()))
+			}
+
+			t.Run("THEN the attempts after the burst are spaced out", func(t *testing.T) {
+				assert.GreaterOrEqual(t, time.Since(start), 25*time.Millisecond)
+			})
+		})
+
+		t.Run("WHEN the context is cancelled while waiting", func(t *testing.T) {
+			slow, err := NewRateLimiter(0.1, 1)
+			require.NoError(t, err)
+			require.NoError(t, slow.Wait(context.Background()))
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+			defer cancel()
+
+			t.Run("THEN the context error is returned", func(t *testing.T) {
+				assert.ErrorIs(t, slow.Wait(ctx), context.DeadlineExceeded)
+			})
+		})
+	})
+}
+
+func TestNewRateLimiter(t *testing.T) {
+
+	for _, perSecond := range []float64{0, -1, math.NaN(), math.Inf(1)} {
+		t.Run(fmt.Sprintf("GIVEN a rate of %v per second", perSecond), func(t *testing.T) {
+			_, err := NewRateLimiter(perSecond, 1)
+
+			t.Run("THEN NewRateLimiter fails", func(t *testing.T) {
+				assert.Error(t, err)
+			})
+		})
+	}
+}
+
+func TestIntegration_RateLimiter(t *testing.T) {
+
+	t.Run("GIVEN a server", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		defer ts.Close()
+
+		url, err := url.Parse(ts.URL)
+		require.NoError(t, err)
+
+		t.Run("AND two requests sharing a limiter of 50 per second", func(t *testing.T) {
+			limiter, err := NewRateLimiter(50, 1)
+			require.NoError(t, err)
+			first := NewHttpRequest(HttpRequestOptions{URL: url, RateLimiter: limiter})
+			second := NewHttpRequest(HttpRequestOptions{URL: url, RateLimiter: limiter})
+
+			t.Run("WHEN 4 calls are sent", func(t *testing.T) {
+				start := time.Now()
+				for i := 0; i < 2; i++ {
+					_, _, err := first.HttpGet(context.Background())
+					require.NoError(t, err)
+					_, _, err = second.HttpGet(context.Background())
+					require.NoError(t, err)
+				}
+
+				t.Run("THEN they are throttled together", func(t *testing.T) {
+					assert.GreaterOrEqual(t, time.Since(start), 55*time.Millisecond)
+				})
+			})
+		})
+	})
+}
@@ -0,0 +1,270 @@
+package httpretry
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// ErrNoInteraction is returned by a replaying Recorder when the cassette has
+// no unused interaction matching the request.
+var ErrNoInteraction = errors.New("no recorded interaction matches request")
+
+// RecorderMode selects whether a Recorder talks to the network.
+type RecorderMode int
+
+const (
+	// RecorderReplayOrRecord replays the cassette when the file exists and
+	// records a new one otherwise.
+	RecorderReplayOrRecord RecorderMode = iota
+	// RecorderRecord always sends requests and overwrites the cassette.
+	RecorderRecord
+	// RecorderReplay never sends requests, unmatched requests fail with
+	// ErrNoInteraction.
+	RecorderReplay
+)
+
+// RecordedRequest is the part of a request used to match interactions.
+// Request headers are not recorded and sensitive fields of the URL and body
+// are redacted, so credentials don't reach the cassette.
+type RecordedRequest struct {
+	Method string `json:"method"`
+	URL    string `json:"url"`
+	Body   string `json:"body,omitempty"`
+}
+
+// RecordedResponse is replayed in place of the server response.
+type RecordedResponse struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body,omitempty"`
+}
+
+// Interaction is one request/response pair of a cassette.
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+type RecorderOptions struct {
+	// Path of the JSON cassette file, parent directories are created on
+	// first write
+	Path string
+
+	// Mode defaults to RecorderReplayOrRecord
+	Mode RecorderMode
+
+	// Transport is used while recording
+	// defaults to http.DefaultTransport
+	Transport http.RoundTripper
+
+	// Match reports whether a recorded request answers req.  Recorded
+	// interactions are replayed in order and each is used once.  The URL of
+	// req and body are redacted like the recorded request.
+	// defaults to comparing method, URL and body
+	Match func(req *http.Request, body []byte, recorded RecordedRequest) bool
+
+	// Redactor masks sensitive query parameters and body fields of the
+	// recorded requests and responses
+	// defaults to DefaultRedactor
+	Redactor *Redactor
+
+	// DisableRedaction records URLs and bodies as they are sent and received
+	DisableRedaction bool
+}
+
+// Recorder is a round tripper persisting request/response pairs to a cassette
+// on first run and replaying them deterministically afterwards, so tests
+// against real API shapes run fast and offline:
+//
+//	recorder, err := httpretry.NewRecorder(httpretry.RecorderOptions{Path: "testdata/things.json"})
+//	api := httpretry.NewHttpRequest(httpretry.HttpRequestOptions{URL: url, Transport: recorder})
+//
+// Sensitive response headers, see DefaultSensitiveHeaders, are not recorded
+// and sensitive fields of URLs and bodies are redacted, see
+// RecorderOptions.Redactor.
+type Recorder struct {
+	path      string
+	replay    bool
+	transport http.RoundTripper
+	match     func(req *http.Request, body []byte, recorded RecordedRequest) bool
+	redactor  *Redactor
+
+	mu           sync.Mutex
+	interactions []Interaction
+	used         []bool
+}
+
+// NewRecorder loads the cassette at options.Path when replaying.
+func NewRecorder(options RecorderOptions) (*Recorder, error) {
+	if options.Transport == nil {
+		options.Transport = http.DefaultTransport
+	}
+	if options.Match == nil {
+		options.Match = matchRecordedRequest
+	}
+	if options.Redactor == nil {
+		options.Redactor = DefaultRedactor
+	}
+	if options.DisableRedaction {
+		options.Redactor = nil
+	}
+	recorder := &Recorder{
+		path:      options.Path,
+		transport: options.Transport,
+		match:     options.Match,
+		redactor:  options.Redactor,
+	}
+
+	switch options.Mode {
+	case RecorderRecord:
+		return recorder, nil
+	case RecorderReplayOrRecord:
+		if _, err := os.Stat(options.Path); errors.Is(err, os.ErrNotExist) {
+			return recorder, nil
+		}
+	}
+
+	data, err := os.ReadFile(options.Path)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &recorder.interactions); err != nil {
+		return nil, fmt.Errorf("cassette %s: %w", options.Path, err)
+	}
+	recorder.used = make([]bool, len(recorder.interactions))
+	recorder.replay = true
+	return recorder, nil
+}
+
+// Replaying reports whether responses come from the cassette.
+func (r *Recorder) Replaying() bool {
+	return r.replay
+}
+
+// Interactions returns a copy of the recorded or loaded interactions.
+func (r *Recorder) Interactions() []Interaction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Interaction(nil), r.interactions...)
+}
+
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil && req.Body != http.NoBody {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if r.replay {
+		return r.replayInteraction(req, r.redactor.Redact(body, ""))
+	}
+
+	attempt := req
+	if body != nil {
+		attempt = req.Clone(req.Context())
+		attempt.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	resp, err := r.transport.RoundTrip(attempt)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	header := resp.Header.Clone()
+	for _, name := range DefaultSensitiveHeaders {
+		header.Del(name)
+	}
+	if err := r.record(Interaction{
+		Request: RecordedRequest{
+			Method: req.Method,
+			URL:    r.redactURL(req.URL).String(),
+			Body:   string(r.redactor.Redact(body, "")),
+		},
+		Response: RecordedResponse{
+			StatusCode: resp.StatusCode,
+			Header:     header,
+			Body:       string(r.redactor.Redact(respBody, "")),
+		},
+	}); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (r *Recorder) replayInteraction(req *http.Request, body []byte) (*http.Response, error) {
+	redactedReq := *req
+	redactedReq.URL = r.redactURL(req.URL)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, interaction := range r.interactions {
+		if r.used[i] || !r.match(&redactedReq, body, interaction.Request) {
+			continue
+		}
+		r.used[i] = true
+		recorded := interaction.Response
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", recorded.StatusCode, http.StatusText(recorded.StatusCode)),
+			StatusCode:    recorded.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        recorded.Header.Clone(),
+			Body:          io.NopCloser(bytes.NewReader([]byte(recorded.Body))),
+			ContentLength: int64(len(recorded.Body)),
+			Request:       req,
+		}, nil
+	}
+	return nil, fmt.Errorf("%w: %s %s", ErrNoInteraction, req.Method, req.URL)
+}
+
+// redactURL returns u with sensitive query parameters and the password of its
+// user info masked.
+func (r *Recorder) redactURL(u *url.URL) *url.URL {
+	if r.redactor == nil {
+		return u
+	}
+	masked := *u
+	if password, ok := u.User.Password(); ok && password != "" {
+		masked.User = url.UserPassword(u.User.Username(), redacted)
+	}
+	masked.RawQuery = string(r.redactor.Redact([]byte(u.RawQuery), ""))
+	return &masked
+}
+
+// record appends the interaction and rewrites the cassette so it survives
+// tests that fail or never close the recorder.
+func (r *Recorder) record(interaction Interaction) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.interactions = append(r.interactions, interaction)
+
+	data, err := json.MarshalIndent(r.interactions, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(r.path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(r.path, data, 0o644)
+}
+
+func matchRecordedRequest(req *http.Request, body []byte, recorded RecordedRequest) bool {
+	return req.Method == recorded.Method && req.URL.String() == recorded.URL && string(body) == recorded.Body
+}
@@ -0,0 +1,123 @@
+package httpretry
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntegration_Recorder(t *testing.T) {
+
+	t.Run("GIVEN a server AND a cassette path that does not exist", func(t *testing.T) {
+		calls := 0
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			w.Header().Set("Set-Cookie", "session=secret")
+			w.Header().Set("X-Thing", "1")
+			w.Write([]byte(`{"data":{"id":"1"}}`))
+		}))
+		defer ts.Close()
+
+		url, err := url.Parse(ts.URL)
+		require.NoError(t, err)
+		path := filepath.Join(t.TempDir(), "cassettes", "things.json")
+
+		t.Run("WHEN a request is sent through a recorder", func(t *testing.T) {
+			recorder, err := NewRecorder(RecorderOptions{Path: path})
+			require.NoError(t, err)
+			assert.False(t, recorder.Replaying())
+
+			api := NewHttpRequest(HttpRequestOptions{URL: url, Transport: recorder})
+			body, status, err := api.HttpGet(context.Background())
+			require.NoError(t, err)
+
+			t.Run("THEN the real response is returned AND recorded without sensitive headers", func(t *testing.T) {
+				assert.Equal(t, http.StatusOK, status)
+				assert.Equal(t, `{"data":{"id":"1"}}`, string(body))
+				interactions := recorder.Interactions()
+				require.Len(t, interactions, 1)
+				assert.Equal(t, "1", interactions[0].Response.Header.Get("X-Thing"))
+				assert.Empty(t, interactions[0].Response.Header.Get("Set-Cookie"))
+			})
+		})
+
+		t.Run("WHEN the request is sent again through a new recorder", func(t *testing.T) {
+			recorder, err := NewRecorder(RecorderOptions{Path: path})
+			require.NoError(t, err)
+			assert.True(t, recorder.Replaying())
+
+			api := NewHttpRequest(HttpRequestOptions{URL: url, Transport: recorder, RetriesMax: 1})
+			body, status, err := api.HttpGet(context.Background())
+			require.NoError(t, err)
+
+			t.Run("THEN the response is replayed without reaching the server", func(t *testing.T) {
+				assert.Equal(t, 1, calls)
+				assert.Equal(t, http.StatusOK, status)
+				assert.Equal(t, `{"data":{"id":"1"}}`, string(body))
+			})
+
+			t.Run("AND a further request fails once the interaction is used", func(t *testing.T) {
+				_, _, err := api.HttpGet(context.Background())
+				assert.True(t, errors.Is(err, ErrNoInteraction))
+			})
+		})
+	})
+
+	t.Run("GIVEN a token endpoint AND a cassette path that does not exist", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{"access_token":"s3cr3t-t0k3n","expires_in":3600}`))
+		}))
+		defer ts.Close()
+
+		url, err := url.Parse(ts.URL + "/token?api_key=k3y&scope=read")
+		require.NoError(t, err)
+		path := filepath.Join(t.TempDir(), "token.json")
+
+		t.Run("WHEN credentials are exchanged through a recorder", func(t *testing.T) {
+			recorder, err := NewRecorder(RecorderOptions{Path: path})
+			require.NoError(t, err)
+
+			api := NewHttpRequest(HttpRequestOptions{URL: url, Transport: recorder})
+			_, _, err = api.HttpPost(context.Background(), []byte(`{"client_id":"app","client_secret":"hunter2"}`))
+			require.NoError(t, err)
+
+			t.Run("THEN the cassette holds no credentials", func(t *testing.T) {
+				cassette, err := os.ReadFile(path)
+				require.NoError(t, err)
+				assert.NotContains(t, string(cassette), "k3y")
+				assert.NotContains(t, string(cassette), "hunter2")
+				assert.NotContains(t, string(cassette), "s3cr3t-t0k3n")
+				assert.Contains(t, string(cassette), "scope=read")
+			})
+		})
+
+		t.Run("WHEN the exchange is sent again through a new recorder", func(t *testing.T) {
+			recorder, err := NewRecorder(RecorderOptions{Path: path, Mode: RecorderReplay})
+			require.NoError(t, err)
+
+			api := NewHttpRequest(HttpRequestOptions{URL: url, Transport: recorder, RetriesMax: 1})
+			_, status, err := api.HttpPost(context.Background(), []byte(`{"client_id":"app","client_secret":"hunter2"}`))
+
+			t.Run("THEN the redacted interaction still matches", func(t *testing.T) {
+				require.NoError(t, err)
+				assert.Equal(t, http.StatusOK, status)
+			})
+		})
+	})
+
+	t.Run("GIVEN replay mode AND a missing cassette", func(t *testing.T) {
+		_, err := NewRecorder(RecorderOptions{Path: filepath.Join(t.TempDir(), "missing.json"), Mode: RecorderReplay})
+
+		t.Run("THEN NewRecorder fails", func(t *testing.T) {
+			assert.Error(t, err)
+		})
+	})
+}
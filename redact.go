@@ -0,0 +1,85 @@
+package httpretry
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+)
+
+const redacted = "[REDACTED]"
+
+// DefaultSensitiveHeaders are masked in debug dumps by DefaultRedactor.
+var DefaultSensitiveHeaders = []string{
+	"Authorization",
+	"Proxy-Authorization",
+	"Cookie",
+	"Set-Cookie",
+	"X-Api-Key",
+}
+
+// DefaultSensitiveFields are JSON and form fields masked in debug dumps by
+// DefaultRedactor, matched case-insensitively.
+var DefaultSensitiveFields = []string{
+	"password",
+	"client_secret",
+	"access_token",
+	"refresh_token",
+	"id_token",
+	"api_key",
+	"secret",
+}
+
+// DefaultRedactor is used for debug dumps unless a request sets its own
+// Redactor or disables redaction.
+var DefaultRedactor = NewRedactor(DefaultSensitiveHeaders, DefaultSensitiveFields)
+
+// Redactor masks credentials in request and response dumps before they are
+// logged.  Values of sensitive headers and of sensitive JSON or form fields
+// in bodies are replaced by [REDACTED].
+type Redactor struct {
+	headers *regexp.Regexp
+	json    *regexp.Regexp
+	form    *regexp.Regexp
+}
+
+// NewRedactor masks the given headers and body fields, to extend the defaults
+// append to DefaultSensitiveHeaders and DefaultSensitiveFields.
+func NewRedactor(headers []string, fields []string) *Redactor {
+	r := &Redactor{}
+	if len(headers) > 0 {
+		r.headers = regexp.MustCompile(`(?im)^(` + quoteAll(headers) + `):[^\r\n]*`)
+	}
+	if len(fields) > 0 {
+		names := quoteAll(fields)
+		r.json = regexp.MustCompile(`(?i)("(?:` + names + `)"\s*:\s*)("(?:[^"\\]|\\.)*"|[^,}\]\s]+)`)
+		r.form = regexp.MustCompile(`(?i)((?:^|[&?\s])(?:` + names + `)=)[^&\s]*`)
+	}
+	return r
+}
+
+// Redact masks a dump, secret is masked wherever it appears, for example the
+// bearer token of the request.
+func (r *Redactor) Redact(dump []byte, secret string) []byte {
+	if r == nil {
+		return dump
+	}
+	if secret != "" {
+		dump = bytes.ReplaceAll(dump, []byte(secret), []byte(redacted))
+	}
+	if r.headers != nil {
+		dump = r.headers.ReplaceAll(dump, []byte("${1}: "+redacted))
+	}
+	if r.json != nil {
+		dump = r.json.ReplaceAll(dump, []byte(`${1}"`+redacted+`"`))
+		dump = r.form.ReplaceAll(dump, []byte("${1}"+redacted))
+	}
+	return dump
+}
+
+func quoteAll(values []string) string {
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = regexp.QuoteMeta(v)
+	}
+	return strings.Join(quoted, "|")
+}
@@ -0,0 +1,90 @@
+package httpretry
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedactor(t *testing.T) {
+
+	t.Run("GIVEN a dump with credentials in headers and bodies", func(t *testing.T) {
+		dump := "POST /token HTTP/1.1\r\n" +
+			"Authorization: Bearer abc123\r\n" +
+			"Content-Type: application/json\r\n" +
+			"\r\n" +
+			`{"user":"bob","Password" : "hunter\"2","nested":{"client_secret":"s3cr3t"},"pin":1234}` +
+			"\ngrant_type=password&client_secret=xyz&scope=all"
+
+		t.Run("WHEN redacted with the default redactor", func(t *testing.T) {
+			result := string(DefaultRedactor.Redact([]byte(dump), ""))
+
+			t.Run("THEN sensitive values are masked", func(t *testing.T) {
+				assert.Contains(t, result, "Authorization: [REDACTED]\r\n")
+				assert.Contains(t, result, `"Password" : "[REDACTED]"`)
+				assert.Contains(t, result, `"client_secret":"[REDACTED]"`)
+				assert.Contains(t, result, "client_secret=[REDACTED]&")
+				assert.NotContains(t, result, "abc123")
+				assert.NotContains(t, result, "hunter")
+				assert.NotContains(t, result, "xyz")
+			})
+
+			t.Run("THEN other values are kept", func(t *testing.T) {
+				assert.Contains(t, result, "Content-Type: application/json")
+				assert.Contains(t, result, `"user":"bob"`)
+				assert.Contains(t, result, "grant_type=password")
+				assert.Contains(t, result, `"pin":1234`)
+			})
+		})
+
+		t.Run("WHEN redacted with a secret", func(t *testing.T) {
+			result := string(NewRedactor(nil, nil).Redact([]byte(dump), "abc123"))
+
+			t.Run("THEN the secret is masked wherever it appears", func(t *testing.T) {
+				assert.Contains(t, result, "Authorization: Bearer [REDACTED]")
+			})
+		})
+	})
+}
+
+func TestIntegration_RedactedDebugDumps(t *testing.T) {
+
+	t.Run("GIVEN a server that sets a session cookie", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Set-Cookie", "session=topsecret")
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer ts.Close()
+
+		url, err := url.Parse(ts.URL)
+		require.NoError(t, err)
+
+		t.Run("AND http request with a token", func(t *testing.T) {
+			logger := &testLogger{}
+			api := NewHttpRequest(HttpRequestOptions{
+				URL:    url,
+				Token:  "my-bearer-token",
+				Logger: logger,
+			})
+
+			t.Run("WHEN HttpPost is sent with a password", func(t *testing.T) {
+				_, _, err := api.HttpPost(context.Background(), []byte(`{"password":"hunter2"}`))
+				require.NoError(t, err)
+
+				t.Run("THEN no secret is logged", func(t *testing.T) {
+					logs := strings.Join(logger.lines, "\n")
+					assert.Contains(t, logs, "[REDACTED]")
+					assert.NotContains(t, logs, "my-bearer-token")
+					assert.NotContains(t, logs, "hunter2")
+					assert.NotContains(t, logs, "topsecret")
+				})
+			})
+		})
+	})
+}
@@ -0,0 +1,63 @@
+package httpretry
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// ErrRedirectDenied is returned for a redirect to another host when
+// RedirectPolicy.DenyCrossHost is set.
+var ErrRedirectDenied = errors.New("cross-host redirect denied")
+
+// RedirectPolicy controls how a request follows redirects instead of the
+// default behavior of the shared client, which follows up to 10 redirects
+// and strips Authorization when the domain changes.
+type RedirectPolicy struct {
+	// Disable returns the redirect response itself
+	Disable bool
+
+	// MaxRedirects stops after that many redirects with an error
+	// defaults to 10
+	MaxRedirects int
+
+	// DenyCrossHost stops with ErrRedirectDenied at a redirect to another
+	// host
+	DenyCrossHost bool
+
+	// StripAuthorization removes the Authorization header on every redirect,
+	// also to the same host
+	StripAuthorization bool
+
+	// ForwardAuthorization keeps the Authorization header on redirects to
+	// other domains, only use it when every redirect target is trusted
+	ForwardAuthorization bool
+}
+
+func (p *RedirectPolicy) checkRedirect(req *http.Request, via []*http.Request) error {
+	if p.Disable {
+		return http.ErrUseLastResponse
+	}
+	max := p.MaxRedirects
+	if max == 0 {
+		max = 10
+	}
+	if len(via) >= max {
+		return fmt.Errorf("stopped after %d redirects", max)
+	}
+
+	initial := via[0]
+	crossHost := req.URL.Host != initial.URL.Host
+	if crossHost && p.DenyCrossHost {
+		return fmt.Errorf("%w from %s to %s", ErrRedirectDenied, initial.URL.Host, req.URL.Host)
+	}
+	switch {
+	case p.StripAuthorization:
+		req.Header.Del("Authorization")
+	case p.ForwardAuthorization && crossHost:
+		if auth := initial.Header.Get("Authorization"); auth != "" {
+			req.Header.Set("Authorization", auth)
+		}
+	}
+	return nil
+}
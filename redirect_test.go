@@ -0,0 +1,98 @@
+package httpretry
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntegration_RedirectPolicy(t *testing.T) {
+
+	t.Run("GIVEN a server redirecting to another host", func(t *testing.T) {
+		var authorization string
+		target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			authorization = r.Header.Get("Authorization")
+			w.Write([]byte("target"))
+		}))
+		defer target.Close()
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/loop" {
+				http.Redirect(w, r, "/loop", http.StatusFound)
+				return
+			}
+			http.Redirect(w, r, target.URL, http.StatusFound)
+		}))
+		defer ts.Close()
+
+		url, err := url.Parse(ts.URL)
+		require.NoError(t, err)
+
+		send := func(policy *RedirectPolicy) ([]byte, int, error) {
+			authorization = ""
+			api := NewHttpRequest(HttpRequestOptions{URL: url, Token: "secret", RetriesMax: 1, Redirect: policy})
+			return api.HttpGet(context.Background())
+		}
+
+		t.Run("WHEN HttpGet is sent without a policy", func(t *testing.T) {
+			body, _, err := send(nil)
+			require.NoError(t, err)
+
+			t.Run("THEN the redirect is followed", func(t *testing.T) {
+				assert.Equal(t, "target", string(body))
+			})
+		})
+
+		t.Run("WHEN HttpGet is sent with StripAuthorization", func(t *testing.T) {
+			_, _, err := send(&RedirectPolicy{StripAuthorization: true})
+			require.NoError(t, err)
+
+			t.Run("THEN Authorization is not sent after the redirect", func(t *testing.T) {
+				assert.Empty(t, authorization)
+			})
+		})
+
+		t.Run("WHEN HttpGet is sent with ForwardAuthorization", func(t *testing.T) {
+			_, _, err := send(&RedirectPolicy{ForwardAuthorization: true})
+			require.NoError(t, err)
+
+			t.Run("THEN Authorization reaches the other host", func(t *testing.T) {
+				assert.Equal(t, "Bearer secret", authorization)
+			})
+		})
+
+		t.Run("WHEN HttpGet is sent with redirects disabled", func(t *testing.T) {
+			_, status, err := send(&RedirectPolicy{Disable: true})
+			require.NoError(t, err)
+
+			t.Run("THEN the redirect response is returned", func(t *testing.T) {
+				assert.Equal(t, http.StatusFound, status)
+			})
+		})
+
+		t.Run("WHEN HttpGet is sent with DenyCrossHost", func(t *testing.T) {
+			_, _, err := send(&RedirectPolicy{DenyCrossHost: true})
+
+			t.Run("THEN it fails with ErrRedirectDenied", func(t *testing.T) {
+				assert.True(t, errors.Is(err, ErrRedirectDenied))
+			})
+		})
+
+		t.Run("WHEN a redirect loop is followed with MaxRedirects", func(t *testing.T) {
+			loop := *url
+			loop.Path = "/loop"
+			api := NewHttpRequest(HttpRequestOptions{URL: &loop, RetriesMax: 1, Redirect: &RedirectPolicy{MaxRedirects: 3}})
+			_, _, err := api.HttpGet(context.Background())
+
+			t.Run("THEN it stops after MaxRedirects", func(t *testing.T) {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), "stopped after 3 redirects")
+			})
+		})
+	})
+}
@@ -0,0 +1,68 @@
+package httpretry
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+)
+
+// ErrUnknownClient is returned by requests selecting a client name that was
+// never registered.
+var ErrUnknownClient = errors.New("unknown client")
+
+// DefaultClientRegistry is used by requests with a ClientName unless they set
+// their own ClientRegistry.
+var DefaultClientRegistry = NewClientRegistry()
+
+// ClientRegistry holds named client configurations, for example one per
+// egress proxy or TLS identity.  Each configuration gets a single client
+// built on first use, so requests selecting the same name share its
+// connection pool.  It is safe for concurrent use.
+type ClientRegistry struct {
+	mu      sync.Mutex
+	options map[string]ClientOptions
+	clients map[string]*http.Client
+}
+
+func NewClientRegistry() *ClientRegistry {
+	return &ClientRegistry{
+		options: make(map[string]ClientOptions),
+		clients: make(map[string]*http.Client),
+	}
+}
+
+// Register sets the configuration of name.  Registering a name again
+// replaces its client for requests sent afterwards.
+func (c *ClientRegistry) Register(name string, options ClientOptions) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.options[name] = options
+	delete(c.clients, name)
+}
+
+// Client returns the client of name, building it on first use.
+func (c *ClientRegistry) Client(name string) (*http.Client, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if client, ok := c.clients[name]; ok {
+		return client, nil
+	}
+	options, ok := c.options[name]
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnknownClient, name)
+	}
+	client := NewHttpClient(options)
+	c.clients[name] = client
+	return client, nil
+}
+
+// closeIdleConnections closes the idle connections of the clients built so
+// far.
+func (c *ClientRegistry) closeIdleConnections() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, client := range c.clients {
+		client.CloseIdleConnections()
+	}
+}
@@ -0,0 +1,86 @@
+package httpretry
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientRegistry(t *testing.T) {
+
+	t.Run("GIVEN a registry with a named configuration", func(t *testing.T) {
+		registry := NewClientRegistry()
+		registry.Register("internal", ClientOptions{Timeout: time.Second, MaxIdleConnsPerHost: 5})
+
+		t.Run("THEN the client is built once AND reused", func(t *testing.T) {
+			first, err := registry.Client("internal")
+			require.NoError(t, err)
+			second, err := registry.Client("internal")
+			require.NoError(t, err)
+			assert.Same(t, first, second)
+			assert.Equal(t, time.Second, first.Timeout)
+		})
+
+		t.Run("THEN registering the name again replaces the client", func(t *testing.T) {
+			first, err := registry.Client("internal")
+			require.NoError(t, err)
+			registry.Register("internal", ClientOptions{Timeout: 2 * time.Second})
+			second, err := registry.Client("internal")
+			require.NoError(t, err)
+			assert.NotSame(t, first, second)
+			assert.Equal(t, 2*time.Second, second.Timeout)
+		})
+
+		t.Run("THEN an unknown name fails", func(t *testing.T) {
+			_, err := registry.Client("public")
+			assert.ErrorIs(t, err, ErrUnknownClient)
+		})
+	})
+}
+
+func TestIntegration_ClientName(t *testing.T) {
+
+	t.Run("GIVEN a server AND a registry with a client using a proxy", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("direct"))
+		}))
+		defer ts.Close()
+		proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("proxied"))
+		}))
+		defer proxy.Close()
+
+		u, err := url.Parse(ts.URL)
+		require.NoError(t, err)
+		proxyURL, err := url.Parse(proxy.URL)
+		require.NoError(t, err)
+
+		registry := NewClientRegistry()
+		registry.Register("eu", ClientOptions{Proxy: http.ProxyURL(proxyURL)})
+
+		t.Run("WHEN HttpGet is sent with the client name", func(t *testing.T) {
+			api := NewHttpRequest(HttpRequestOptions{URL: u, ClientName: "eu", ClientRegistry: registry})
+			body, _, err := api.HttpGet(context.Background())
+			require.NoError(t, err)
+
+			t.Run("THEN the named client sends it", func(t *testing.T) {
+				assert.Equal(t, "proxied", string(body))
+			})
+		})
+
+		t.Run("WHEN HttpGet is sent with an unknown client name", func(t *testing.T) {
+			api := NewHttpRequest(HttpRequestOptions{URL: u, ClientName: "us", ClientRegistry: registry})
+			_, _, err := api.HttpGet(context.Background())
+
+			t.Run("THEN it fails with ErrUnknownClient", func(t *testing.T) {
+				assert.ErrorIs(t, err, ErrUnknownClient)
+			})
+		})
+	})
+}
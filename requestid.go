@@ -0,0 +1,39 @@
+package httpretry
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+type contextKey string
+
+// RequestIDKey is the context key holding the request ID used in log lines.
+// Prefer WithRequestID and RequestIDFromContext over using it directly.
+var RequestIDKey = contextKey("RequestId")
+
+// RequestIDHeader is the header carrying the request ID when
+// HttpRequestOptions.InjectRequestID is set.
+const RequestIDHeader = "X-Request-ID"
+
+// WithRequestID returns a context carrying id.  Calls made with this context
+// use id for every attempt instead of generating one per attempt, so logs of
+// the caller and of this package can be correlated.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, RequestIDKey, id)
+}
+
+// RequestIDFromContext returns the request ID in ctx, or an empty string.
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(RequestIDKey).(string)
+	return id
+}
+
+// attemptContext sets the request ID for an attempt, keeping a caller
+// supplied one.
+func attemptContext(ctx context.Context, callerID string) context.Context {
+	if callerID != "" {
+		return WithRequestID(ctx, callerID)
+	}
+	return WithRequestID(ctx, uuid.New().String())
+}
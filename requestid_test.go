@@ -0,0 +1,67 @@
+package httpretry
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntegration_RequestID(t *testing.T) {
+
+	t.Run("GIVEN a server that returns 503 once AND records X-Request-ID", func(t *testing.T) {
+		attempts := 1
+		var ids []string
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ids = append(ids, r.Header.Get(RequestIDHeader))
+			if attempts > 0 {
+				attempts--
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer ts.Close()
+
+		url, err := url.Parse(ts.URL)
+		require.NoError(t, err)
+
+		api := NewHttpRequest(HttpRequestOptions{
+			URL:             url,
+			RetriesWait:     time.Millisecond,
+			InjectRequestID: true,
+			IsRetryCondition: func(resp *http.Response, retryCount int) bool {
+				return resp.StatusCode == http.StatusServiceUnavailable
+			},
+		})
+
+		t.Run("WHEN HttpGet is sent with a caller supplied request ID", func(t *testing.T) {
+			ctx := WithRequestID(context.Background(), "caller-id")
+			_, _, err := api.HttpGet(ctx)
+			require.NoError(t, err)
+
+			t.Run("THEN every attempt sends the caller ID", func(t *testing.T) {
+				assert.Equal(t, []string{"caller-id", "caller-id"}, ids)
+				assert.Equal(t, "caller-id", RequestIDFromContext(ctx))
+			})
+		})
+
+		t.Run("WHEN HttpGet is sent without a request ID", func(t *testing.T) {
+			ids = nil
+			attempts = 1
+			_, _, err := api.HttpGet(context.Background())
+			require.NoError(t, err)
+
+			t.Run("THEN every attempt sends its own generated ID", func(t *testing.T) {
+				require.Len(t, ids, 2)
+				assert.NotEmpty(t, ids[0])
+				assert.NotEqual(t, ids[0], ids[1])
+			})
+		})
+	})
+}
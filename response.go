@@ -0,0 +1,110 @@
+package httpretry
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// Response is the outcome of a call including what the ([]byte, int, error)
+// methods discard, like headers for pagination Link or rate-limit headers.
+type Response struct {
+	Body       []byte
+	StatusCode int
+	Header     http.Header
+
+	// Attempts number of requests sent, 1 when the first attempt was final
+	Attempts int
+
+	// Duration total time of the call including waits between retries
+	Duration time.Duration
+
+	// History describes every attempt in order
+	History []AttemptSummary
+
+	// IdempotencyKey sent with every attempt, see AddIdempotencyKey
+	IdempotencyKey string
+
+	// FromCache is true when the upstream answered 304 and Body is the cached
+	// body, see HttpRequestOptions.Cache
+	FromCache bool
+
+	// Stale is true when every attempt failed and Body is the last cached
+	// response, see HttpRequestOptions.StaleOnError
+	Stale bool
+
+	// raw is the final response, its body is still open in stream mode
+	raw *http.Response
+
+	// req is the final request, nil when none could be built
+	req *http.Request
+
+	// exhausted is true when the attempts gave up, as opposed to a final
+	// response or a cancelled context
+	exhausted bool
+
+	// mayHaveProcessed is true when SafeRetries stopped the call because the
+	// upstream may have processed the request
+	mayHaveProcessed bool
+
+	// cached is the response revalidated by the call, if any
+	cached *CachedResponse
+}
+
+func newResponse(resp *http.Response, body []byte, attempts int, duration time.Duration) *Response {
+	response := &Response{
+		Body:       body,
+		StatusCode: responseStatusCode(resp),
+		Attempts:   attempts,
+		Duration:   duration,
+		raw:        resp,
+	}
+	if resp != nil {
+		response.Header = resp.Header
+	}
+	return response
+}
+
+// DoFull is Do returning a Response.  The Response is never nil, on error it
+// holds what is known about the last attempt.
+func (r httpRequest) DoFull(ctx context.Context, method string, object []byte, opts ...CallOption) (*Response, error) {
+	r = r.forCall(method, opts)
+	client, err := r.httpClient()
+	if err != nil {
+		return &Response{}, err
+	}
+
+	return r.retryLoop(ctx, client, r.newRequestFactory(method, r.URL.String(), object), false)
+}
+
+func (r httpRequest) HttpGetFull(ctx context.Context, opts ...CallOption) (*Response, error) {
+	return r.DoFull(ctx, http.MethodGet, nil, opts...)
+}
+
+func (r httpRequest) HttpPostFull(ctx context.Context, object []byte, opts ...CallOption) (*Response, error) {
+	return r.DoFull(ctx, http.MethodPost, object, opts...)
+}
+
+func (r httpRequest) HttpPatchFull(ctx context.Context, object []byte, opts ...CallOption) (*Response, error) {
+	return r.DoFull(ctx, http.MethodPatch, object, opts...)
+}
+
+func (r httpRequest) HttpPutFull(ctx context.Context, object []byte, opts ...CallOption) (*Response, error) {
+	return r.DoFull(ctx, http.MethodPut, object, opts...)
+}
+
+func (r httpRequest) HttpDeleteFull(ctx context.Context, opts ...CallOption) (*Response, error) {
+	return r.DoFull(ctx, http.MethodDelete, nil, opts...)
+}
+
+// HttpHead returns the headers of the resource, for example for existence
+// probes and health checks.  Body is empty.
+func (r httpRequest) HttpHead(ctx context.Context, opts ...CallOption) (*Response, error) {
+	return r.DoFull(ctx, http.MethodHead, nil, opts...)
+}
+
+// HttpOptions returns the communication options of the resource, for example
+// the Allow header or the CORS headers of a preflight request.
+func (r httpRequest) HttpOptions(ctx context.Context, opts ...CallOption) (*Response, error) {
+	return r.DoFull(ctx, http.MethodOptions, nil, opts...)
+}
@@ -0,0 +1,128 @@
+package httpretry
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntegration_DoFull(t *testing.T) {
+
+	t.Run("GIVEN a server that returns 429 once AND then a Link header", func(t *testing.T) {
+		attempts := 1
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if attempts > 0 {
+				attempts--
+				w.WriteHeader(http.StatusTooManyRequests)
+				return
+			}
+			w.Header().Set("Link", `<https://api.example.com/things?page=2>; rel="next"`)
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte("page 1"))
+		}))
+		defer ts.Close()
+
+		url, err := url.Parse(ts.URL)
+		require.NoError(t, err)
+
+		api := NewHttpRequest(HttpRequestOptions{
+			URL:         url,
+			RetriesWait: time.Millisecond,
+			IsRetryCondition: func(resp *http.Response, retryCount int) bool {
+				return resp.StatusCode == http.StatusTooManyRequests
+			},
+		})
+
+		t.Run("WHEN HttpGetFull is sent", func(t *testing.T) {
+			resp, err := api.HttpGetFull(context.Background())
+			require.NoError(t, err)
+
+			t.Run("THEN the response includes headers and attempts", func(t *testing.T) {
+				assert.Equal(t, http.StatusOK, resp.StatusCode)
+				assert.Equal(t, "page 1", string(resp.Body))
+				assert.Contains(t, resp.Header.Get("Link"), `rel="next"`)
+				assert.Equal(t, 2, resp.Attempts)
+				assert.Greater(t, resp.Duration, time.Duration(0))
+			})
+		})
+	})
+
+	t.Run("GIVEN a closed server", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		url, err := url.Parse(ts.URL)
+		require.NoError(t, err)
+		ts.Close()
+
+		api := NewHttpRequest(HttpRequestOptions{
+			URL:         url,
+			RetriesMax:  3,
+			RetriesWait: time.Millisecond,
+		})
+
+		t.Run("WHEN DoFull is sent", func(t *testing.T) {
+			resp, err := api.DoFull(context.Background(), http.MethodGet, nil)
+
+			t.Run("THEN the response reports the attempts alongside the error", func(t *testing.T) {
+				assert.Error(t, err)
+				require.NotNil(t, resp)
+				assert.Equal(t, 0, resp.StatusCode)
+				assert.Equal(t, 3, resp.Attempts)
+			})
+		})
+	})
+}
+
+func TestIntegration_HttpHeadAndOptions(t *testing.T) {
+
+	t.Run("GIVEN a server answering HEAD and OPTIONS", func(t *testing.T) {
+		var methods []string
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			methods = append(methods, r.Method)
+			switch r.Method {
+			case http.MethodHead:
+				w.Header().Set("ETag", `"v1"`)
+			case http.MethodOptions:
+				w.Header().Set("Allow", "GET, HEAD, OPTIONS")
+				w.Header().Set("Access-Control-Allow-Origin", "*")
+				w.WriteHeader(http.StatusNoContent)
+			}
+		}))
+		defer ts.Close()
+
+		url, err := url.Parse(ts.URL)
+		require.NoError(t, err)
+		api := NewHttpRequest(HttpRequestOptions{URL: url})
+
+		t.Run("WHEN HttpHead is sent", func(t *testing.T) {
+			resp, err := api.HttpHead(context.Background())
+			require.NoError(t, err)
+
+			t.Run("THEN the headers are returned", func(t *testing.T) {
+				assert.Equal(t, http.StatusOK, resp.StatusCode)
+				assert.Equal(t, `"v1"`, resp.Header.Get("ETag"))
+				assert.Empty(t, resp.Body)
+			})
+		})
+
+		t.Run("WHEN HttpOptions is sent", func(t *testing.T) {
+			resp, err := api.HttpOptions(context.Background())
+			require.NoError(t, err)
+
+			t.Run("THEN the allowed methods are returned", func(t *testing.T) {
+				assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+				assert.Equal(t, "GET, HEAD, OPTIONS", resp.Header.Get("Allow"))
+				assert.Equal(t, "*", resp.Header.Get("Access-Control-Allow-Origin"))
+			})
+		})
+
+		t.Run("THEN the server received both methods", func(t *testing.T) {
+			assert.Equal(t, []string{http.MethodHead, http.MethodOptions}, methods)
+		})
+	})
+}
@@ -0,0 +1,28 @@
+package httpretry
+
+import (
+	"math"
+)
+
+const (
+	// NoRetries as RetriesMax sends a single attempt, RetriesMax 0 means
+	// the default.
+	NoRetries = -1
+
+	// UnlimitedRetries as RetriesMax retries until the context is done,
+	// MaxElapsedTime is reached or the RetryBudget runs dry.  Without any
+	// of them a call to a dead upstream never returns.
+	UnlimitedRetries = -2
+)
+
+// maxAttempts converts RetriesMax to a number of attempts, other negative
+// values send a single attempt like NoRetries.
+func maxAttempts(retriesMax int) int {
+	if retriesMax == UnlimitedRetries {
+		return math.MaxInt
+	}
+	if retriesMax < 1 {
+		return 1
+	}
+	return retriesMax
+}
@@ -0,0 +1,103 @@
+package httpretry
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/mandric/httpretry/httpretrytest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntegration_RetrySentinels(t *testing.T) {
+
+	t.Run("GIVEN a server failing 20 times", func(t *testing.T) {
+		ts := httpretrytest.NewFlakyServer(20, http.StatusServiceUnavailable)
+		defer ts.Close()
+
+		url, err := url.Parse(ts.URL)
+		require.NoError(t, err)
+		options := HttpRequestOptions{
+			URL:              url,
+			IsRetryCondition: RetryOnStatus(http.StatusServiceUnavailable),
+			Clock:            httpretrytest.NewFakeClock(time.Now()),
+		}
+
+		t.Run("WHEN HttpGetFull is sent with NoRetries", func(t *testing.T) {
+			options.RetriesMax = NoRetries
+			resp, err := NewHttpRequest(options).HttpGetFull(context.Background())
+			require.NoError(t, err)
+
+			t.Run("THEN a single attempt is made", func(t *testing.T) {
+				assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+				assert.Equal(t, 1, resp.Attempts)
+			})
+		})
+
+		t.Run("WHEN HttpGetFull is sent with UnlimitedRetries", func(t *testing.T) {
+			options.RetriesMax = UnlimitedRetries
+			resp, err := NewHttpRequest(options).HttpGetFull(context.Background())
+			require.NoError(t, err)
+
+			t.Run("THEN it retries past the default limit until success", func(t *testing.T) {
+				assert.Equal(t, http.StatusOK, resp.StatusCode)
+				assert.Equal(t, 20, resp.Attempts)
+			})
+		})
+	})
+
+	t.Run("GIVEN a server failing once AND a negative RetriesMax that isn't a sentinel", func(t *testing.T) {
+		ts := httpretrytest.NewFlakyServer(1, http.StatusServiceUnavailable)
+		defer ts.Close()
+
+		url, err := url.Parse(ts.URL)
+		require.NoError(t, err)
+		api := NewHttpRequest(HttpRequestOptions{
+			URL:              url,
+			RetriesMax:       -5,
+			IsRetryCondition: RetryOnStatus(http.StatusServiceUnavailable),
+			Clock:            httpretrytest.NewFakeClock(time.Now()),
+		})
+
+		t.Run("WHEN HttpGetFull is sent", func(t *testing.T) {
+			resp, err := api.HttpGetFull(context.Background())
+			require.NoError(t, err)
+
+			t.Run("THEN a single attempt is made like NoRetries", func(t *testing.T) {
+				assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+				assert.Equal(t, 1, resp.Attempts)
+				assert.Equal(t, 1, ts.Requests())
+			})
+		})
+	})
+
+	t.Run("GIVEN a server always failing AND UnlimitedRetries", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer ts.Close()
+
+		url, err := url.Parse(ts.URL)
+		require.NoError(t, err)
+		api := NewHttpRequest(HttpRequestOptions{
+			URL:              url,
+			RetriesMax:       UnlimitedRetries,
+			RetriesWait:      time.Millisecond,
+			IsRetryCondition: RetryOnStatus(http.StatusServiceUnavailable),
+		})
+
+		t.Run("WHEN HttpGet is sent with a context deadline", func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+			defer cancel()
+			_, _, err := api.HttpGet(ctx)
+
+			t.Run("THEN it stops at the deadline", func(t *testing.T) {
+				assert.ErrorIs(t, err, context.DeadlineExceeded)
+			})
+		})
+	})
+}
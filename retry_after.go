@@ -0,0 +1,58 @@
+package httpretry
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// parseRetryAfter reads the Retry-After header which is either a number of
+// seconds or an HTTP-date.  Dates in the past yield a zero wait.
+//
+// See https://www.rfc-editor.org/rfc/rfc9110#field.retry-after
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		if seconds > int64(math.MaxInt64/time.Second) {
+			// would overflow, the caller caps it anyway
+			seconds = int64(math.MaxInt64 / time.Second)
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	date, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+	wait := date.Sub(now)
+	if wait < 0 {
+		wait = 0
+	}
+	return wait, true
+}
+
+// retryAfterWait returns the wait requested by the server for 429 and 503
+// responses at now, capped at max.
+func retryAfterWait(resp *http.Response, max time.Duration, now time.Time) (time.Duration, bool) {
+	if resp == nil {
+		return 0, false
+	}
+	if resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode != http.StatusServiceUnavailable {
+		return 0, false
+	}
+	wait, ok := parseRetryAfter(resp.Header.Get("Retry-After"), now)
+	if !ok {
+		return 0, false
+	}
+	if max > 0 && wait > max {
+		wait = max
+	}
+	return wait, true
+}
@@ -0,0 +1,83 @@
+package httpretry
+
+import (
+	"math"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("GIVEN a Retry-After header in delta-seconds", func(t *testing.T) {
+		wait, ok := parseRetryAfter("120", now)
+
+		t.Run("THEN the wait is parsed", func(t *testing.T) {
+			assert.True(t, ok)
+			assert.Equal(t, 2*time.Minute, wait)
+		})
+	})
+
+	t.Run("GIVEN a Retry-After header as an HTTP-date", func(t *testing.T) {
+		wait, ok := parseRetryAfter(now.Add(30*time.Second).Format(http.TimeFormat), now)
+
+		t.Run("THEN the wait is the time until that date", func(t *testing.T) {
+			assert.True(t, ok)
+			assert.Equal(t, 30*time.Second, wait)
+		})
+	})
+
+	t.Run("GIVEN a Retry-After header with a date in the past", func(t *testing.T) {
+		wait, ok := parseRetryAfter(now.Add(-time.Hour).Format(http.TimeFormat), now)
+
+		t.Run("THEN the wait is zero", func(t *testing.T) {
+			assert.True(t, ok)
+			assert.Equal(t, time.Duration(0), wait)
+		})
+	})
+
+	t.Run("GIVEN an invalid Retry-After header", func(t *testing.T) {
+		_, ok := parseRetryAfter("soon", now)
+
+		t.Run("THEN it is ignored", func(t *testing.T) {
+			assert.False(t, ok)
+		})
+	})
+
+	t.Run("GIVEN a 429 response asking for a long wait", func(t *testing.T) {
+		resp := &http.Response{
+			StatusCode: http.StatusTooManyRequests,
+			Header:     http.Header{"Retry-After": []string{"3600"}},
+		}
+
+		t.Run("THEN the wait is capped", func(t *testing.T) {
+			wait, ok := retryAfterWait(resp, time.Minute, now)
+			assert.True(t, ok)
+			assert.Equal(t, time.Minute, wait)
+		})
+	})
+
+	t.Run("GIVEN a Retry-After header overflowing a duration", func(t *testing.T) {
+		wait, ok := parseRetryAfter("99999999999", now)
+
+		t.Run("THEN the wait is clamped instead of wrapping around", func(t *testing.T) {
+			assert.True(t, ok)
+			assert.Equal(t, time.Duration(math.MaxInt64/time.Second)*time.Second, wait)
+		})
+	})
+
+	t.Run("GIVEN a 500 response with a Retry-After header", func(t *testing.T) {
+		resp := &http.Response{
+			StatusCode: http.StatusInternalServerError,
+			Header:     http.Header{"Retry-After": []string{"1"}},
+		}
+
+		t.Run("THEN the header is ignored", func(t *testing.T) {
+			_, ok := retryAfterWait(resp, time.Minute, now)
+			assert.False(t, ok)
+		})
+	})
+}
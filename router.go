@@ -0,0 +1,175 @@
+package httpretry
+
+import (
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// EndpointPolicy tunes the calls to the URLs matching a pattern of a
+// PolicyRouter.  Zero fields keep the settings of the request.
+type EndpointPolicy struct {
+	// RetriesMax max number of attempts, NoRetries for a single attempt
+	RetriesMax int
+
+	Backoff          BackoffStrategy
+	IsRetryCondition RetryPredicate
+	IsRetryError     RetryErrorPredicate
+
+	// RateLimiter is waited on before every attempt to the endpoint, share
+	// one limiter to rate limit every request to a vendor.
+	RateLimiter RateLimiter
+
+	CircuitBreaker *CircuitBreaker
+}
+
+// PolicyRouter selects the EndpointPolicy of a call by its URL, so requests
+// to different vendors get their tuned policies from a single router shared
+// through HttpRequestOptions.PolicyRouter:
+//
+//	router := httpretry.NewPolicyRouter()
+//	router.Handle("api.vendora.com", httpretry.EndpointPolicy{RetriesMax: 3})
+//	router.Handle("*.vendorb.com/v2/", httpretry.EndpointPolicy{RateLimiter: limiter})
+//
+// It is safe for concurrent use.
+type PolicyRouter struct {
+	mu     sync.RWMutex
+	routes []policyRoute
+}
+
+type policyRoute struct {
+	pattern string
+	host    string
+	path    string
+	policy  EndpointPolicy
+}
+
+// NewPolicyRouter returns a router without patterns.
+func NewPolicyRouter() *PolicyRouter {
+	return &PolicyRouter{}
+}
+
+// Handle registers the policy of pattern, replacing the policy of a pattern
+// registered before.  A pattern is a host, "*.example.com" matches the
+// subdomains of example.com, followed by an optional path prefix, for example
+// "api.example.com/v2/".  A pattern starting with "/" matches the path of any
+// host.
+func (p *PolicyRouter) Handle(pattern string, policy EndpointPolicy) {
+	host, path, found := strings.Cut(pattern, "/")
+	if found {
+		path = "/" + path
+	}
+	route := policyRoute{pattern: pattern, host: strings.ToLower(host), path: path, policy: policy}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for i := range p.routes {
+		if p.routes[i].pattern == pattern {
+			p.routes[i] = route
+			return
+		}
+	}
+	p.routes = append(p.routes, route)
+}
+
+// replaceRoutes atomically replaces the patterns by those of other.
+func (p *PolicyRouter) replaceRoutes(other *PolicyRouter) {
+	other.mu.RLock()
+	routes := other.routes
+	other.mu.RUnlock()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.routes = routes
+}
+
+// Match returns the policy of the most specific pattern matching u: an exact
+// host wins over a wildcard, which wins over any host, then the longest path
+// prefix wins.
+func (p *PolicyRouter) Match(u *url.URL) (EndpointPolicy, bool) {
+	if u == nil {
+		return EndpointPolicy{}, false
+	}
+	host := strings.ToLower(strings.TrimSuffix(u.Hostname(), "."))
+	path := u.EscapedPath()
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	var best *policyRoute
+	for i := range p.routes {
+		route := &p.routes[i]
+		if !route.matches(host, path) {
+			continue
+		}
+		if best == nil || route.moreSpecific(best) {
+			best = route
+		}
+	}
+	if best == nil {
+		return EndpointPolicy{}, false
+	}
+	return best.policy, true
+}
+
+func (r *policyRoute) matches(host string, path string) bool {
+	if !strings.HasPrefix(path, r.path) {
+		return false
+	}
+	if suffix, ok := strings.CutPrefix(r.host, "*"); ok {
+		return strings.HasSuffix(host, suffix)
+	}
+	return r.host == "" || r.host == host
+}
+
+// hostRank orders the kinds of host patterns by specificity.
+func (r *policyRoute) hostRank() int {
+	switch {
+	case r.host == "":
+		return 0
+	case strings.HasPrefix(r.host, "*"):
+		return 1
+	default:
+		return 2
+	}
+}
+
+func (r *policyRoute) moreSpecific(other *policyRoute) bool {
+	if r.hostRank() != other.hostRank() {
+		return r.hostRank() > other.hostRank()
+	}
+	if len(r.host) != len(other.host) {
+		return len(r.host) > len(other.host)
+	}
+	return len(r.path) > len(other.path)
+}
+
+// withEndpointPolicy returns a copy of the request with the policy the
+// PolicyRouter matches for its URL applied.
+func (r httpRequest) withEndpointPolicy() httpRequest {
+	if r.PolicyRouter == nil {
+		return r
+	}
+	policy, ok := r.PolicyRouter.Match(r.URL)
+	if !ok {
+		return r
+	}
+	if policy.RetriesMax != 0 {
+		r.RetriesMax = policy.RetriesMax
+	}
+	if policy.Backoff != nil {
+		r.Backoff = policy.Backoff
+	}
+	if policy.IsRetryCondition != nil {
+		r.IsRetryCondition = policy.IsRetryCondition
+	}
+	if policy.IsRetryError != nil {
+		r.IsRetryError = policy.IsRetryError
+	}
+	if policy.RateLimiter != nil {
+		r.RateLimiter = policy.RateLimiter
+	}
+	if policy.CircuitBreaker != nil {
+		r.CircuitBreaker = policy.CircuitBreaker
+	}
+	return r
+}
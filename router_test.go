@@ -0,0 +1,99 @@
+package httpretry
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/mandric/httpretry/httpretrytest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type countingLimiter struct{ waits int }
+
+func (l *countingLimiter) Wait(ctx context.Context) error {
+	l.waits++
+	return nil
+}
+
+func TestPolicyRouter(t *testing.T) {
+
+	t.Run("GIVEN host, wildcard and path patterns", func(t *testing.T) {
+		router := NewPolicyRouter()
+		router.Handle("*.vendor.com", EndpointPolicy{RetriesMax: 1})
+		router.Handle("api.vendor.com", EndpointPolicy{RetriesMax: 2})
+		router.Handle("api.vendor.com/v2/", EndpointPolicy{RetriesMax: 3})
+		router.Handle("/health", EndpointPolicy{RetriesMax: 4})
+
+		match := func(rawURL string) int {
+			u, err := url.Parse(rawURL)
+			require.NoError(t, err)
+			policy, ok := router.Match(u)
+			if !ok {
+				return 0
+			}
+			return policy.RetriesMax
+		}
+
+		t.Run("THEN the most specific pattern wins", func(t *testing.T) {
+			assert.Equal(t, 1, match("https://eu.vendor.com/v2/users"))
+			assert.Equal(t, 2, match("https://API.vendor.com/v1/users"))
+			assert.Equal(t, 3, match("https://api.vendor.com/v2/users"))
+			assert.Equal(t, 4, match("https://other.com/health"))
+			assert.Equal(t, 0, match("https://other.com/users"))
+		})
+
+		t.Run("WHEN a pattern is registered again", func(t *testing.T) {
+			router.Handle("api.vendor.com", EndpointPolicy{RetriesMax: 5})
+
+			t.Run("THEN its policy is replaced", func(t *testing.T) {
+				assert.Equal(t, 5, match("https://api.vendor.com/v1/users"))
+			})
+		})
+	})
+}
+
+func TestIntegration_PolicyRouter(t *testing.T) {
+
+	t.Run("GIVEN a server always failing AND a router limiting its host", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer ts.Close()
+
+		url, err := url.Parse(ts.URL)
+		require.NoError(t, err)
+		limiter := &countingLimiter{}
+		router := NewPolicyRouter()
+		router.Handle(url.Hostname(), EndpointPolicy{RetriesMax: 2, RateLimiter: limiter})
+		options := HttpRequestOptions{
+			URL:          url,
+			Clock:        httpretrytest.NewFakeClock(time.Now()),
+			PolicyRouter: router,
+		}
+
+		t.Run("WHEN HttpGetFull is sent", func(t *testing.T) {
+			resp, err := NewHttpRequest(options).HttpGetFull(context.Background())
+			require.NoError(t, err)
+
+			t.Run("THEN the endpoint policy is applied", func(t *testing.T) {
+				assert.Equal(t, 2, resp.Attempts)
+				assert.Equal(t, 2, limiter.waits)
+			})
+		})
+
+		t.Run("WHEN a method policy is set for GET", func(t *testing.T) {
+			options.MethodPolicies = map[string]MethodPolicy{http.MethodGet: {RetriesMax: 3}}
+			resp, err := NewHttpRequest(options).HttpGetFull(context.Background())
+			require.NoError(t, err)
+
+			t.Run("THEN it takes precedence", func(t *testing.T) {
+				assert.Equal(t, 3, resp.Attempts)
+			})
+		})
+	})
+}
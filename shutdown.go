@@ -0,0 +1,131 @@
+package httpretry
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrShutdown is returned by calls starting an attempt after Shutdown.
+var ErrShutdown = errors.New("httpretry is shut down")
+
+var lifecycle struct {
+	mu       sync.Mutex
+	closing  chan struct{}
+	inFlight int
+	drained  chan struct{}
+}
+
+func init() {
+	lifecycle.closing = make(chan struct{})
+	lifecycle.drained = make(chan struct{})
+}
+
+// Shutdown stops the package for a clean termination of a lambda or
+// container: attempts aren't started anymore, calls waiting to retry return
+// their last result right away and calls starting afterwards fail with
+// ErrShutdown.  It then waits for the attempts in flight until ctx is done
+// and closes the idle connections of the singleton client and of the clients
+// of DefaultClientRegistry.  Requests with their own Client or transport
+// options close theirs with Client.CloseIdleConnections.
+//
+// It returns ctx.Err() when attempts were still in flight.  Shutdown is
+// permanent and affects every request of the process, there is no way to
+// resume calls afterwards.  Later calls wait like the first one.
+func Shutdown(ctx context.Context) error {
+	lifecycle.mu.Lock()
+	select {
+	case <-lifecycle.closing:
+	default:
+		close(lifecycle.closing)
+		if lifecycle.inFlight == 0 {
+			close(lifecycle.drained)
+		}
+	}
+	drained := lifecycle.drained
+	lifecycle.mu.Unlock()
+
+	var err error
+	select {
+	case <-drained:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	closeManagedIdleConnections()
+	return err
+}
+
+// beginAttempt counts an attempt in flight until release is called, it
+// returns ErrShutdown once Shutdown was called.
+func beginAttempt() (release func(), err error) {
+	lifecycle.mu.Lock()
+	defer lifecycle.mu.Unlock()
+	if isClosed(lifecycle.closing) {
+		return nil, ErrShutdown
+	}
+	lifecycle.inFlight++
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			lifecycle.mu.Lock()
+			defer lifecycle.mu.Unlock()
+			lifecycle.inFlight--
+			if lifecycle.inFlight == 0 && isClosed(lifecycle.closing) {
+				close(lifecycle.drained)
+			}
+		})
+	}, nil
+}
+
+func isShutdown() bool {
+	return isClosed(closingChannel())
+}
+
+// closingChannel returns the channel closed by Shutdown.
+func closingChannel() <-chan struct{} {
+	lifecycle.mu.Lock()
+	defer lifecycle.mu.Unlock()
+	return lifecycle.closing
+}
+
+func isClosed(closing <-chan struct{}) bool {
+	select {
+	case <-closing:
+		return true
+	default:
+		return false
+	}
+}
+
+// sleepOrShutdown waits like clock.Sleep, returning ErrShutdown early when
+// Shutdown is called during the wait.
+func sleepOrShutdown(ctx context.Context, clock Clock, wait time.Duration) error {
+	closing := closingChannel()
+	sleepCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		select {
+		case <-closing:
+			cancel()
+		case <-sleepCtx.Done():
+		}
+	}()
+	err := clock.Sleep(sleepCtx, wait)
+	if err != nil && ctx.Err() == nil && isClosed(closing) {
+		return ErrShutdown
+	}
+	return err
+}
+
+// closeManagedIdleConnections closes the idle connections of the clients
+// built by the package.
+func closeManagedIdleConnections() {
+	clientOptionsMu.Lock()
+	created := clientOptionsApplied
+	clientOptionsMu.Unlock()
+	if created {
+		GetSingletonHttpClient().CloseIdleConnections()
+	}
+	DefaultClientRegistry.closeIdleConnections()
+}
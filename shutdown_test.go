@@ -0,0 +1,120 @@
+package httpretry
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// resetLifecycle undoes Shutdown for the next test.
+func resetLifecycle() {
+	lifecycle.mu.Lock()
+	defer lifecycle.mu.Unlock()
+	lifecycle.closing = make(chan struct{})
+	lifecycle.drained = make(chan struct{})
+	lifecycle.inFlight = 0
+}
+
+func TestIntegration_Shutdown(t *testing.T) {
+
+	t.Run("GIVEN a call waiting an hour to retry a 503", func(t *testing.T) {
+		defer resetLifecycle()
+		received := make(chan struct{}, 1)
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			received <- struct{}{}
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer ts.Close()
+
+		url, err := url.Parse(ts.URL)
+		require.NoError(t, err)
+		api := NewHttpRequest(HttpRequestOptions{
+			URL:              url,
+			RetriesWait:      time.Hour,
+			IsRetryCondition: RetryOnStatus(http.StatusServiceUnavailable),
+		})
+		type result struct {
+			resp *Response
+			err  error
+		}
+		done := make(chan result)
+		go func() {
+			resp, err := api.HttpGetFull(context.Background())
+			done <- result{resp, err}
+		}()
+		<-received
+
+		t.Run("WHEN Shutdown is called", func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+			defer cancel()
+			require.NoError(t, Shutdown(ctx))
+
+			t.Run("THEN the call returns its last result without retrying", func(t *testing.T) {
+				select {
+				case res := <-done:
+					require.NoError(t, res.err)
+					assert.Equal(t, http.StatusServiceUnavailable, res.resp.StatusCode)
+					assert.Equal(t, 1, res.resp.Attempts)
+				case <-time.After(time.Second):
+					t.Fatal("the call is still waiting")
+				}
+			})
+
+			t.Run("AND new calls fail", func(t *testing.T) {
+				_, _, err := api.HttpGet(context.Background())
+				assert.ErrorIs(t, err, ErrShutdown)
+			})
+		})
+	})
+
+	t.Run("GIVEN an attempt in flight", func(t *testing.T) {
+		defer resetLifecycle()
+		received := make(chan struct{})
+		respond := make(chan struct{})
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			close(received)
+			<-respond
+		}))
+		defer ts.Close()
+
+		url, err := url.Parse(ts.URL)
+		require.NoError(t, err)
+		done := make(chan error)
+		go func() {
+			_, _, err := NewHttpRequest(HttpRequestOptions{URL: url}).HttpGet(context.Background())
+			done <- err
+		}()
+		<-received
+
+		t.Run("WHEN Shutdown times out", func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+			defer cancel()
+			err := Shutdown(ctx)
+
+			t.Run("THEN the deadline is returned", func(t *testing.T) {
+				assert.ErrorIs(t, err, context.DeadlineExceeded)
+			})
+		})
+
+		t.Run("WHEN the attempt completes during a second Shutdown", func(t *testing.T) {
+			go func() {
+				time.Sleep(10 * time.Millisecond)
+				close(respond)
+			}()
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+			defer cancel()
+			err := Shutdown(ctx)
+
+			t.Run("THEN Shutdown waits for it", func(t *testing.T) {
+				assert.NoError(t, err)
+				assert.NoError(t, <-done)
+			})
+		})
+	})
+}
@@ -0,0 +1,263 @@
+package httpretry
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	sigV4Algorithm   = "AWS4-HMAC-SHA256"
+	sigV4TimeFormat  = "20060102T150405Z"
+	unsignedPayload  = "UNSIGNED-PAYLOAD"
+	amzDateHeader    = "X-Amz-Date"
+	amzTokenHeader   = "X-Amz-Security-Token"
+	amzContentSHA256 = "X-Amz-Content-Sha256"
+)
+
+// AWSCredentials sign requests, SessionToken is set for temporary
+// credentials.
+type AWSCredentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+}
+
+// AWSCredentialsProvider supplies the credentials of every attempt, so
+// rotated or refreshed credentials are picked up by retries.
+type AWSCredentialsProvider interface {
+	Credentials(ctx context.Context) (AWSCredentials, error)
+}
+
+// StaticAWSCredentials always provides the same credentials.
+type StaticAWSCredentials AWSCredentials
+
+func (c StaticAWSCredentials) Credentials(ctx context.Context) (AWSCredentials, error) {
+	return AWSCredentials(c), nil
+}
+
+type SigV4Options struct {
+	// Region of the endpoint, for example us-east-1
+	Region string
+
+	// Service signing name, for example s3, execute-api or es
+	Service string
+
+	Credentials AWSCredentialsProvider
+
+	// UnsignedPayload leaves the body out of the signature, streamed bodies
+	// are never signed since they can't be read twice
+	UnsignedPayload bool
+}
+
+// SigV4Signer signs requests with AWS Signature Version 4 for AWS and S3
+// compatible APIs.  Signatures include a timestamp so they're computed on
+// every attempt, use Middleware as the last middleware of a request:
+//
+//	signer := httpretry.NewSigV4Signer(httpretry.SigV4Options{
+//		Region:      "eu-west-1",
+//		Service:     "execute-api",
+//		Credentials: httpretry.StaticAWSCredentials{AccessKeyID: id, SecretAccessKey: secret},
+//	})
+//	api := httpretry.NewHttpRequest(httpretry.HttpRequestOptions{URL: url, Middleware: []httpretry.Middleware{signer.Middleware}})
+type SigV4Signer struct {
+	options SigV4Options
+	now     func() time.Time
+}
+
+func NewSigV4Signer(options SigV4Options) *SigV4Signer {
+	return &SigV4Signer{options: options, now: time.Now}
+}
+
+// Middleware signs the request before passing it to next.
+func (s *SigV4Signer) Middleware(next Doer) Doer {
+	return DoerFunc(func(req *http.Request) (*http.Response, error) {
+		if err := s.Sign(req); err != nil {
+			return nil, err
+		}
+		return next.Do(req)
+	})
+}
+
+// Sign sets the X-Amz-Date and Authorization headers of req, replacing a
+// previous signature.
+func (s *SigV4Signer) Sign(req *http.Request) error {
+	credentials, err := s.options.Credentials.Credentials(req.Context())
+	if err != nil {
+		return fmt.Errorf("sigv4 credentials: %w", err)
+	}
+	payloadHash, err := s.payloadHash(req)
+	if err != nil {
+		return err
+	}
+
+	now := s.now().UTC()
+	amzDate := now.Format(sigV4TimeFormat)
+	req.Header.Set(amzDateHeader, amzDate)
+	req.Header.Del(amzTokenHeader)
+	if credentials.SessionToken != "" {
+		req.Header.Set(amzTokenHeader, credentials.SessionToken)
+	}
+	if s.options.Service == "s3" || payloadHash == unsignedPayload {
+		req.Header.Set(amzContentSHA256, payloadHash)
+	}
+
+	canonicalHeaders, signedHeaders := sigV4Headers(req)
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		sigV4Path(req.URL, s.options.Service != "s3"),
+		sigV4Query(req.URL),
+		canonicalHeaders,
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := strings.Join([]string{now.Format("20060102"), s.options.Region, s.options.Service, "aws4_request"}, "/")
+	stringToSign := strings.Join([]string{sigV4Algorithm, amzDate, scope, sha256Hex([]byte(canonicalRequest))}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+credentials.SecretAccessKey), now.Format("20060102"))
+	for _, part := range []string{s.options.Region, s.options.Service, "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		sigV4Algorithm, credentials.AccessKeyID, scope, signedHeaders, signature))
+	return nil
+}
+
+func (s *SigV4Signer) payloadHash(req *http.Request) (string, error) {
+	if s.options.UnsignedPayload || isStreamedBody(req) {
+		return unsignedPayload, nil
+	}
+	body, err := rereadBody(req)
+	if err != nil {
+		return "", err
+	}
+	return sha256Hex(body), nil
+}
+
+// rereadBody returns the body of req and leaves req with an unread copy.
+func rereadBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		defer body.Close()
+		return io.ReadAll(body)
+	}
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
+
+// sigV4Headers signs host, content-type and the x-amz-* headers.
+func sigV4Headers(req *http.Request) (canonical string, signed string) {
+	host := req.Host
+	if host == "" {
+		host = req.URL.Host
+	}
+	values := map[string]string{"host": host}
+	for name, headerValues := range req.Header {
+		lower := strings.ToLower(name)
+		if lower != "content-type" && !strings.HasPrefix(lower, "x-amz-") {
+			continue
+		}
+		trimmed := make([]string, len(headerValues))
+		for i, value := range headerValues {
+			trimmed[i] = strings.Join(strings.Fields(value), " ")
+		}
+		values[lower] = strings.Join(trimmed, ",")
+	}
+
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var b strings.Builder
+	for _, name := range names {
+		b.WriteString(name + ":" + values[name] + "\n")
+	}
+	return b.String(), strings.Join(names, ";")
+}
+
+// sigV4Path encodes each path segment, twice for every service but S3.
+func sigV4Path(u *url.URL, doubleEncode bool) string {
+	if u.Path == "" {
+		return "/"
+	}
+	segments := strings.Split(u.Path, "/")
+	for i, segment := range segments {
+		segments[i] = awsEscape(segment)
+		if doubleEncode {
+			segments[i] = awsEscape(segments[i])
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+func sigV4Query(u *url.URL) string {
+	query := u.Query()
+	pairs := make([][2]string, 0, len(query))
+	for key, values := range query {
+		for _, value := range values {
+			pairs = append(pairs, [2]string{awsEscape(key), awsEscape(value)})
+		}
+	}
+	// sorted by key then value, joining first would sort "a-b" before "a"
+	sort.Slice(pairs, func(i, j int) bool {
+		if pairs[i][0] != pairs[j][0] {
+			return pairs[i][0] < pairs[j][0]
+		}
+		return pairs[i][1] < pairs[j][1]
+	})
+	joined := make([]string, len(pairs))
+	for i, pair := range pairs {
+		joined[i] = pair[0] + "=" + pair[1]
+	}
+	return strings.Join(joined, "&")
+}
+
+// awsEscape percent-encodes everything but the unreserved characters of
+// RFC 3986, with upper case hex digits.
+func awsEscape(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' || c == '-' || c == '_' || c == '.' || c == '~' {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
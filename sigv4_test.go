@@ -0,0 +1,125 @@
+package httpretry
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// credentials and expected signatures of the AWS Signature Version 4 test
+// suite
+var sigV4TestOptions = SigV4Options{
+	Region:  "us-east-1",
+	Service: "service",
+	Credentials: StaticAWSCredentials{
+		AccessKeyID:     "AKIDEXAMPLE",
+		SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY",
+	},
+}
+
+func sigV4TestTime() time.Time {
+	return time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC)
+}
+
+func TestSigV4Signer(t *testing.T) {
+
+	t.Run("GIVEN a signer at the time of the AWS test suite", func(t *testing.T) {
+		signer := NewSigV4Signer(sigV4TestOptions)
+		signer.now = sigV4TestTime
+
+		t.Run("WHEN the get-vanilla request is signed", func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, "https://example.amazonaws.com/", nil)
+			require.NoError(t, err)
+			require.NoError(t, signer.Sign(req))
+
+			t.Run("THEN the signature matches the test suite", func(t *testing.T) {
+				assert.Equal(t, "20150830T123600Z", req.Header.Get("X-Amz-Date"))
+				assert.Equal(t, "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, "+
+					"SignedHeaders=host;x-amz-date, "+
+					"Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31", req.Header.Get("Authorization"))
+			})
+		})
+
+		t.Run("WHEN the get-vanilla-query-order-key-case request is signed", func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, "https://example.amazonaws.com/?Param2=value2&Param1=value1", nil)
+			require.NoError(t, err)
+			require.NoError(t, signer.Sign(req))
+
+			t.Run("THEN the signature matches the test suite", func(t *testing.T) {
+				assert.Contains(t, req.Header.Get("Authorization"), "Signature=b97d918cfa904a5beff61c982a1b6f458b799221646efd99d3219ec94cdf2500")
+			})
+		})
+
+		t.Run("WHEN a request with temporary credentials for S3 is signed", func(t *testing.T) {
+			signer := NewSigV4Signer(SigV4Options{
+				Region:      "us-east-1",
+				Service:     "s3",
+				Credentials: StaticAWSCredentials{AccessKeyID: "AKID", SecretAccessKey: "secret", SessionToken: "token"},
+			})
+			req, err := http.NewRequest(http.MethodPut, "https://bucket.s3.amazonaws.com/a b.txt", nil)
+			require.NoError(t, err)
+			require.NoError(t, signer.Sign(req))
+
+			t.Run("THEN the token and payload hash are signed", func(t *testing.T) {
+				assert.Equal(t, "token", req.Header.Get("X-Amz-Security-Token"))
+				assert.Equal(t, sha256Hex(nil), req.Header.Get("X-Amz-Content-Sha256"))
+				assert.Contains(t, req.Header.Get("Authorization"), "SignedHeaders=host;x-amz-content-sha256;x-amz-date;x-amz-security-token,")
+			})
+
+			t.Run("AND the path is encoded once", func(t *testing.T) {
+				assert.Equal(t, "/a%20b.txt", sigV4Path(req.URL, false))
+				assert.Equal(t, "/a%2520b.txt", sigV4Path(req.URL, true))
+			})
+		})
+	})
+}
+
+func TestIntegration_SigV4Middleware(t *testing.T) {
+
+	t.Run("GIVEN a server that returns 503 once", func(t *testing.T) {
+		var dates []string
+		var signatures []string
+		attempts := 1
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			dates = append(dates, r.Header.Get("X-Amz-Date"))
+			signatures = append(signatures, r.Header.Get("Authorization"))
+			if attempts > 0 {
+				attempts--
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}
+		}))
+		defer ts.Close()
+
+		url, err := url.Parse(ts.URL)
+		require.NoError(t, err)
+
+		t.Run("WHEN a signed HttpPost is retried", func(t *testing.T) {
+			signer := NewSigV4Signer(sigV4TestOptions)
+			now := sigV4TestTime()
+			signer.now = func() time.Time {
+				now = now.Add(time.Second)
+				return now
+			}
+			api := NewHttpRequest(HttpRequestOptions{
+				URL:              url,
+				RetriesWait:      time.Millisecond,
+				IsRetryCondition: RetryOnStatus(http.StatusServiceUnavailable),
+				Middleware:       []Middleware{signer.Middleware},
+			})
+			_, status, err := api.HttpPost(context.Background(), []byte(`{"a":1}`))
+			require.NoError(t, err)
+
+			t.Run("THEN every attempt is signed again", func(t *testing.T) {
+				assert.Equal(t, http.StatusOK, status)
+				assert.Equal(t, []string{"20150830T123601Z", "20150830T123602Z"}, dates)
+				assert.NotEqual(t, signatures[0], signatures[1])
+			})
+		})
+	})
+}
@@ -0,0 +1,171 @@
+package httpretry
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Event is a server-sent event.
+type Event struct {
+	ID    string
+	Type  string
+	Data  string
+	Retry time.Duration
+}
+
+// EventStream delivers the events of a Subscribe call.
+type EventStream struct {
+	// C receives the events, it is closed when the subscription ends
+	C <-chan Event
+
+	err error
+}
+
+// Err returns why the subscription ended, it is only valid once C is
+// closed.  It is nil when the server ended the stream with 204 No Content
+// and the context error when ctx was cancelled.
+func (s *EventStream) Err() error {
+	return s.err
+}
+
+// Subscribe opens a server-sent events stream with a GET request and
+// delivers its events on the returned stream until ctx is cancelled:
+//
+//	stream := api.Subscribe(ctx)
+//	for event := range stream.C {
+//		...
+//	}
+//	if err := stream.Err(); err != nil && !errors.Is(err, context.Canceled) {
+//		...
+//	}
+//
+// Connecting is retried like HttpGetStream.  When the connection drops the
+// stream reconnects with the Last-Event-ID header after the retry interval
+// sent by the server, or else after the backoff.  A non-2xx response or a
+// content type other than text/event-stream ends the subscription.  The
+// client must not have a Timeout, it would cut long lived streams.
+func (r httpRequest) Subscribe(ctx context.Context, opts ...CallOption) *EventStream {
+	events := make(chan Event)
+	stream := &EventStream{C: events}
+	go func() {
+		defer close(events)
+		stream.err = r.subscribe(ctx, events, opts)
+	}()
+	return stream
+}
+
+func (r httpRequest) subscribe(ctx context.Context, events chan<- Event, opts []CallOption) error {
+	var lastID string
+	var serverRetry time.Duration
+	for drops := 1; ; drops++ {
+		callOpts := append([]CallOption{
+			WithHeader("Accept", "text/event-stream"),
+			WithHeader("Cache-Control", "no-cache"),
+		}, opts...)
+		if lastID != "" {
+			callOpts = append(callOpts, WithHeader("Last-Event-ID", lastID))
+		}
+		resp, err := r.HttpGetStream(ctx, callOpts...)
+		if err != nil {
+			return err
+		}
+		if resp.StatusCode == http.StatusNoContent {
+			resp.Body.Close()
+			return nil
+		}
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			body, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			return unexpectedStatusError([]int{http.StatusOK}, resp.StatusCode, resp.Header, r.URL, body)
+		}
+		if mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type")); mediaType != "text/event-stream" {
+			resp.Body.Close()
+			return fmt.Errorf("unexpected content type %q for an event stream", resp.Header.Get("Content-Type"))
+		}
+
+		received, err := readEvents(ctx, resp.Body, events, &lastID, &serverRetry)
+		resp.Body.Close()
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if received {
+			drops = 1
+		}
+		wait := serverRetry
+		if wait == 0 {
+			wait = r.Backoff.Backoff(drops)
+		}
+		r.logRetry(LogLevelInfo, "Request event stream dropped, reconnecting", Fields{
+			"attempt":    drops,
+			"wait":       wait,
+			"error":      err,
+			"request_id": RequestIDFromContext(ctx),
+		})
+		if err := r.clock().Sleep(ctx, wait); err != nil {
+			return err
+		}
+	}
+}
+
+// readEvents parses the stream until it ends, see
+// https://html.spec.whatwg.org/multipage/server-sent-events.html#event-stream-interpretation
+// It reports whether any event was delivered.
+func readEvents(ctx context.Context, body io.Reader, events chan<- Event, lastID *string, retry *time.Duration) (bool, error) {
+	reader := bufio.NewReader(body)
+	received := false
+	var data []string
+	eventType := ""
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			// an event without its blank line is incomplete and dropped
+			return received, err
+		}
+		line = strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r")
+
+		if line == "" {
+			if len(data) > 0 {
+				if eventType == "" {
+					eventType = "message"
+				}
+				event := Event{ID: *lastID, Type: eventType, Data: strings.Join(data, "\n"), Retry: *retry}
+				select {
+				case events <- event:
+					received = true
+				case <-ctx.Done():
+					return received, ctx.Err()
+				}
+			}
+			data, eventType = nil, ""
+			continue
+		}
+		if strings.HasPrefix(line, ":") {
+			// comment, often sent as a keep alive
+			continue
+		}
+
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "data":
+			data = append(data, value)
+		case "event":
+			eventType = value
+		case "id":
+			if !strings.ContainsRune(value, 0) {
+				*lastID = value
+			}
+		case "retry":
+			if ms, err := strconv.Atoi(value); err == nil && ms >= 0 {
+				*retry = time.Duration(ms) * time.Millisecond
+			}
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package httpretry
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntegration_Subscribe(t *testing.T) {
+
+	t.Run("GIVEN an event stream dropping the connection after each batch", func(t *testing.T) {
+		var lastEventIDs []string
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			lastEventIDs = append(lastEventIDs, r.Header.Get("Last-Event-ID"))
+			assert.Equal(t, "text/event-stream", r.Header.Get("Accept"))
+			switch len(lastEventIDs) {
+			case 1:
+				w.Header().Set("Content-Type", "text/event-stream")
+				fmt.Fprint(w, ": keep alive\nretry: 5\nid: 1\ndata: a\n\n")
+			case 2:
+				w.Header().Set("Content-Type", "text/event-stream; charset=utf-8")
+				fmt.Fprint(w, "event: update\r\nid: 2\r\ndata: b\r\ndata:c\r\n\r\ndata: incomplete\n")
+			default:
+				w.WriteHeader(http.StatusNoContent)
+			}
+		}))
+		defer ts.Close()
+
+		url, err := url.Parse(ts.URL)
+		require.NoError(t, err)
+
+		t.Run("WHEN Subscribe is called", func(t *testing.T) {
+			api := NewHttpRequest(HttpRequestOptions{URL: url, RetriesWait: time.Millisecond})
+			stream := api.Subscribe(context.Background())
+			var events []Event
+			for event := range stream.C {
+				events = append(events, event)
+			}
+
+			t.Run("THEN the events of every connection are delivered", func(t *testing.T) {
+				assert.Equal(t, []Event{
+					{ID: "1", Type: "message", Data: "a", Retry: 5 * time.Millisecond},
+					{ID: "2", Type: "update", Data: "b\nc", Retry: 5 * time.Millisecond},
+				}, events)
+			})
+
+			t.Run("AND reconnections send the last event ID", func(t *testing.T) {
+				assert.Equal(t, []string{"", "1", "2"}, lastEventIDs)
+			})
+
+			t.Run("AND 204 No Content ends the subscription", func(t *testing.T) {
+				assert.NoError(t, stream.Err())
+			})
+		})
+	})
+
+	t.Run("GIVEN a server that doesn't stream events", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{}`))
+		}))
+		defer ts.Close()
+
+		url, err := url.Parse(ts.URL)
+		require.NoError(t, err)
+
+		t.Run("WHEN Subscribe is called", func(t *testing.T) {
+			stream := NewHttpRequest(HttpRequestOptions{URL: url}).Subscribe(context.Background())
+			for range stream.C {
+			}
+
+			t.Run("THEN the subscription fails", func(t *testing.T) {
+				require.Error(t, stream.Err())
+				assert.True(t, strings.Contains(stream.Err().Error(), "application/json"))
+			})
+		})
+	})
+
+	t.Run("GIVEN a stream that stays open", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/event-stream")
+			fmt.Fprint(w, "data: first\n\n")
+			w.(http.Flusher).Flush()
+			<-r.Context().Done()
+		}))
+		defer ts.Close()
+
+		url, err := url.Parse(ts.URL)
+		require.NoError(t, err)
+
+		t.Run("WHEN the context is cancelled", func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			stream := NewHttpRequest(HttpRequestOptions{URL: url}).Subscribe(ctx)
+			event := <-stream.C
+			cancel()
+			for range stream.C {
+			}
+
+			t.Run("THEN the subscription ends with the context error", func(t *testing.T) {
+				assert.Equal(t, "first", event.Data)
+				assert.ErrorIs(t, stream.Err(), context.Canceled)
+			})
+		})
+	})
+}
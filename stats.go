@@ -0,0 +1,121 @@
+package httpretry
+
+import (
+	"expvar"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// StatsSink is a minimal metrics backend for stacks other than Prometheus,
+// see NewStatsMetrics.  tags hold the method, the host and, for responses,
+// the status class.  Implementations must be safe for concurrent use.
+type StatsSink interface {
+	IncrCounter(name string, tags map[string]string)
+	ObserveLatency(name string, duration time.Duration, tags map[string]string)
+}
+
+// NewStatsMetrics returns a MetricsCollector reporting to sink:
+//
+//	metrics := httpretry.NewStatsMetrics(httpretry.NewExpvarSink("httpretry"))
+//	api := httpretry.NewHttpRequest(httpretry.HttpRequestOptions{URL: url, Metrics: metrics})
+//
+// Reported metrics, the same as PrometheusMetrics:
+//
+//   - requests counter of calls made, retries excluded
+//   - retries counter of attempts that were retried
+//   - responses counter of attempts, tagged by status class
+//   - attempt_duration latency of a single attempt
+//   - call_duration latency of a call including retries
+func NewStatsMetrics(sink StatsSink) MetricsCollector {
+	return statsMetrics{sink: sink}
+}
+
+type statsMetrics struct {
+	sink StatsSink
+}
+
+func (m statsMetrics) ObserveAttempt(method string, host string, statusCode int, err error, duration time.Duration) {
+	m.sink.IncrCounter("responses", map[string]string{"method": method, "host": host, "class": StatusClass(statusCode, err)})
+	m.sink.ObserveLatency("attempt_duration", duration, map[string]string{"method": method, "host": host})
+}
+
+func (m statsMetrics) ObserveRetry(method string, host string) {
+	m.sink.IncrCounter("retries", map[string]string{"method": method, "host": host})
+}
+
+func (m statsMetrics) ObserveCall(method string, host string, statusCode int, err error, duration time.Duration) {
+	tags := map[string]string{"method": method, "host": host}
+	m.sink.IncrCounter("requests", tags)
+	m.sink.ObserveLatency("call_duration", duration, tags)
+}
+
+// ExpvarSink is a StatsSink publishing its metrics with expvar, served as
+// JSON by the /debug/vars handler.  Every metric is a map keyed by the
+// sorted tags, like "class=2xx,host=example.com,method=GET".  Latencies are
+// maps of count and total seconds.
+type ExpvarSink struct {
+	root *expvar.Map
+
+	mu      sync.Mutex
+	metrics map[string]*expvar.Map
+}
+
+// NewExpvarSink publishes the metrics under name.  expvar names are global,
+// calling it twice with the same name returns sinks sharing the same map.
+func NewExpvarSink(name string) *ExpvarSink {
+	root, ok := expvar.Get(name).(*expvar.Map)
+	if !ok {
+		root = expvar.NewMap(name)
+	}
+	return &ExpvarSink{root: root, metrics: map[string]*expvar.Map{}}
+}
+
+func (s *ExpvarSink) IncrCounter(name string, tags map[string]string) {
+	s.metric(name).Add(formatTags(tags, "=", ","), 1)
+}
+
+func (s *ExpvarSink) ObserveLatency(name string, duration time.Duration, tags map[string]string) {
+	metric := s.metric(name)
+	key := formatTags(tags, "=", ",")
+	latency, ok := metric.Get(key).(*expvar.Map)
+	if !ok {
+		s.mu.Lock()
+		if latency, ok = metric.Get(key).(*expvar.Map); !ok {
+			latency = new(expvar.Map).Init()
+			metric.Set(key, latency)
+		}
+		s.mu.Unlock()
+	}
+	latency.Add("count", 1)
+	latency.AddFloat("seconds", duration.Seconds())
+}
+
+func (s *ExpvarSink) metric(name string) *expvar.Map {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	metric, ok := s.metrics[name]
+	if !ok {
+		if metric, ok = s.root.Get(name).(*expvar.Map); !ok {
+			metric = new(expvar.Map).Init()
+			s.root.Set(name, metric)
+		}
+		s.metrics[name] = metric
+	}
+	return metric
+}
+
+// formatTags joins tags sorted by key, for example "host=a,method=GET".
+func formatTags(tags map[string]string, assign string, separator string) string {
+	keys := make([]string, 0, len(tags))
+	for key := range tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, len(keys))
+	for i, key := range keys {
+		pairs[i] = key + assign + tags[key]
+	}
+	return strings.Join(pairs, separator)
+}
@@ -0,0 +1,63 @@
+package httpretry
+
+import (
+	"context"
+	"expvar"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntegration_ExpvarSink(t *testing.T) {
+
+	t.Run("GIVEN a server that returns 503 once", func(t *testing.T) {
+		attempts := 1
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if attempts > 0 {
+				attempts--
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}
+		}))
+		defer ts.Close()
+
+		url, err := url.Parse(ts.URL)
+		require.NoError(t, err)
+
+		t.Run("WHEN HttpGet is sent with expvar metrics", func(t *testing.T) {
+			sink := NewExpvarSink("httpretry_test")
+			api := NewHttpRequest(HttpRequestOptions{
+				URL:              url,
+				RetriesWait:      time.Millisecond,
+				IsRetryCondition: RetryOnStatus(http.StatusServiceUnavailable),
+				Metrics:          NewStatsMetrics(sink),
+			})
+			_, _, err := api.HttpGet(context.Background())
+			require.NoError(t, err)
+
+			t.Run("THEN the counters are published", func(t *testing.T) {
+				root := expvar.Get("httpretry_test").(*expvar.Map)
+				tags := "host=" + url.Host + ",method=GET"
+				assert.Equal(t, "1", root.Get("requests").(*expvar.Map).Get(tags).String())
+				assert.Equal(t, "1", root.Get("retries").(*expvar.Map).Get(tags).String())
+				responses := root.Get("responses").(*expvar.Map)
+				assert.Equal(t, "1", responses.Get("class=5xx,"+tags).String())
+				assert.Equal(t, "1", responses.Get("class=2xx,"+tags).String())
+			})
+
+			t.Run("AND the latencies are counted", func(t *testing.T) {
+				root := expvar.Get("httpretry_test").(*expvar.Map)
+				attempts := root.Get("attempt_duration").(*expvar.Map).Get("host=" + url.Host + ",method=GET").(*expvar.Map)
+				assert.Equal(t, "2", attempts.Get("count").String())
+			})
+
+			t.Run("AND a sink with the same name shares the map", func(t *testing.T) {
+				assert.Same(t, sink.root, NewExpvarSink("httpretry_test").root)
+			})
+		})
+	})
+}
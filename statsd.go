@@ -0,0 +1,77 @@
+package httpretry
+
+import (
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type StatsdOptions struct {
+	// Address of the statsd agent
+	// defaults to 127.0.0.1:8125
+	Address string
+
+	// Prefix is prepended to the metric names with a dot
+	// defaults to httpretry
+	Prefix string
+
+	// DogStatsD sends tags with the DogStatsD extension, plain statsd has
+	// no tags so they are dropped otherwise.
+	DogStatsD bool
+}
+
+// StatsdSink is a StatsSink sending metrics over UDP to a statsd or DogStatsD
+// agent, counters as "c" and latencies as "ms" timers.  Sending is best
+// effort, packets the agent doesn't receive are lost.
+type StatsdSink struct {
+	conn      net.Conn
+	prefix    string
+	dogStatsD bool
+}
+
+// NewStatsdSink fails only when the address can't be resolved, UDP doesn't
+// need the agent to be up.
+func NewStatsdSink(options StatsdOptions) (*StatsdSink, error) {
+	if options.Address == "" {
+		options.Address = "127.0.0.1:8125"
+	}
+	if options.Prefix == "" {
+		options.Prefix = "httpretry"
+	}
+	conn, err := net.Dial("udp", options.Address)
+	if err != nil {
+		return nil, err
+	}
+	return &StatsdSink{conn: conn, prefix: options.Prefix, dogStatsD: options.DogStatsD}, nil
+}
+
+func (s *StatsdSink) IncrCounter(name string, tags map[string]string) {
+	s.send(name, "1", "c", tags)
+}
+
+func (s *StatsdSink) ObserveLatency(name string, duration time.Duration, tags map[string]string) {
+	s.send(name, strconv.FormatFloat(float64(duration)/float64(time.Millisecond), 'f', -1, 64), "ms", tags)
+}
+
+// Close closes the UDP socket.
+func (s *StatsdSink) Close() error {
+	return s.conn.Close()
+}
+
+func (s *StatsdSink) send(name string, value string, kind string, tags map[string]string) {
+	var b strings.Builder
+	b.WriteString(s.prefix)
+	b.WriteString(".")
+	b.WriteString(name)
+	b.WriteString(":")
+	b.WriteString(value)
+	b.WriteString("|")
+	b.WriteString(kind)
+	if s.dogStatsD && len(tags) > 0 {
+		b.WriteString("|#")
+		b.WriteString(formatTags(tags, ":", ","))
+	}
+	// a write error means the packet is lost, like an unreachable agent
+	s.conn.Write([]byte(b.String()))
+}
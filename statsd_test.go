@@ -0,0 +1,54 @@
+package httpretry
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatsdSink(t *testing.T) {
+
+	t.Run("GIVEN a statsd agent", func(t *testing.T) {
+		agent, err := net.ListenPacket("udp", "127.0.0.1:0")
+		require.NoError(t, err)
+		defer agent.Close()
+
+		receive := func() string {
+			buf := make([]byte, 512)
+			agent.SetReadDeadline(time.Now().Add(time.Second))
+			n, _, err := agent.ReadFrom(buf)
+			require.NoError(t, err)
+			return string(buf[:n])
+		}
+		tags := map[string]string{"method": "GET", "host": "example.com"}
+
+		t.Run("WHEN a plain statsd sink reports", func(t *testing.T) {
+			sink, err := NewStatsdSink(StatsdOptions{Address: agent.LocalAddr().String()})
+			require.NoError(t, err)
+			defer sink.Close()
+
+			sink.IncrCounter("requests", tags)
+			sink.ObserveLatency("call_duration", 1500*time.Microsecond, tags)
+
+			t.Run("THEN the metrics are sent without tags", func(t *testing.T) {
+				assert.Equal(t, "httpretry.requests:1|c", receive())
+				assert.Equal(t, "httpretry.call_duration:1.5|ms", receive())
+			})
+		})
+
+		t.Run("WHEN a DogStatsD sink reports", func(t *testing.T) {
+			sink, err := NewStatsdSink(StatsdOptions{Address: agent.LocalAddr().String(), Prefix: "myapp", DogStatsD: true})
+			require.NoError(t, err)
+			defer sink.Close()
+
+			sink.IncrCounter("retries", tags)
+
+			t.Run("THEN the tags are sent", func(t *testing.T) {
+				assert.Equal(t, "myapp.retries:1|c|#host:example.com,method:GET", receive())
+			})
+		})
+	})
+}
@@ -0,0 +1,93 @@
+package httpretry
+
+import (
+	"net/http"
+)
+
+// StatusDecision is what a StatusClassifier decides for a response.
+type StatusDecision int
+
+const (
+	// StatusSucceed returns the response without retrying.
+	StatusSucceed StatusDecision = iota
+	// StatusRetry retries the request.
+	StatusRetry
+	// StatusFail returns the response without retrying but counts it as a
+	// failure of the upstream, see CircuitBreaker.
+	StatusFail
+)
+
+// StatusClassifier decides from the status code of a response whether it is
+// retried, see HttpRequestOptions.StatusClassifier.  Codes takes precedence
+// over Classes, statuses in neither succeed.
+type StatusClassifier struct {
+	// Codes maps status codes to decisions, for example 501 to StatusFail
+	Codes map[int]StatusDecision
+
+	// Classes maps status classes to decisions, for example 5 for 500 to
+	// 599
+	Classes map[int]StatusDecision
+
+	// IdempotentOnly fails instead of retrying statuses that Classes
+	// retries for requests that aren't safe to repeat: POST, PATCH and
+	// other non idempotent methods without an Idempotency-Key header.  The
+	// upstream may have processed them, retrying could duplicate a write.
+	IdempotentOnly bool
+}
+
+// DefaultStatusClassifier retries 408, 425, 429 and 503 responses, which
+// are answered before the request is processed, for every method.  Other
+// 5xx responses are only retried for idempotent methods and requests with an
+// Idempotency-Key header, see IdempotentOnly, but 501 fails since retrying
+// won't implement the method.
+func DefaultStatusClassifier() *StatusClassifier {
+	return &StatusClassifier{
+		Codes: map[int]StatusDecision{
+			http.StatusRequestTimeout:     StatusRetry,
+			http.StatusTooEarly:           StatusRetry,
+			http.StatusTooManyRequests:    StatusRetry,
+			http.StatusServiceUnavailable: StatusRetry,
+			http.StatusNotImplemented:     StatusFail,
+		},
+		Classes: map[int]StatusDecision{
+			5: StatusRetry,
+		},
+		IdempotentOnly: true,
+	}
+}
+
+// Classify returns the decision for code, a nil classifier succeeds.
+func (c *StatusClassifier) Classify(code int) StatusDecision {
+	if c == nil {
+		return StatusSucceed
+	}
+	if decision, ok := c.Codes[code]; ok {
+		return decision
+	}
+	return c.Classes[code/100]
+}
+
+// classify is Classify for a response to req, see IdempotentOnly.
+func (c *StatusClassifier) classify(req *http.Request, code int) StatusDecision {
+	decision := c.Classify(code)
+	if c == nil || !c.IdempotentOnly || decision != StatusRetry || req == nil {
+		return decision
+	}
+	if _, ok := c.Codes[code]; ok {
+		return decision
+	}
+	if idempotentMethods[req.Method] || req.Header.Get(IdempotencyKeyHeader) != "" {
+		return decision
+	}
+	return StatusFail
+}
+
+// RetryPredicate returns the classifier as a RetryPredicate, to combine it
+// with others:
+//
+//	IsRetryCondition: httpretry.And(httpretry.RetryOnIdempotent(), classifier.RetryPredicate()),
+func (c *StatusClassifier) RetryPredicate() RetryPredicate {
+	return func(resp *http.Response, retryCount int) bool {
+		return c.classify(resp.Request, resp.StatusCode) == StatusRetry
+	}
+}
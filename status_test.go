@@ -0,0 +1,152 @@
+package httpretry
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatusClassifier(t *testing.T) {
+
+	t.Run("GIVEN the default classifier", func(t *testing.T) {
+		classifier := DefaultStatusClassifier()
+
+		t.Run("THEN transient statuses are retried", func(t *testing.T) {
+			for _, code := range []int{408, 425, 429, 500, 502, 503, 504, 599} {
+				assert.Equal(t, StatusRetry, classifier.Classify(code), code)
+			}
+		})
+
+		t.Run("AND 501 fails", func(t *testing.T) {
+			assert.Equal(t, StatusFail, classifier.Classify(http.StatusNotImplemented))
+		})
+
+		t.Run("AND other statuses succeed", func(t *testing.T) {
+			for _, code := range []int{200, 201, 304, 400, 404, 409} {
+				assert.Equal(t, StatusSucceed, classifier.Classify(code), code)
+			}
+		})
+
+		t.Run("WHEN the request is a POST", func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodPost, "http://example.com", nil)
+			require.NoError(t, err)
+
+			t.Run("THEN only statuses of unprocessed requests are retried", func(t *testing.T) {
+				for _, code := range []int{408, 425, 429, 503} {
+					assert.Equal(t, StatusRetry, classifier.classify(req, code), code)
+				}
+				for _, code := range []int{500, 502, 504} {
+					assert.Equal(t, StatusFail, classifier.classify(req, code), code)
+				}
+			})
+
+			t.Run("AND other 5xx are retried with an Idempotency-Key", func(t *testing.T) {
+				req.Header.Set(IdempotencyKeyHeader, "key")
+				assert.Equal(t, StatusRetry, classifier.classify(req, http.StatusInternalServerError))
+			})
+		})
+	})
+
+	t.Run("GIVEN a nil classifier", func(t *testing.T) {
+		var classifier *StatusClassifier
+
+		t.Run("THEN every status succeeds", func(t *testing.T) {
+			assert.Equal(t, StatusSucceed, classifier.Classify(http.StatusServiceUnavailable))
+		})
+	})
+}
+
+func TestIntegration_StatusClassifier(t *testing.T) {
+
+	t.Run("GIVEN a server answering with the status of the status query parameter", func(t *testing.T) {
+		calls := 0
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			switch r.URL.Query().Get("status") {
+			case "503":
+				w.WriteHeader(http.StatusServiceUnavailable)
+			case "500":
+				w.WriteHeader(http.StatusInternalServerError)
+			case "409":
+				w.WriteHeader(http.StatusConflict)
+			}
+		}))
+		defer ts.Close()
+
+		url, err := url.Parse(ts.URL)
+		require.NoError(t, err)
+
+		t.Run("WHEN a 503 is answered without a retry condition", func(t *testing.T) {
+			calls = 0
+			api := NewHttpRequest(HttpRequestOptions{URL: url, RetriesMax: 3, RetriesWait: time.Millisecond})
+			_, status, err := api.WithQuery("status", "503").HttpGet(context.Background())
+			require.NoError(t, err)
+
+			t.Run("THEN the default classifier retries it", func(t *testing.T) {
+				assert.Equal(t, http.StatusServiceUnavailable, status)
+				assert.Equal(t, 3, calls)
+			})
+		})
+
+		t.Run("WHEN a POST is answered 500 without a retry condition", func(t *testing.T) {
+			calls = 0
+			api := NewHttpRequest(HttpRequestOptions{URL: url, RetriesMax: 3, RetriesWait: time.Millisecond})
+			_, status, err := api.WithQuery("status", "500").HttpPost(context.Background(), []byte(`{}`))
+			require.NoError(t, err)
+
+			t.Run("THEN the default classifier does not retry it", func(t *testing.T) {
+				assert.Equal(t, http.StatusInternalServerError, status)
+				assert.Equal(t, 1, calls)
+			})
+		})
+
+		t.Run("WHEN a GET is answered 500 without a retry condition", func(t *testing.T) {
+			calls = 0
+			api := NewHttpRequest(HttpRequestOptions{URL: url, RetriesMax: 3, RetriesWait: time.Millisecond})
+			_, _, err := api.WithQuery("status", "500").HttpGet(context.Background())
+			require.NoError(t, err)
+
+			t.Run("THEN the default classifier retries it", func(t *testing.T) {
+				assert.Equal(t, 3, calls)
+			})
+		})
+
+		t.Run("WHEN a 409 is answered with a classifier retrying it", func(t *testing.T) {
+			calls = 0
+			api := NewHttpRequest(HttpRequestOptions{
+				URL:              url,
+				RetriesMax:       3,
+				RetriesWait:      time.Millisecond,
+				StatusClassifier: &StatusClassifier{Codes: map[int]StatusDecision{http.StatusConflict: StatusRetry}},
+			})
+			_, _, err := api.WithQuery("status", "409").HttpGet(context.Background())
+			require.NoError(t, err)
+
+			t.Run("THEN it is retried", func(t *testing.T) {
+				assert.Equal(t, 3, calls)
+			})
+		})
+
+		t.Run("WHEN IsRetryCondition is set", func(t *testing.T) {
+			calls = 0
+			api := NewHttpRequest(HttpRequestOptions{
+				URL:              url,
+				RetriesMax:       3,
+				RetriesWait:      time.Millisecond,
+				IsRetryCondition: RetryOnStatus(http.StatusConflict),
+			})
+			_, _, err := api.WithQuery("status", "503").HttpGet(context.Background())
+			require.NoError(t, err)
+
+			t.Run("THEN the classifier is not used", func(t *testing.T) {
+				assert.Equal(t, 1, calls)
+			})
+		})
+	})
+}
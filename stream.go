@@ -0,0 +1,37 @@
+package httpretry
+
+import (
+	"context"
+	"net/http"
+)
+
+// DoStream sends a request and returns the response of the final attempt with
+// its body unread, so large downloads don't have to fit in memory.  Attempts
+// are retried on errors and IsRetryCondition like other methods, which only
+// sees the response headers since the body isn't read.  Once a response is
+// returned nothing is retried, errors while reading the body are left to the
+// caller.
+//
+// The caller must close the response body.  On error the response is nil.
+func (r httpRequest) DoStream(ctx context.Context, method string, object []byte, opts ...CallOption) (*http.Response, error) {
+	r = r.forCall(method, opts)
+	client, err := r.httpClient()
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := r.retryLoop(ctx, client, r.newRequestFactory(method, r.URL.String(), object), true)
+	resp := result.raw
+	if err != nil {
+		if resp != nil {
+			resp.Body.Close()
+		}
+		return nil, err
+	}
+	return resp, nil
+}
+
+// HttpGetStream is DoStream for GET requests.
+func (r httpRequest) HttpGetStream(ctx context.Context, opts ...CallOption) (*http.Response, error) {
+	return r.DoStream(ctx, http.MethodGet, nil, opts...)
+}
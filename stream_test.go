@@ -0,0 +1,80 @@
+package httpretry
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntegration_HttpGetStream(t *testing.T) {
+
+	t.Run("GIVEN a server that returns 503 once AND then a large body", func(t *testing.T) {
+		payload := strings.Repeat("0123456789", 100000)
+		attempts := 1
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if attempts > 0 {
+				attempts--
+				w.WriteHeader(http.StatusServiceUnavailable)
+				w.Write([]byte("unavailable"))
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+			io.WriteString(w, payload)
+		}))
+		defer ts.Close()
+
+		url, err := url.Parse(ts.URL)
+		require.NoError(t, err)
+
+		api := NewHttpRequest(HttpRequestOptions{
+			URL:         url,
+			RetriesWait: time.Millisecond,
+			IsRetryCondition: func(resp *http.Response, retryCount int) bool {
+				return resp.StatusCode == http.StatusServiceUnavailable
+			},
+		})
+
+		t.Run("WHEN HttpGetStream is sent", func(t *testing.T) {
+			resp, err := api.HttpGetStream(context.Background())
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			t.Run("THEN the final response body is streamed to the caller", func(t *testing.T) {
+				assert.Equal(t, http.StatusOK, resp.StatusCode)
+				body, err := io.ReadAll(resp.Body)
+				require.NoError(t, err)
+				assert.Equal(t, len(payload), len(body))
+			})
+		})
+	})
+
+	t.Run("GIVEN a closed server", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		url, err := url.Parse(ts.URL)
+		require.NoError(t, err)
+		ts.Close()
+
+		api := NewHttpRequest(HttpRequestOptions{
+			URL:         url,
+			RetriesMax:  2,
+			RetriesWait: time.Millisecond,
+		})
+
+		t.Run("WHEN HttpGetStream is sent", func(t *testing.T) {
+			resp, err := api.HttpGetStream(context.Background())
+
+			t.Run("THEN the connection error is returned without a response", func(t *testing.T) {
+				assert.Error(t, err)
+				assert.Nil(t, resp)
+			})
+		})
+	})
+}
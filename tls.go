@@ -0,0 +1,48 @@
+package httpretry
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+)
+
+// TLSOptions configure mutual TLS and private certificate authorities, see
+// HttpRequestOptions.TLSClientConfig and ClientOptions.TLSClientConfig.
+type TLSOptions struct {
+	// ClientCert and ClientKey are the PEM encoded client certificate chain
+	// and private key presented to servers requiring mutual TLS
+	ClientCert []byte
+	ClientKey  []byte
+
+	// RootCAs are PEM encoded certificates of the authorities trusted for
+	// server certificates
+	// defaults to the system pool
+	RootCAs []byte
+
+	// InsecureSkipVerify disables server certificate verification, only use
+	// it in tests
+	InsecureSkipVerify bool
+}
+
+// Config builds the tls.Config described by the options.
+func (o TLSOptions) Config() (*tls.Config, error) {
+	config := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: o.InsecureSkipVerify,
+	}
+	if len(o.ClientCert) > 0 || len(o.ClientKey) > 0 {
+		cert, err := tls.X509KeyPair(o.ClientCert, o.ClientKey)
+		if err != nil {
+			return nil, err
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+	if len(o.RootCAs) > 0 {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(o.RootCAs) {
+			return nil, errors.New("no certificate found in RootCAs")
+		}
+		config.RootCAs = pool
+	}
+	return config, nil
+}
@@ -0,0 +1,125 @@
+package httpretry
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testClientCertificate returns a self-signed client certificate and key, PEM
+// encoded.
+func testClientCertificate(t *testing.T) ([]byte, []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "client"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		IsCA:         true,
+
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+func TestIntegration_MutualTLS(t *testing.T) {
+
+	t.Run("GIVEN a server requiring a client certificate", func(t *testing.T) {
+		clientCert, clientKey := testClientCertificate(t)
+		clientCAs := x509.NewCertPool()
+		require.True(t, clientCAs.AppendCertsFromPEM(clientCert))
+
+		ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(r.TLS.PeerCertificates[0].Subject.CommonName))
+		}))
+		ts.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs}
+		ts.StartTLS()
+		defer ts.Close()
+
+		url, err := url.Parse(ts.URL)
+		require.NoError(t, err)
+		serverCA := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ts.Certificate().Raw})
+
+		t.Run("WHEN HttpGet is sent with the client certificate AND the server CA", func(t *testing.T) {
+			config, err := TLSOptions{ClientCert: clientCert, ClientKey: clientKey, RootCAs: serverCA}.Config()
+			require.NoError(t, err)
+			api := NewHttpRequest(HttpRequestOptions{URL: url, TLSClientConfig: config, RetriesMax: 1})
+			body, status, err := api.HttpGet(context.Background())
+			require.NoError(t, err)
+
+			t.Run("THEN the server authenticates the client", func(t *testing.T) {
+				assert.Equal(t, http.StatusOK, status)
+				assert.Equal(t, "client", string(body))
+			})
+		})
+
+		t.Run("WHEN HttpGet is sent without the client certificate", func(t *testing.T) {
+			config, err := TLSOptions{RootCAs: serverCA}.Config()
+			require.NoError(t, err)
+			api := NewHttpRequest(HttpRequestOptions{URL: url, TLSClientConfig: config, RetriesMax: 1, Logger: NopLogger{}})
+			_, _, err = api.HttpGet(context.Background())
+
+			t.Run("THEN the handshake fails", func(t *testing.T) {
+				assert.Error(t, err)
+			})
+		})
+	})
+
+	t.Run("GIVEN a TLS server AND a client with a wrapped transport", func(t *testing.T) {
+		ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		defer ts.Close()
+
+		url, err := url.Parse(ts.URL)
+		require.NoError(t, err)
+		serverCA := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ts.Certificate().Raw})
+		client := &http.Client{Transport: wrappedTransport{http.DefaultTransport}}
+
+		t.Run("WHEN HttpGet is sent with the server CA", func(t *testing.T) {
+			config, err := TLSOptions{RootCAs: serverCA}.Config()
+			require.NoError(t, err)
+			logger := &testLogger{}
+			api := NewHttpRequest(HttpRequestOptions{URL: url, Client: client, TLSClientConfig: config, RetriesMax: 1, Logger: logger})
+			_, status, err := api.HttpGet(context.Background())
+
+			t.Run("THEN a dedicated transport verifies the server AND the replacement is logged", func(t *testing.T) {
+				require.NoError(t, err)
+				assert.Equal(t, http.StatusOK, status)
+				assert.True(t, logger.contains("warn: Client transport can't be configured"))
+			})
+		})
+	})
+
+	t.Run("GIVEN invalid PEM", func(t *testing.T) {
+		_, err := TLSOptions{RootCAs: []byte("garbage")}.Config()
+
+		t.Run("THEN Config fails", func(t *testing.T) {
+			assert.Error(t, err)
+		})
+	})
+}
+
+type wrappedTransport struct {
+	http.RoundTripper
+}
@@ -0,0 +1,147 @@
+package httpretry
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sync"
+	"time"
+)
+
+// Tracer creates a span for every call and a child span for every attempt.
+// It is shaped after the OpenTelemetry API so wrapping an otel trace.Tracer
+// and propagation.TextMapPropagator takes a few lines, W3CTracer is a
+// dependency free implementation.
+type Tracer interface {
+	Start(ctx context.Context, spanName string) (context.Context, Span)
+
+	// Inject adds the propagation headers of the span in ctx, for example
+	// traceparent, to an outgoing request.
+	Inject(ctx context.Context, header http.Header)
+}
+
+type Span interface {
+	SetAttribute(key string, value interface{})
+	RecordError(err error)
+	End()
+}
+
+// Span attribute keys set by the retry loop.
+const (
+	AttributeHTTPMethod     = "http.method"
+	AttributeHTTPURL        = "http.url"
+	AttributeHTTPStatusCode = "http.status_code"
+	AttributeRetryCount     = "httpretry.retry_count"
+	AttributeWait           = "httpretry.wait"
+)
+
+// SpanData is a finished span reported by W3CTracer.
+type SpanData struct {
+	Name         string
+	TraceID      string
+	SpanID       string
+	ParentSpanID string
+	Start        time.Time
+	End          time.Time
+	Attributes   map[string]interface{}
+	Err          error
+}
+
+// W3CTracer propagates W3C Trace Context (traceparent) and reports finished
+// spans to a callback, for example to log them.  A call continues the trace
+// of a parent span created by this tracer, otherwise a new trace is started.
+//
+// See https://www.w3.org/TR/trace-context/
+type W3CTracer struct {
+	onEnd func(SpanData)
+}
+
+// NewW3CTracer returns a tracer calling onEnd for every finished span, onEnd
+// may be nil when only propagation is wanted.
+func NewW3CTracer(onEnd func(SpanData)) *W3CTracer {
+	return &W3CTracer{onEnd: onEnd}
+}
+
+type w3cSpanKey struct{}
+
+type w3cSpan struct {
+	mu     sync.Mutex
+	tracer *W3CTracer
+	data   SpanData
+	ended  bool
+}
+
+func (t *W3CTracer) Start(ctx context.Context, spanName string) (context.Context, Span) {
+	span := &w3cSpan{
+		tracer: t,
+		data: SpanData{
+			Name:       spanName,
+			SpanID:     randomHex(8),
+			Start:      time.Now(),
+			Attributes: map[string]interface{}{},
+		},
+	}
+	if parent, ok := ctx.Value(w3cSpanKey{}).(*w3cSpan); ok {
+		span.data.TraceID = parent.data.TraceID
+		span.data.ParentSpanID = parent.data.SpanID
+	} else {
+		span.data.TraceID = randomHex(16)
+	}
+	return context.WithValue(ctx, w3cSpanKey{}, span), span
+}
+
+func (t *W3CTracer) Inject(ctx context.Context, header http.Header) {
+	span, ok := ctx.Value(w3cSpanKey{}).(*w3cSpan)
+	if !ok {
+		return
+	}
+	header.Set("traceparent", fmt.Sprintf("00-%s-%s-01", span.data.TraceID, span.data.SpanID))
+}
+
+func (s *w3cSpan) SetAttribute(key string, value interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data.Attributes[key] = value
+}
+
+func (s *w3cSpan) RecordError(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data.Err = err
+}
+
+func (s *w3cSpan) End() {
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.data.End = time.Now()
+	data := s.data
+	s.mu.Unlock()
+	if s.tracer.onEnd != nil {
+		s.tracer.onEnd(data)
+	}
+}
+
+var traceparentPattern = regexp.MustCompile(`^00-([0-9a-f]{32})-([0-9a-f]{16})-[0-9a-f]{2}$`)
+
+// ParseTraceparent returns the trace and parent span IDs of a traceparent
+// header value.
+func ParseTraceparent(value string) (traceID string, spanID string, ok bool) {
+	match := traceparentPattern.FindStringSubmatch(value)
+	if match == nil {
+		return "", "", false
+	}
+	return match[1], match[2], true
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,81 @@
+package httpretry
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntegration_Tracing(t *testing.T) {
+
+	t.Run("GIVEN a server that returns 503 once AND records traceparent headers", func(t *testing.T) {
+		attempts := 1
+		var traceparents []string
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			traceparents = append(traceparents, r.Header.Get("traceparent"))
+			if attempts > 0 {
+				attempts--
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer ts.Close()
+
+		url, err := url.Parse(ts.URL)
+		require.NoError(t, err)
+
+		t.Run("AND http request with a W3C tracer", func(t *testing.T) {
+			var mu sync.Mutex
+			var spans []SpanData
+			api := NewHttpRequest(HttpRequestOptions{
+				URL:         url,
+				RetriesWait: time.Millisecond,
+				Tracer: NewW3CTracer(func(span SpanData) {
+					mu.Lock()
+					defer mu.Unlock()
+					spans = append(spans, span)
+				}),
+				IsRetryCondition: func(resp *http.Response, retryCount int) bool {
+					return resp.StatusCode == http.StatusServiceUnavailable
+				},
+			})
+
+			t.Run("WHEN HttpGet is sent", func(t *testing.T) {
+				_, _, err := api.HttpGet(context.Background())
+				require.NoError(t, err)
+
+				t.Run("THEN a call span has a child span per attempt", func(t *testing.T) {
+					require.Len(t, spans, 3)
+					call := spans[2]
+					assert.Equal(t, "HTTP GET", call.Name)
+					assert.Equal(t, http.StatusOK, call.Attributes[AttributeHTTPStatusCode])
+					for i, attempt := range spans[:2] {
+						assert.Equal(t, call.TraceID, attempt.TraceID)
+						assert.Equal(t, call.SpanID, attempt.ParentSpanID)
+						assert.Equal(t, i+1, attempt.Attributes[AttributeRetryCount])
+					}
+					assert.Equal(t, http.StatusServiceUnavailable, spans[0].Attributes[AttributeHTTPStatusCode])
+					assert.Equal(t, "1ms", spans[1].Attributes[AttributeWait])
+				})
+
+				t.Run("THEN every attempt propagates its span", func(t *testing.T) {
+					require.Len(t, traceparents, 2)
+					for i, header := range traceparents {
+						traceID, spanID, ok := ParseTraceparent(header)
+						require.True(t, ok)
+						assert.Equal(t, spans[i].TraceID, traceID)
+						assert.Equal(t, spans[i].SpanID, spanID)
+					}
+				})
+			})
+		})
+	})
+}
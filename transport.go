@@ -0,0 +1,128 @@
+package httpretry
+
+import (
+	"io"
+	"net/http"
+	"time"
+)
+
+type RetryTransportOptions struct {
+	// Transport is the wrapped round tripper
+	// defaults to http.DefaultTransport
+	Transport http.RoundTripper
+
+	// RetriesMax max number of attempts, NoRetries or UnlimitedRetries
+	// defaults to 10
+	RetriesMax int
+
+	// RetriesWait amount of time to wait between retries
+	// defaults to 1sec
+	RetriesWait time.Duration
+
+	// Backoff computes the wait between retries.  When set RetriesWait is
+	// ignored.
+	// defaults to ConstantBackoff using RetriesWait
+	Backoff BackoffStrategy
+
+	// IsRetryCondition returns false by default, see HttpRequestOptions.
+	IsRetryCondition RetryPredicate
+
+	// Logger defaults to the package logger, see SetLogger
+	Logger Logger
+}
+
+type retryTransport struct {
+	transport        http.RoundTripper
+	retriesMax       int
+	backoff          BackoffStrategy
+	isRetryCondition RetryPredicate
+	logger           Logger
+}
+
+// NewRetryTransport wraps a round tripper with the retry semantics of
+// httpRequest so retries can be added to an existing *http.Client:
+//
+//	client := &http.Client{Transport: httpretry.NewRetryTransport(options)}
+//
+// Requests with a body are only retried when they can be rewound through
+// Request.GetBody, which http.NewRequest sets for in-memory bodies.  The
+// final response is returned with its body unread.
+func NewRetryTransport(options RetryTransportOptions) http.RoundTripper {
+	if options.Transport == nil {
+		options.Transport = http.DefaultTransport
+	}
+	if options.RetriesMax == 0 {
+		options.RetriesMax = 10
+	}
+	if options.RetriesWait == 0 {
+		options.RetriesWait = time.Second * 1
+	}
+	if options.Backoff == nil {
+		options.Backoff = ConstantBackoff{Wait: options.RetriesWait}
+	}
+	return &retryTransport{
+		transport:        options.Transport,
+		retriesMax:       options.RetriesMax,
+		backoff:          options.Backoff,
+		isRetryCondition: options.IsRetryCondition,
+		logger:           options.Logger,
+	}
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	rewindable := req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+
+	for retryCount := 1; ; retryCount++ {
+		attempt := req
+		if retryCount > 1 {
+			attempt = req.Clone(ctx)
+			if req.GetBody != nil {
+				body, err := req.GetBody()
+				if err != nil {
+					return nil, err
+				}
+				attempt.Body = body
+			}
+		}
+
+		resp, err := t.transport.RoundTrip(attempt)
+		if ctx.Err() != nil {
+			if resp != nil {
+				resp.Body.Close()
+			}
+			return nil, ctx.Err()
+		}
+		if err == nil && (t.isRetryCondition == nil || !t.isRetryCondition(resp, retryCount)) {
+			return resp, nil
+		}
+		if retryCount >= maxAttempts(t.retriesMax) || !rewindable || IsPermanentTLSError(err) || isShutdown() {
+			return resp, err
+		}
+		if err != nil {
+			t.logRetry(LogLevelWarn, "RoundTrip failed", attemptFields(ctx, req, nil, err, retryCount))
+		} else {
+			t.logRetry(LogLevelInfo, "RoundTrip IsRetryCondition returned true", attemptFields(ctx, req, resp, nil, retryCount))
+			// drain so the connection can be reused
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		if err := sleepOrShutdown(ctx, systemClock{}, t.backoff.Backoff(retryCount)); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (t *retryTransport) log() Logger {
+	if t.logger != nil {
+		return t.logger
+	}
+	return getLogger()
+}
+
+func (t *retryTransport) logRetry(level LogLevel, msg string, fields Fields) {
+	if override := getRetryLogLevel(); override != LogLevelDefault {
+		level = override
+	}
+	logWithFields(t.log(), level, msg, fields)
+}
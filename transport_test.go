@@ -0,0 +1,57 @@
+package httpretry
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntegration_RetryTransport(t *testing.T) {
+
+	t.Run("GIVEN a server that returns 503 for 2 requests AND echoes the request body", func(t *testing.T) {
+		attempts := 2
+
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, err := io.ReadAll(r.Body)
+			require.NoError(t, err)
+			if attempts > 0 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				attempts--
+			} else {
+				w.WriteHeader(http.StatusOK)
+			}
+			w.Write(body)
+		}))
+		defer ts.Close()
+
+		t.Run("AND a client using the retry transport", func(t *testing.T) {
+			client := &http.Client{
+				Transport: NewRetryTransport(RetryTransportOptions{
+					RetriesWait: time.Millisecond,
+					IsRetryCondition: func(resp *http.Response, retryCount int) bool {
+						return resp.StatusCode == http.StatusServiceUnavailable
+					},
+				}),
+			}
+
+			t.Run("WHEN a POST is sent", func(t *testing.T) {
+				resp, err := client.Post(ts.URL, "text/plain", strings.NewReader("payload"))
+				require.NoError(t, err)
+				defer resp.Body.Close()
+				body, err := io.ReadAll(resp.Body)
+				require.NoError(t, err)
+
+				t.Run("THEN the retried request succeeds with the full body", func(t *testing.T) {
+					assert.Equal(t, http.StatusOK, resp.StatusCode)
+					assert.Equal(t, "payload", string(body))
+				})
+			})
+		})
+	})
+}
@@ -0,0 +1,126 @@
+package httpretry
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// WebhookIDHeader carries the ID of a webhook delivery, the same for every
+// attempt so receivers can deduplicate.
+const WebhookIDHeader = "Webhook-Id"
+
+// DefaultWebhookSchedule spreads deliveries over about a day.
+var DefaultWebhookSchedule = []time.Duration{
+	time.Minute,
+	5 * time.Minute,
+	30 * time.Minute,
+	2 * time.Hour,
+	5 * time.Hour,
+	10 * time.Hour,
+}
+
+// WebhookDelivery is the outcome of WebhookSender.Send.
+type WebhookDelivery struct {
+	ID        string
+	URL       string
+	Payload   []byte
+	Delivered bool
+
+	// StatusCode and Err are those of the last attempt
+	StatusCode int
+	Err        error
+
+	// History lists every attempt of every round
+	History []AttemptSummary
+}
+
+type WebhookOptions struct {
+	// Signer signs every attempt, for example
+	// NewHMACSigner(HMACOptions{Secret: secret, Header: "Webhook-Signature"})
+	// defaults to unsigned payloads
+	Signer *HMACSigner
+
+	// Schedule is the wait before each delivery round after the first, a
+	// round being retried like DoFull.  The delivery fails permanently when
+	// the schedule is exhausted.
+	// defaults to DefaultWebhookSchedule
+	Schedule []time.Duration
+
+	// OnFailure is called with deliveries that failed permanently, after
+	// the schedule or on a 4xx status but 408 and 429
+	OnFailure func(delivery *WebhookDelivery)
+}
+
+// WebhookSender delivers events to customer endpoints.  Endpoints are often
+// down for hours, so on top of the retries of each round deliveries are
+// spread over a long schedule.
+type WebhookSender struct {
+	r       httpRequest
+	options WebhookOptions
+}
+
+// NewWebhookSender delivers with the retry policy, client and headers of the
+// request, its URL is ignored.
+func (r httpRequest) NewWebhookSender(options WebhookOptions) *WebhookSender {
+	if options.Schedule == nil {
+		options.Schedule = DefaultWebhookSchedule
+	}
+	if options.Signer != nil {
+		r.Middleware = append(r.Middleware[:len(r.Middleware):len(r.Middleware)], options.Signer.Middleware)
+	}
+	r.Header = r.Header.Clone()
+	if r.Header == nil {
+		r.Header = http.Header{}
+	}
+	if r.Header.Get("Content-Type") == "" {
+		r.Header.Set("Content-Type", "application/json")
+	}
+	return &WebhookSender{r: r, options: options}
+}
+
+// Send posts payload to endpoint until it's accepted with a 2xx status, the
+// schedule is exhausted or ctx is cancelled, then Err is the context
+// error and OnFailure isn't called.  It blocks for the whole
+// schedule, run it in its own goroutine:
+//
+//	go sender.Send(ctx, endpoint, event)
+func (s *WebhookSender) Send(ctx context.Context, endpoint *url.URL, payload []byte) *WebhookDelivery {
+	delivery := &WebhookDelivery{ID: uuid.New().String(), URL: endpoint.String(), Payload: payload}
+	r := s.r
+	r.URL = endpoint
+	for round := 0; ; round++ {
+		resp, err := r.HttpPostFull(ctx, payload, WithHeader(WebhookIDHeader, delivery.ID))
+		delivery.History = append(delivery.History, resp.History...)
+		delivery.StatusCode, delivery.Err = resp.StatusCode, err
+		if err == nil && resp.StatusCode >= 200 && resp.StatusCode < 300 {
+			delivery.Delivered = true
+			return delivery
+		}
+		if ctx.Err() != nil {
+			delivery.Err = ctx.Err()
+			return delivery
+		}
+		if (err == nil && isPermanentStatus(resp.StatusCode)) || round >= len(s.options.Schedule) {
+			break
+		}
+		if err := r.clock().Sleep(ctx, s.options.Schedule[round]); err != nil {
+			delivery.Err = err
+			return delivery
+		}
+	}
+
+	r.logRetry(LogLevelWarn, "Request webhook delivery failed", Fields{
+		"url":     delivery.URL,
+		"attempt": len(delivery.History),
+		"status":  delivery.StatusCode,
+		"error":   delivery.Err,
+	})
+	if s.options.OnFailure != nil {
+		s.options.OnFailure(delivery)
+	}
+	return delivery
+}
@@ -0,0 +1,92 @@
+package httpretry
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntegration_WebhookSender(t *testing.T) {
+
+	t.Run("GIVEN an endpoint down for the first two rounds", func(t *testing.T) {
+		var mu sync.Mutex
+		var ids, signatures []string
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			defer mu.Unlock()
+			ids = append(ids, r.Header.Get(WebhookIDHeader))
+			signatures = append(signatures, r.Header.Get("Webhook-Signature"))
+			if len(ids) <= 4 {
+				w.WriteHeader(http.StatusBadGateway)
+			}
+		}))
+		defer ts.Close()
+
+		endpoint, err := url.Parse(ts.URL + "/hooks")
+		require.NoError(t, err)
+		api := NewHttpRequest(HttpRequestOptions{
+			RetriesMax:       2,
+			RetriesWait:      time.Millisecond,
+			IsRetryCondition: RetryOnStatusClass(5),
+		})
+
+		t.Run("WHEN an event is sent", func(t *testing.T) {
+			failed := false
+			sender := api.NewWebhookSender(WebhookOptions{
+				Signer:    NewHMACSigner(HMACOptions{Secret: []byte("secret"), Header: "Webhook-Signature"}),
+				Schedule:  []time.Duration{time.Millisecond, time.Millisecond, time.Millisecond},
+				OnFailure: func(delivery *WebhookDelivery) { failed = true },
+			})
+			delivery := sender.Send(context.Background(), endpoint, []byte(`{"type":"created"}`))
+
+			t.Run("THEN it is delivered in the third round", func(t *testing.T) {
+				assert.True(t, delivery.Delivered)
+				assert.NoError(t, delivery.Err)
+				assert.Equal(t, http.StatusOK, delivery.StatusCode)
+				assert.Len(t, delivery.History, 5)
+				assert.False(t, failed)
+			})
+
+			t.Run("AND every attempt is signed with the same delivery ID", func(t *testing.T) {
+				for i := range ids {
+					assert.Equal(t, delivery.ID, ids[i])
+					assert.NotEmpty(t, signatures[i])
+				}
+			})
+		})
+	})
+
+	t.Run("GIVEN an endpoint that is gone", func(t *testing.T) {
+		attempts := 0
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			attempts++
+			w.WriteHeader(http.StatusGone)
+		}))
+		defer ts.Close()
+
+		endpoint, err := url.Parse(ts.URL)
+		require.NoError(t, err)
+
+		t.Run("WHEN an event is sent", func(t *testing.T) {
+			var failed *WebhookDelivery
+			sender := NewHttpRequest(HttpRequestOptions{}).NewWebhookSender(WebhookOptions{
+				OnFailure: func(delivery *WebhookDelivery) { failed = delivery },
+			})
+			delivery := sender.Send(context.Background(), endpoint, []byte(`{}`))
+
+			t.Run("THEN it fails permanently without waiting for the schedule", func(t *testing.T) {
+				assert.False(t, delivery.Delivered)
+				assert.Equal(t, http.StatusGone, delivery.StatusCode)
+				assert.Equal(t, 1, attempts)
+				assert.Same(t, delivery, failed)
+			})
+		})
+	})
+}